@@ -3,6 +3,7 @@ package backends
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	"git.pubmatic.com/PubMatic/go-common/logger"
@@ -14,24 +15,27 @@ import (
 	"github.com/prebid/prebid-cache/utils"
 )
 
-const setName = "uuid"
-const binValue = "value"
-
 // AerospikeDB is a wrapper for the Aerospike client
 type AerospikeDB interface {
 	NewUUIDKey(namespace string, key string) (*as.Key, error)
-	Get(key *as.Key) (*as.Record, error)
+	Get(policy *as.BasePolicy, key *as.Key) (*as.Record, error)
 	Put(policy *as.WritePolicy, key *as.Key, binMap as.BinMap) error
+	Delete(policy *as.WritePolicy, key *as.Key) (bool, error)
+	BatchPut(policy *as.BatchPolicy, policies []*as.BatchWritePolicy, keys []*as.Key, binMaps []as.BinMap) []error
+	BatchGet(policy *as.BatchPolicy, keys []*as.Key) ([]*as.Record, error)
 }
 
-// AerospikeDBClient implements the AerospikeDB interface
+// AerospikeDBClient implements the AerospikeDB interface. Records are stored in setName and
+// only binName gets read back
 type AerospikeDBClient struct {
-	client *as.Client
+	client  *as.Client
+	setName string
+	binName string
 }
 
 // Get performs the as.Client Get operation
-func (db AerospikeDBClient) Get(key *as.Key) (*as.Record, error) {
-	return db.client.Get(nil, key, binValue)
+func (db AerospikeDBClient) Get(policy *as.BasePolicy, key *as.Key) (*as.Record, error) {
+	return db.client.Get(policy, key, db.binName)
 }
 
 // Put performs the as.Client Put operation
@@ -39,23 +43,123 @@ func (db AerospikeDBClient) Put(policy *as.WritePolicy, key *as.Key, binMap as.B
 	return db.client.Put(policy, key, binMap)
 }
 
+// Delete performs the as.Client Delete operation
+func (db AerospikeDBClient) Delete(policy *as.WritePolicy, key *as.Key) (bool, error) {
+	return db.client.Delete(policy, key)
+}
+
+// BatchPut writes every binMap under its corresponding key and policy using a single as.Client
+// BatchOperate call. Returns one error per key
+func (db AerospikeDBClient) BatchPut(policy *as.BatchPolicy, policies []*as.BatchWritePolicy, keys []*as.Key, binMaps []as.BinMap) []error {
+	records := make([]as.BatchRecordIfc, len(keys))
+	for i := range keys {
+		ops := make([]*as.Operation, 0, len(binMaps[i]))
+		for name, value := range binMaps[i] {
+			ops = append(ops, as.PutOp(as.NewBin(name, value)))
+		}
+		records[i] = as.NewBatchWrite(policies[i], keys[i], ops...)
+	}
+
+	batchErr := db.client.BatchOperate(policy, records)
+
+	errs := make([]error, len(records))
+	for i, record := range records {
+		batchRecord := record.BatchRec()
+		switch {
+		case batchRecord.Err != nil:
+			errs[i] = batchRecord.Err
+		case batchRecord.ResultCode == as_types.OK:
+			errs[i] = nil
+		case batchErr != nil:
+			// Record didn't get a response because the batch failed as a whole
+			errs[i] = batchErr
+		default:
+			errs[i] = &as.AerospikeError{ResultCode: batchRecord.ResultCode}
+		}
+	}
+	return errs
+}
+
+// BatchGet performs the as.Client BatchGet operation. Records that were not found come
+// back as nil
+func (db AerospikeDBClient) BatchGet(policy *as.BatchPolicy, keys []*as.Key) ([]*as.Record, error) {
+	return db.client.BatchGet(policy, keys, db.binName)
+}
+
 // NewUUIDKey creates an aerospike key so we can store data under it
 func (db *AerospikeDBClient) NewUUIDKey(namespace string, key string) (*as.Key, error) {
-	return as.NewKey(namespace, setName, key)
+	return as.NewKey(namespace, db.setName, key)
 }
 
 // AerospikeBackend upon creation will instantiates, and configure the Aerospike client. Implements
-// the Backend interface
+// the Backend interface. Every operation runs with a copy of the client's default policies whose
+// timeouts get capped by the deadline of the request context
 type AerospikeBackend struct {
-	namespace string
-	client    AerospikeDB
-	metrics   *metrics.Metrics
+	namespace   string
+	binName     string
+	client      AerospikeDB
+	metrics     *metrics.Metrics
+	readPolicy  *as.BasePolicy
+	writePolicy *as.WritePolicy
+	batchPolicy *as.BatchPolicy
 }
 
 // NewAerospikeBackend validates config.Aerospike and returns an AerospikeBackend
 func NewAerospikeBackend(cfg config.Aerospike, metrics *metrics.Metrics) *AerospikeBackend {
+	clientPolicy, err := newAerospikeClientPolicy(cfg)
+	if err != nil {
+		logger.Fatal("Error creating Aerospike backend: %v", err)
+		panic("AerospikeBackend failure. This shouldn't happen.")
+	}
+
+	client, err := as.NewClientWithPolicyAndHost(clientPolicy, newAerospikeHosts(cfg)...)
+	if err != nil {
+		stats.LogAerospikeErrorStats()
+		logger.Fatal("Error creating Aerospike backend: %+v", err)
+		panic("AerospikeBackend failure. This shouldn't happen.")
+	}
+	logger.Info("Connected to Aerospike host(s) %v on port %d", append(cfg.Hosts, cfg.Host), cfg.Port)
+
+	configureAerospikePolicies(cfg, client.DefaultPolicy, client.DefaultWritePolicy, client.DefaultBatchPolicy)
+
+	return &AerospikeBackend{
+		namespace:   cfg.Namespace,
+		binName:     cfg.BinName,
+		client:      &AerospikeDBClient{client: client, setName: cfg.SetName, binName: cfg.BinName},
+		metrics:     metrics,
+		readPolicy:  client.DefaultPolicy,
+		writePolicy: client.DefaultWritePolicy,
+		batchPolicy: client.DefaultBatchPolicy,
+	}
+}
+
+// newAerospikeHosts lists the seed nodes in cfg along with the name their TLS certificate
+// gets verified against
+func newAerospikeHosts(cfg config.Aerospike) []*as.Host {
 	var hosts []*as.Host
 
+	addresses := cfg.Hosts
+	if len(cfg.Host) > 1 {
+		addresses = append([]string{cfg.Host}, cfg.Hosts...)
+		logger.Info("config.backend.aerospike.host is being deprecated in favor of config.backend.aerospike.hosts")
+	}
+	for _, address := range addresses {
+		name, tlsName := config.SplitAerospikeHost(address)
+		host := as.NewHost(name, cfg.Port)
+		if cfg.TLS.Enabled {
+			host.TLSName = cfg.TLS.Name
+			if len(tlsName) > 0 {
+				host.TLSName = tlsName
+			}
+		}
+		hosts = append(hosts, host)
+	}
+
+	return hosts
+}
+
+// newAerospikeClientPolicy translates cfg into the policy the Aerospike client connects with
+func newAerospikeClientPolicy(cfg config.Aerospike) (*as.ClientPolicy, error) {
 	clientPolicy := as.NewClientPolicy()
 	// cfg.User and cfg.Password are optional parameters
 	// if left blank in the config, they will default to the empty
@@ -75,41 +179,133 @@ func NewAerospikeBackend(cfg config.Aerospike, metrics *metrics.Metrics) *Aerosp
 		clientPolicy.ConnectionQueueSize = cfg.ConnQueueSize
 	}
 
-	if len(cfg.Host) > 1 {
-		hosts = append(hosts, as.NewHost(cfg.Host, cfg.Port))
-		logger.Info("config.backend.aerospike.host is being deprecated in favor of config.backend.aerospike.hosts")
+	// Rack aware reads need the client to keep track of the rack every node belongs to
+	if cfg.ReadReplica == config.AerospikeReplicaPreferRack {
+		clientPolicy.RackAware = true
+		clientPolicy.RackIds = []int{cfg.RackID}
 	}
-	for _, host := range cfg.Hosts {
-		hosts = append(hosts, as.NewHost(host, cfg.Port))
+
+	switch cfg.AuthMode {
+	case config.AerospikeAuthExternal:
+		clientPolicy.AuthMode = as.AuthModeExternal
+	case config.AerospikeAuthPKI:
+		clientPolicy.AuthMode = as.AuthModePKI
+	default:
+		clientPolicy.AuthMode = as.AuthModeInternal
 	}
 
-	client, err := as.NewClientWithPolicyAndHost(clientPolicy, hosts...)
-	if err != nil {
-		stats.LogAerospikeErrorStats()
-		logger.Fatal("Error creating Aerospike backend: %+v", err)
-		panic("AerospikeBackend failure. This shouldn't happen.")
+	if cfg.TLS.Enabled {
+		tlsConfig, err := cfg.TLS.NewTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		clientPolicy.TlsConfig = tlsConfig
 	}
-	logger.Info("Connected to Aerospike host(s) %v on port %d", append(cfg.Hosts, cfg.Host), cfg.Port)
 
-	// client.DefaultPolicy.MaxRetries determines the maximum number of retries before aborting a transaction.
+	return clientPolicy, nil
+}
+
+// configureAerospikePolicies applies cfg to the default policies of the Aerospike client
+func configureAerospikePolicies(cfg config.Aerospike, readPolicy *as.BasePolicy, writePolicy *as.WritePolicy, batchPolicy *as.BatchPolicy) {
+	// readPolicy.MaxRetries determines the maximum number of retries before aborting a transaction.
 	// Default for read: 2 (initial attempt + 2 retries = 3 attempts)
 	if cfg.MaxReadRetries > 2 {
-		client.DefaultPolicy.MaxRetries = cfg.MaxReadRetries
+		readPolicy.MaxRetries = cfg.MaxReadRetries
 	}
 
-	// client.DefaultWritePolicy.MaxRetries determines the maximum number of retries for write before aborting
+	// writePolicy.MaxRetries determines the maximum number of retries for write before aborting
 	// a transaction. Prebid Cache uses the Aerospike backend to do CREATE_ONLY writes, which are idempotent so
 	// it's safe to increase the maximum value of write retries.
 	// Default for write: 0 (no retries)
 	if cfg.MaxWriteRetries > 0 {
-		client.DefaultWritePolicy.MaxRetries = cfg.MaxWriteRetries
+		writePolicy.MaxRetries = cfg.MaxWriteRetries
 	}
 
-	return &AerospikeBackend{
-		namespace: cfg.Namespace,
-		client:    &AerospikeDBClient{client},
-		metrics:   metrics,
+	for _, policy := range []*as.BasePolicy{readPolicy, &batchPolicy.BasePolicy} {
+		switch cfg.ReadReplica {
+		case config.AerospikeReplicaMaster:
+			policy.ReplicaPolicy = as.MASTER
+		case config.AerospikeReplicaPreferRack:
+			policy.ReplicaPolicy = as.PREFER_RACK
+		default:
+			policy.ReplicaPolicy = as.SEQUENCE
+		}
+
+		if cfg.ReadModeAP == config.AerospikeReadModeAPAll {
+			policy.ReadModeAP = as.ReadModeAPAll
+		} else {
+			policy.ReadModeAP = as.ReadModeAPOne
+		}
+	}
+
+	readPolicy.SendKey = cfg.SendKey
+	writePolicy.SendKey = cfg.SendKey
+	batchPolicy.SendKey = cfg.SendKey
+}
+
+// newReadPolicy returns a copy of the default read policy bound to the ctx deadline
+func (a *AerospikeBackend) newReadPolicy(ctx context.Context) (*as.BasePolicy, error) {
+	policy := as.NewPolicy()
+	if a.readPolicy != nil {
+		*policy = *a.readPolicy
+	}
+	return policy, applyDeadline(ctx, policy)
+}
+
+// newWritePolicy returns a copy of the default write policy bound to the ctx deadline
+func (a *AerospikeBackend) newWritePolicy(ctx context.Context) (*as.WritePolicy, error) {
+	policy := as.NewWritePolicy(0, 0)
+	if a.writePolicy != nil {
+		*policy = *a.writePolicy
 	}
+	return policy, applyDeadline(ctx, &policy.BasePolicy)
+}
+
+// newBatchWritePolicy returns a per record batch write policy carrying the settings of the
+// default write policy
+func (a *AerospikeBackend) newBatchWritePolicy() *as.BatchWritePolicy {
+	policy := as.NewBatchWritePolicy()
+	if a.writePolicy != nil {
+		policy.RecordExistsAction = a.writePolicy.RecordExistsAction
+		policy.CommitLevel = a.writePolicy.CommitLevel
+		policy.GenerationPolicy = a.writePolicy.GenerationPolicy
+		policy.Generation = a.writePolicy.Generation
+		policy.Expiration = a.writePolicy.Expiration
+		policy.DurableDelete = a.writePolicy.DurableDelete
+		policy.SendKey = a.writePolicy.SendKey
+	}
+	return policy
+}
+
+// newBatchPolicy returns a copy of the default batch policy bound to the ctx deadline
+func (a *AerospikeBackend) newBatchPolicy(ctx context.Context) (*as.BatchPolicy, error) {
+	policy := as.NewBatchPolicy()
+	if a.batchPolicy != nil {
+		*policy = *a.batchPolicy
+	}
+	return policy, applyDeadline(ctx, &policy.BasePolicy)
+}
+
+// applyDeadline lowers the total and socket timeouts of policy to the time left before the ctx
+// deadline, so a slow Aerospike node can't hold a request past it. Contexts without a deadline
+// leave policy untouched. Returns an Aerospike timeout error if the deadline already passed
+func applyDeadline(ctx context.Context, policy *as.BasePolicy) error {
+	deadline, hasDeadline := ctx.Deadline()
+	if !hasDeadline {
+		return nil
+	}
+
+	remaining := time.Until(deadline)
+	if remaining <= 0 {
+		return as.ErrTimeout
+	}
+	if policy.TotalTimeout == 0 || policy.TotalTimeout > remaining {
+		policy.TotalTimeout = remaining
+	}
+	if policy.SocketTimeout == 0 || policy.SocketTimeout > remaining {
+		policy.SocketTimeout = remaining
+	}
+	return nil
 }
 
 // Get creates an aerospike key based on the UUID key parameter, perfomrs the client's Get call
@@ -120,19 +316,28 @@ func (a *AerospikeBackend) Get(ctx context.Context, key string) (string, error)
 	if err != nil {
 		return "", classifyAerospikeError(err)
 	}
-	rec, err := a.client.Get(asKey)
+	policy, err := a.newReadPolicy(ctx)
 	if err != nil {
-		return "", classifyAerospikeError(err)
+		return "", classifyAerospikeTimeout(err, utils.GET_DEADLINE_EXCEEDED)
+	}
+	rec, err := a.client.Get(policy, asKey)
+	if err != nil {
+		return "", classifyAerospikeTimeout(err, utils.GET_DEADLINE_EXCEEDED)
 	}
 	if rec == nil {
 		return "", errors.New("Nil record")
 	}
+	logger.Info("Time taken by Aerospike for get: %v", time.Now().Sub(aerospikeStartTime))
+
+	return a.recordValue(rec)
+}
 
-	value, found := rec.Bins[binValue]
+// recordValue extracts the string stored in the configured bin of rec
+func (a *AerospikeBackend) recordValue(rec *as.Record) (string, error) {
+	value, found := rec.Bins[a.binName]
 	if !found {
-		return "", errors.New("No 'value' bucket found")
+		return "", fmt.Errorf("No '%s' bucket found", a.binName)
 	}
-	logger.Info("Time taken by Aerospike for get: %v", time.Now().Sub(aerospikeStartTime))
 
 	str, isString := value.(string)
 	if !isString {
@@ -151,20 +356,136 @@ func (a *AerospikeBackend) Put(ctx context.Context, key string, value string, tt
 		return classifyAerospikeError(err)
 	}
 
-	bins := as.BinMap{binValue: value}
-	policy := &as.WritePolicy{
-		Expiration:         uint32(ttlSeconds),
-		RecordExistsAction: as.CREATE_ONLY,
+	bins := as.BinMap{a.binName: value}
+	policy, err := a.newWritePolicy(ctx)
+	if err != nil {
+		return classifyAerospikeTimeout(err, utils.PUT_DEADLINE_EXCEEDED)
 	}
+	policy.Expiration = uint32(ttlSeconds)
+	policy.RecordExistsAction = as.CREATE_ONLY
 
 	if err := a.client.Put(policy, asKey, bins); err != nil {
-		return classifyAerospikeError(err)
+		return classifyAerospikeTimeout(err, utils.PUT_DEADLINE_EXCEEDED)
 	}
 
 	logger.Info("Time taken by Aerospike for put: %v", time.Now().Sub(aerospikeStartTime))
 	return nil
 }
 
+// Delete creates an aerospike key based on the UUID key parameter and removes its record using
+// the client's Delete implementation. Returns a KEY_NOT_FOUND error if the record didn't exist
+func (a *AerospikeBackend) Delete(ctx context.Context, key string) error {
+	asKey, err := a.client.NewUUIDKey(a.namespace, key)
+	if err != nil {
+		return classifyAerospikeError(err)
+	}
+
+	policy, err := a.newWritePolicy(ctx)
+	if err != nil {
+		return classifyAerospikeTimeout(err, utils.PUT_DEADLINE_EXCEEDED)
+	}
+
+	existed, err := a.client.Delete(policy, asKey)
+	if err != nil {
+		return classifyAerospikeTimeout(err, utils.PUT_DEADLINE_EXCEEDED)
+	}
+	if !existed {
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+	return nil
+}
+
+// PutBatch creates an aerospike key for every item and stores all of them using a single
+// batch write. Like Put, items whose key already exists come back with a RECORD_EXISTS error
+func (a *AerospikeBackend) PutBatch(ctx context.Context, items []BatchPutItem) []error {
+	aerospikeStartTime := time.Now()
+	errs := make([]error, len(items))
+
+	// Items whose key could not be generated are left out of the batch
+	indexes := make([]int, 0, len(items))
+	keys := make([]*as.Key, 0, len(items))
+	policies := make([]*as.BatchWritePolicy, 0, len(items))
+	binMaps := make([]as.BinMap, 0, len(items))
+	for i, item := range items {
+		asKey, err := a.client.NewUUIDKey(a.namespace, item.Key)
+		if err != nil {
+			errs[i] = classifyAerospikeError(err)
+			continue
+		}
+
+		policy := a.newBatchWritePolicy()
+		policy.Expiration = uint32(item.TTLSeconds)
+		policy.RecordExistsAction = as.CREATE_ONLY
+
+		indexes = append(indexes, i)
+		keys = append(keys, asKey)
+		policies = append(policies, policy)
+		binMaps = append(binMaps, as.BinMap{a.binName: item.Value})
+	}
+
+	if len(keys) > 0 {
+		var batchErrs []error
+		if batchPolicy, err := a.newBatchPolicy(ctx); err == nil {
+			batchErrs = a.client.BatchPut(batchPolicy, policies, keys, binMaps)
+		} else {
+			// The deadline passed before the batch could be sent
+			batchErrs = make([]error, len(keys))
+			for j := range batchErrs {
+				batchErrs[j] = err
+			}
+		}
+		for j, err := range batchErrs {
+			errs[indexes[j]] = classifyAerospikeTimeout(err, utils.PUT_DEADLINE_EXCEEDED)
+		}
+	}
+
+	logger.Info("Time taken by Aerospike for batch put of %d items: %v", len(items), time.Now().Sub(aerospikeStartTime))
+	return errs
+}
+
+// GetBatch creates an aerospike key for every UUID in keys and retrieves all of them using a
+// single batch read. Records that don't exist come back with a KEY_NOT_FOUND error
+func (a *AerospikeBackend) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	aerospikeStartTime := time.Now()
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+
+	indexes := make([]int, 0, len(keys))
+	asKeys := make([]*as.Key, 0, len(keys))
+	for i, key := range keys {
+		asKey, err := a.client.NewUUIDKey(a.namespace, key)
+		if err != nil {
+			errs[i] = classifyAerospikeError(err)
+			continue
+		}
+		indexes = append(indexes, i)
+		asKeys = append(asKeys, asKey)
+	}
+
+	if len(asKeys) == 0 {
+		return values, errs
+	}
+
+	var records []*as.Record
+	policy, err := a.newBatchPolicy(ctx)
+	if err == nil {
+		records, err = a.client.BatchGet(policy, asKeys)
+	}
+	for j, i := range indexes {
+		switch {
+		case j < len(records) && records[j] != nil:
+			values[i], errs[i] = a.recordValue(records[j])
+		case err != nil:
+			errs[i] = classifyAerospikeTimeout(err, utils.GET_DEADLINE_EXCEEDED)
+		default:
+			errs[i] = utils.NewPBCError(utils.KEY_NOT_FOUND)
+		}
+	}
+
+	logger.Info("Time taken by Aerospike for batch get of %d keys: %v", len(keys), time.Now().Sub(aerospikeStartTime))
+	return values, errs
+}
+
 func classifyAerospikeError(err error) error {
 	if err != nil {
 		ae := &as.AerospikeError{}
@@ -179,3 +500,12 @@ func classifyAerospikeError(err error) error {
 	}
 	return err
 }
+
+// classifyAerospikeTimeout returns a Prebid Cache error of type timeoutErrType if err comes
+// from a client or server side timeout. Other errors are classified by classifyAerospikeError
+func classifyAerospikeTimeout(err error, timeoutErrType int) error {
+	if errors.Is(err, &as.AerospikeError{ResultCode: as_types.TIMEOUT}) {
+		return utils.NewPBCError(timeoutErrType)
+	}
+	return classifyAerospikeError(err)
+}
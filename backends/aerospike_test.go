@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	as "github.com/aerospike/aerospike-client-go/v7"
 	as_types "github.com/aerospike/aerospike-client-go/v7/types"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/metrics/metricstest"
 	"github.com/prebid/prebid-cache/utils"
@@ -63,6 +65,7 @@ func TestAerospikeClientGet(t *testing.T) {
 		},
 	}
 	aerospikeBackend := &AerospikeBackend{
+		binName: binValue,
 		metrics: m,
 	}
 
@@ -102,6 +105,12 @@ func TestAerospikeClientGet(t *testing.T) {
 			expectedValue:     "",
 			expectedErrorMsg:  "Unexpected non-string value found",
 		},
+		{
+			desc:              "AerospikeBackend.Get() times out",
+			inAerospikeClient: &ErrorProneAerospikeClient{ServerError: "TEST_TIMEOUT_ERROR"},
+			expectedValue:     "",
+			expectedErrorMsg:  "timeout reading value from the backend.",
+		},
 		{
 			desc: "AerospikeBackend.Get() does not throw error",
 			inAerospikeClient: &GoodAerospikeClient{
@@ -138,6 +147,7 @@ func TestClientPut(t *testing.T) {
 		},
 	}
 	aerospikeBackend := &AerospikeBackend{
+		binName: binValue,
 		metrics: m,
 	}
 
@@ -165,6 +175,14 @@ func TestClientPut(t *testing.T) {
 			expectedStoredVal: "",
 			expectedErrorMsg:  "Record exists with provided key.",
 		},
+		{
+			desc:              "AerospikeBackend.Put() times out",
+			inAerospikeClient: &ErrorProneAerospikeClient{ServerError: "TEST_TIMEOUT_ERROR"},
+			inKey:             "testKey",
+			inValueToStore:    "not default value",
+			expectedStoredVal: "",
+			expectedErrorMsg:  "timeout writing value to the backend.",
+		},
 		{
 			desc: "AerospikeBackend.Put() does not throw error",
 			inAerospikeClient: &GoodAerospikeClient{
@@ -198,3 +216,471 @@ func TestClientPut(t *testing.T) {
 		}
 	}
 }
+
+func TestAerospikeClientDelete(t *testing.T) {
+	aerospikeBackend := &AerospikeBackend{binName: binValue}
+
+	testCases := []struct {
+		desc              string
+		inAerospikeClient AerospikeDB
+		expectedErrorMsg  string
+	}{
+		{
+			desc:              "AerospikeBackend.Delete() throws error when trying to generate new key",
+			inAerospikeClient: &ErrorProneAerospikeClient{ServerError: "TEST_KEY_GEN_ERROR"},
+			expectedErrorMsg:  "ResultCode: NOT_AUTHENTICATED, Iteration: 0, InDoubt: false, Node: <nil>: ",
+		},
+		{
+			desc:              "AerospikeBackend.Delete() throws error when 'client.Delete(..)' gets called",
+			inAerospikeClient: &ErrorProneAerospikeClient{ServerError: "TEST_DELETE_ERROR"},
+			expectedErrorMsg:  "ResultCode: SERVER_NOT_AVAILABLE, Iteration: 0, InDoubt: false, Node: <nil>: ",
+		},
+		{
+			desc:              "AerospikeBackend.Delete() times out",
+			inAerospikeClient: &ErrorProneAerospikeClient{ServerError: "TEST_TIMEOUT_ERROR"},
+			expectedErrorMsg:  "timeout writing value to the backend.",
+		},
+		{
+			desc:              "AerospikeBackend.Delete() finds no record to delete",
+			inAerospikeClient: &GoodAerospikeClient{StoredData: map[string]string{}},
+			expectedErrorMsg:  "Key not found",
+		},
+		{
+			desc: "AerospikeBackend.Delete() does not throw error",
+			inAerospikeClient: &GoodAerospikeClient{
+				StoredData: map[string]string{"defaultKey": "Default value"},
+			},
+			expectedErrorMsg: "",
+		},
+	}
+
+	for _, tt := range testCases {
+		// Assign aerospike backend cient
+		aerospikeBackend.client = tt.inAerospikeClient
+
+		// Run test
+		actualErr := aerospikeBackend.Delete(context.Background(), "defaultKey")
+
+		// Assertions
+		if tt.expectedErrorMsg == "" {
+			assert.Nil(t, actualErr, tt.desc)
+		} else {
+			assert.Equal(t, tt.expectedErrorMsg, actualErr.Error(), tt.desc)
+		}
+	}
+}
+
+func TestAerospikeClientPutBatch(t *testing.T) {
+	aerospikeBackend := &AerospikeBackend{binName: binValue}
+
+	testCases := []struct {
+		desc              string
+		inAerospikeClient AerospikeDB
+		expectedErrors    []error
+		expectedStored    map[string]string
+	}{
+		{
+			desc:              "AerospikeBackend.PutBatch() throws error when trying to generate new keys",
+			inAerospikeClient: &ErrorProneAerospikeClient{ServerError: "TEST_KEY_GEN_ERROR"},
+			expectedErrors: []error{
+				&as.AerospikeError{ResultCode: as_types.NOT_AUTHENTICATED},
+				&as.AerospikeError{ResultCode: as_types.NOT_AUTHENTICATED},
+			},
+		},
+		{
+			desc:              "AerospikeBackend.PutBatch() times out",
+			inAerospikeClient: &ErrorProneAerospikeClient{ServerError: "TEST_TIMEOUT_ERROR"},
+			expectedErrors: []error{
+				utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+				utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+			},
+		},
+		{
+			desc: "AerospikeBackend.PutBatch() stores new keys and interprets KEY_EXISTS_ERROR as RECORD_EXISTS",
+			inAerospikeClient: &GoodAerospikeClient{
+				StoredData: map[string]string{"key2": "original value"},
+			},
+			expectedErrors: []error{
+				nil,
+				utils.NewPBCError(utils.RECORD_EXISTS),
+			},
+			expectedStored: map[string]string{"key1": "value1", "key2": "original value"},
+		},
+	}
+
+	for _, tt := range testCases {
+		aerospikeBackend.client = tt.inAerospikeClient
+
+		// Run test
+		errs := aerospikeBackend.PutBatch(context.Background(), []BatchPutItem{
+			{Key: "key1", Value: "value1", TTLSeconds: 10},
+			{Key: "key2", Value: "value2", TTLSeconds: 10},
+		})
+
+		// Assertions
+		assert.Equal(t, tt.expectedErrors, errs, tt.desc)
+		if client, isGoodClient := tt.inAerospikeClient.(*GoodAerospikeClient); isGoodClient {
+			assert.Equal(t, tt.expectedStored, client.StoredData, tt.desc)
+		}
+	}
+}
+
+func TestAerospikeClientGetBatch(t *testing.T) {
+	aerospikeBackend := &AerospikeBackend{binName: binValue}
+
+	testCases := []struct {
+		desc              string
+		inAerospikeClient AerospikeDB
+		expectedValues    []string
+		expectedErrors    []error
+	}{
+		{
+			desc:              "AerospikeBackend.GetBatch() throws error when 'client.BatchGet(..)' gets called",
+			inAerospikeClient: &ErrorProneAerospikeClient{ServerError: "TEST_GET_ERROR"},
+			expectedValues:    []string{"", ""},
+			expectedErrors: []error{
+				&as.AerospikeError{ResultCode: as_types.SERVER_NOT_AVAILABLE},
+				&as.AerospikeError{ResultCode: as_types.SERVER_NOT_AVAILABLE},
+			},
+		},
+		{
+			desc:              "AerospikeBackend.GetBatch() times out",
+			inAerospikeClient: &ErrorProneAerospikeClient{ServerError: "TEST_TIMEOUT_ERROR"},
+			expectedValues:    []string{"", ""},
+			expectedErrors: []error{
+				utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED),
+				utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED),
+			},
+		},
+		{
+			desc: "AerospikeBackend.GetBatch() returns a KEY_NOT_FOUND error for records that don't exist",
+			inAerospikeClient: &GoodAerospikeClient{
+				StoredData: map[string]string{"key1": "value1"},
+			},
+			expectedValues: []string{"value1", ""},
+			expectedErrors: []error{nil, utils.NewPBCError(utils.KEY_NOT_FOUND)},
+		},
+	}
+
+	for _, tt := range testCases {
+		aerospikeBackend.client = tt.inAerospikeClient
+
+		// Run test
+		values, errs := aerospikeBackend.GetBatch(context.Background(), []string{"key1", "key2"})
+
+		// Assertions
+		assert.Equal(t, tt.expectedValues, values, tt.desc)
+		assert.Equal(t, tt.expectedErrors, errs, tt.desc)
+	}
+}
+
+func TestApplyDeadline(t *testing.T) {
+	testCases := []struct {
+		desc                  string
+		inTimeout             time.Duration
+		inPolicy              as.BasePolicy
+		expectedTotalTimeout  time.Duration
+		expectedSocketTimeout time.Duration
+		expectedErr           error
+	}{
+		{
+			desc:                  "Context without deadline leaves the policy timeouts untouched",
+			inPolicy:              as.BasePolicy{TotalTimeout: time.Second, SocketTimeout: 30 * time.Second},
+			expectedTotalTimeout:  time.Second,
+			expectedSocketTimeout: 30 * time.Second,
+		},
+		{
+			desc:                  "Deadline sooner than the policy timeouts caps both of them",
+			inTimeout:             time.Minute,
+			inPolicy:              as.BasePolicy{TotalTimeout: time.Hour, SocketTimeout: time.Hour},
+			expectedTotalTimeout:  time.Minute,
+			expectedSocketTimeout: time.Minute,
+		},
+		{
+			desc:                  "Disabled policy timeouts get set to the deadline",
+			inTimeout:             time.Minute,
+			inPolicy:              as.BasePolicy{},
+			expectedTotalTimeout:  time.Minute,
+			expectedSocketTimeout: time.Minute,
+		},
+		{
+			desc:                  "Policy timeouts sooner than the deadline are kept",
+			inTimeout:             time.Hour,
+			inPolicy:              as.BasePolicy{TotalTimeout: time.Second, SocketTimeout: 100 * time.Millisecond},
+			expectedTotalTimeout:  time.Second,
+			expectedSocketTimeout: 100 * time.Millisecond,
+		},
+		{
+			desc:        "Deadline already passed",
+			inTimeout:   -time.Second,
+			inPolicy:    as.BasePolicy{TotalTimeout: time.Second},
+			expectedErr: as.ErrTimeout,
+		},
+	}
+
+	for _, tt := range testCases {
+		ctx := context.Background()
+		if tt.inTimeout != 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, tt.inTimeout)
+			defer cancel()
+		}
+		policy := tt.inPolicy
+
+		err := applyDeadline(ctx, &policy)
+
+		assert.Equal(t, tt.expectedErr, err, tt.desc)
+		if tt.expectedErr == nil {
+			// Time elapses between the creation of the context and the policy update
+			assert.InDelta(t, tt.expectedTotalTimeout, policy.TotalTimeout, float64(time.Second), tt.desc)
+			assert.InDelta(t, tt.expectedSocketTimeout, policy.SocketTimeout, float64(time.Second), tt.desc)
+		}
+	}
+}
+
+// policyRecorderAerospikeClient stores the policies the backend sends along every operation
+type policyRecorderAerospikeClient struct {
+	GoodAerospikeClient
+	readPolicy         *as.BasePolicy
+	writePolicy        *as.WritePolicy
+	batchWritePolicies []*as.BatchWritePolicy
+}
+
+func (c *policyRecorderAerospikeClient) Get(policy *as.BasePolicy, aeKey *as.Key) (*as.Record, error) {
+	c.readPolicy = policy
+	return c.GoodAerospikeClient.Get(policy, aeKey)
+}
+
+func (c *policyRecorderAerospikeClient) Put(policy *as.WritePolicy, aeKey *as.Key, binMap as.BinMap) error {
+	c.writePolicy = policy
+	return c.GoodAerospikeClient.Put(policy, aeKey, binMap)
+}
+
+func (c *policyRecorderAerospikeClient) Delete(policy *as.WritePolicy, aeKey *as.Key) (bool, error) {
+	c.writePolicy = policy
+	return c.GoodAerospikeClient.Delete(policy, aeKey)
+}
+
+func (c *policyRecorderAerospikeClient) BatchPut(policy *as.BatchPolicy, policies []*as.BatchWritePolicy, keys []*as.Key, binMaps []as.BinMap) []error {
+	c.batchWritePolicies = policies
+	return c.GoodAerospikeClient.BatchPut(policy, policies, keys, binMaps)
+}
+
+func TestAerospikeBackendPolicies(t *testing.T) {
+	client := &policyRecorderAerospikeClient{GoodAerospikeClient: GoodAerospikeClient{StoredData: map[string]string{}}}
+	readPolicy := &as.BasePolicy{MaxRetries: 4, TotalTimeout: time.Hour, SocketTimeout: time.Hour}
+	writePolicy := &as.WritePolicy{BasePolicy: as.BasePolicy{MaxRetries: 3, TotalTimeout: time.Hour, SendKey: true}, CommitLevel: as.COMMIT_MASTER, DurableDelete: true}
+	aerospikeBackend := &AerospikeBackend{
+		binName:     binValue,
+		client:      client,
+		readPolicy:  readPolicy,
+		writePolicy: writePolicy,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	assert.Nil(t, aerospikeBackend.Put(ctx, "key", "value", 60), "Put")
+	assert.Equal(t, 3, client.writePolicy.MaxRetries, "Put keeps the default write policy settings")
+	assert.Equal(t, uint32(60), client.writePolicy.Expiration, "Put sets the TTL")
+	assert.Equal(t, as.CREATE_ONLY, client.writePolicy.RecordExistsAction, "Put doesn't overwrite records")
+	assert.True(t, client.writePolicy.TotalTimeout <= time.Minute, "Put total timeout is capped by the context deadline")
+	assert.True(t, client.writePolicy.SocketTimeout <= time.Minute, "Put socket timeout is capped by the context deadline")
+
+	errs := aerospikeBackend.PutBatch(ctx, []BatchPutItem{{Key: "batchKey", Value: "value", TTLSeconds: 30}})
+	assert.Equal(t, []error{nil}, errs, "PutBatch")
+	if assert.Len(t, client.batchWritePolicies, 1, "PutBatch") {
+		policy := client.batchWritePolicies[0]
+		assert.True(t, policy.SendKey, "PutBatch keeps the default write policy send_key")
+		assert.Equal(t, as.COMMIT_MASTER, policy.CommitLevel, "PutBatch keeps the default write policy settings")
+		assert.True(t, policy.DurableDelete, "PutBatch keeps the default write policy settings")
+		assert.Equal(t, uint32(30), policy.Expiration, "PutBatch sets the TTL")
+		assert.Equal(t, as.CREATE_ONLY, policy.RecordExistsAction, "PutBatch doesn't overwrite records")
+	}
+
+	value, err := aerospikeBackend.Get(ctx, "key")
+	assert.Nil(t, err, "Get")
+	assert.Equal(t, "value", value, "Get")
+	assert.Equal(t, 4, client.readPolicy.MaxRetries, "Get keeps the default read policy settings")
+	assert.True(t, client.readPolicy.TotalTimeout <= time.Minute, "Get total timeout is capped by the context deadline")
+	assert.True(t, client.readPolicy.SocketTimeout <= time.Minute, "Get socket timeout is capped by the context deadline")
+
+	// Default policies are copied rather than modified
+	assert.Equal(t, time.Hour, readPolicy.TotalTimeout, "Default read policy")
+	assert.Equal(t, time.Hour, writePolicy.TotalTimeout, "Default write policy")
+	assert.Equal(t, uint32(0), writePolicy.Expiration, "Default write policy")
+
+	expired, cancelExpired := context.WithTimeout(context.Background(), -time.Second)
+	defer cancelExpired()
+	client.readPolicy, client.writePolicy = nil, nil
+
+	assert.Equal(t, utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED), aerospikeBackend.Put(expired, "other", "value", 60), "Put past the deadline")
+	assert.Nil(t, client.writePolicy, "Put past the deadline doesn't reach Aerospike")
+	assert.Equal(t, utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED), aerospikeBackend.Delete(expired, "key"), "Delete past the deadline")
+	assert.Nil(t, client.writePolicy, "Delete past the deadline doesn't reach Aerospike")
+	_, err = aerospikeBackend.Get(expired, "key")
+	assert.Equal(t, utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED), err, "Get past the deadline")
+	assert.Nil(t, client.readPolicy, "Get past the deadline doesn't reach Aerospike")
+}
+
+func TestNewAerospikeClientPolicy(t *testing.T) {
+	testCases := []struct {
+		desc              string
+		inCfg             config.Aerospike
+		expectedRackAware bool
+		expectedRackIds   []int
+	}{
+		{
+			desc:  "Reads from the master node don't need rack information",
+			inCfg: config.Aerospike{ReadReplica: config.AerospikeReplicaMaster, RackID: 3},
+		},
+		{
+			desc:              "Rack aware reads",
+			inCfg:             config.Aerospike{ReadReplica: config.AerospikeReplicaPreferRack, RackID: 3},
+			expectedRackAware: true,
+			expectedRackIds:   []int{3},
+		},
+	}
+
+	for _, tt := range testCases {
+		clientPolicy, err := newAerospikeClientPolicy(tt.inCfg)
+
+		assert.Nil(t, err, tt.desc)
+		assert.Equal(t, tt.expectedRackAware, clientPolicy.RackAware, tt.desc)
+		assert.Equal(t, tt.expectedRackIds, clientPolicy.RackIds, tt.desc)
+	}
+}
+
+func TestNewAerospikeClientPolicySecurity(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		inCfg            config.Aerospike
+		expectedAuthMode as.AuthMode
+		expectedTLS      bool
+		expectedCerts    int
+		expectedErr      error
+	}{
+		{
+			desc:             "Internal authentication without TLS",
+			inCfg:            config.Aerospike{User: "foo", Password: "bar", AuthMode: config.AerospikeAuthInternal},
+			expectedAuthMode: as.AuthModeInternal,
+		},
+		{
+			desc:             "External authentication over TLS",
+			inCfg:            config.Aerospike{User: "foo", Password: "bar", AuthMode: config.AerospikeAuthExternal, TLS: config.AerospikeTLS{Enabled: true, CAFile: "../config/configtest/tls/ca.pem"}},
+			expectedAuthMode: as.AuthModeExternal,
+			expectedTLS:      true,
+		},
+		{
+			desc:             "PKI authentication with a client certificate",
+			inCfg:            config.Aerospike{AuthMode: config.AerospikeAuthPKI, TLS: config.AerospikeTLS{Enabled: true, CertFile: "../config/configtest/tls/client.pem", KeyFile: "../config/configtest/tls/client-key.pem"}},
+			expectedAuthMode: as.AuthModePKI,
+			expectedTLS:      true,
+			expectedCerts:    1,
+		},
+		{
+			desc:        "Unreadable client key",
+			inCfg:       config.Aerospike{AuthMode: config.AerospikeAuthPKI, TLS: config.AerospikeTLS{Enabled: true, CertFile: "../config/configtest/tls/client.pem", KeyFile: "../config/configtest/tls/missing.pem"}},
+			expectedErr: fmt.Errorf("invalid config.backend.aerospike.tls.cert_file or key_file: open ../config/configtest/tls/missing.pem: no such file or directory"),
+		},
+	}
+
+	for _, tt := range testCases {
+		clientPolicy, err := newAerospikeClientPolicy(tt.inCfg)
+
+		assert.Equal(t, tt.expectedErr, err, tt.desc)
+		if tt.expectedErr != nil {
+			continue
+		}
+		assert.Equal(t, tt.expectedAuthMode, clientPolicy.AuthMode, tt.desc)
+		assert.Equal(t, tt.expectedTLS, clientPolicy.TlsConfig != nil, tt.desc)
+		if tt.expectedTLS {
+			assert.Len(t, clientPolicy.TlsConfig.Certificates, tt.expectedCerts, tt.desc)
+		}
+	}
+}
+
+func TestNewAerospikeHosts(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		inCfg         config.Aerospike
+		expectedHosts []*as.Host
+	}{
+		{
+			desc:  "Hosts without TLS",
+			inCfg: config.Aerospike{Host: "foo.com", Hosts: []string{"bar.com"}, Port: 3000},
+			expectedHosts: []*as.Host{
+				{Name: "foo.com", Port: 3000},
+				{Name: "bar.com", Port: 3000},
+			},
+		},
+		{
+			desc:  "Hosts with their own TLS name or the default one",
+			inCfg: config.Aerospike{Hosts: []string{"foo.com:foo-tls", "bar.com", "[2001:db8::1]:ipv6-tls"}, Port: 4333, TLS: config.AerospikeTLS{Enabled: true, Name: "cluster-tls"}},
+			expectedHosts: []*as.Host{
+				{Name: "foo.com", TLSName: "foo-tls", Port: 4333},
+				{Name: "bar.com", TLSName: "cluster-tls", Port: 4333},
+				{Name: "2001:db8::1", TLSName: "ipv6-tls", Port: 4333},
+			},
+		},
+	}
+
+	for _, tt := range testCases {
+		assert.Equal(t, tt.expectedHosts, newAerospikeHosts(tt.inCfg), tt.desc)
+	}
+}
+
+func TestConfigureAerospikePolicies(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		inCfg              config.Aerospike
+		expectedReplica    as.ReplicaPolicy
+		expectedReadModeAP as.ReadModeAP
+		expectedSendKey    bool
+	}{
+		{
+			desc:               "Default read policies",
+			inCfg:              config.Aerospike{ReadReplica: config.AerospikeReplicaSequence, ReadModeAP: config.AerospikeReadModeAPOne},
+			expectedReplica:    as.SEQUENCE,
+			expectedReadModeAP: as.ReadModeAPOne,
+		},
+		{
+			desc:               "Reads from the master node consulting every duplicate",
+			inCfg:              config.Aerospike{ReadReplica: config.AerospikeReplicaMaster, ReadModeAP: config.AerospikeReadModeAPAll},
+			expectedReplica:    as.MASTER,
+			expectedReadModeAP: as.ReadModeAPAll,
+		},
+		{
+			desc:               "Rack aware reads that send the user key",
+			inCfg:              config.Aerospike{ReadReplica: config.AerospikeReplicaPreferRack, RackID: 1, ReadModeAP: config.AerospikeReadModeAPOne, SendKey: true},
+			expectedReplica:    as.PREFER_RACK,
+			expectedReadModeAP: as.ReadModeAPOne,
+			expectedSendKey:    true,
+		},
+	}
+
+	for _, tt := range testCases {
+		readPolicy, writePolicy, batchPolicy := as.NewPolicy(), as.NewWritePolicy(0, 0), as.NewBatchPolicy()
+
+		configureAerospikePolicies(tt.inCfg, readPolicy, writePolicy, batchPolicy)
+
+		assert.Equal(t, tt.expectedReplica, readPolicy.ReplicaPolicy, tt.desc)
+		assert.Equal(t, tt.expectedReplica, batchPolicy.ReplicaPolicy, tt.desc)
+		assert.Equal(t, tt.expectedReadModeAP, readPolicy.ReadModeAP, tt.desc)
+		assert.Equal(t, tt.expectedReadModeAP, batchPolicy.ReadModeAP, tt.desc)
+		assert.Equal(t, tt.expectedSendKey, readPolicy.SendKey, tt.desc)
+		assert.Equal(t, tt.expectedSendKey, writePolicy.SendKey, tt.desc)
+		assert.Equal(t, tt.expectedSendKey, batchPolicy.SendKey, tt.desc)
+	}
+}
+
+func TestAerospikeBackendBinName(t *testing.T) {
+	aerospikeBackend := &AerospikeBackend{binName: "payload"}
+
+	value, err := aerospikeBackend.recordValue(&as.Record{Bins: as.BinMap{"payload": "stored value"}})
+	assert.Nil(t, err, "Value stored in the configured bin")
+	assert.Equal(t, "stored value", value, "Value stored in the configured bin")
+
+	_, err = aerospikeBackend.recordValue(&as.Record{Bins: as.BinMap{binValue: "stored value"}})
+	assert.Equal(t, "No 'payload' bucket found", err.Error(), "Value stored in a different bin")
+}
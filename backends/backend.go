@@ -2,10 +2,94 @@ package backends
 
 import (
 	"context"
+	"sync"
 )
 
 // Backend interface for storing data
 type Backend interface {
 	Put(ctx context.Context, key string, value string, ttlSeconds int) error
 	Get(ctx context.Context, key string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// BatchPutItem is a single element of a batched put request
+type BatchPutItem struct {
+	Key        string
+	Value      string
+	TTLSeconds int
+}
+
+// BatchPutter is implemented by those backends that can store several elements in a single
+// round trip to their storage service. PutBatch returns one error per item, in the same order
+// the items were passed in, and with the same meaning Put would have given it
+type BatchPutter interface {
+	PutBatch(ctx context.Context, items []BatchPutItem) []error
+}
+
+// BatchGetter is implemented by those backends that can retrieve several elements in a single
+// round trip to their storage service. GetBatch returns one value and one error per key, in the
+// same order the keys were passed in, and with the same meaning Get would have given them
+type BatchGetter interface {
+	GetBatch(ctx context.Context, keys []string) ([]string, []error)
+}
+
+// PutBatch stores every item in backend. If backend implements BatchPutter the items are sent
+// together, otherwise PutBatch falls back to calling backend.Put once per item in parallel
+func PutBatch(ctx context.Context, backend Backend, items []BatchPutItem) []error {
+	if batchPutter, ok := backend.(BatchPutter); ok {
+		return batchPutter.PutBatch(ctx, items)
+	}
+	return putEach(ctx, backend, items)
+}
+
+// putEach calls backend.Put once per item in parallel. Backends that can only send items
+// together under some configurations fall back to it under the others
+func putEach(ctx context.Context, backend Backend, items []BatchPutItem) []error {
+	errs := make([]error, len(items))
+
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(len(items))
+	for i := range items {
+		go func(i int) {
+			defer waitGroup.Done()
+			errs[i] = backend.Put(ctx, items[i].Key, items[i].Value, items[i].TTLSeconds)
+		}(i)
+	}
+	waitGroup.Wait()
+
+	return errs
+}
+
+// GetBatch retrieves the values stored under keys. If backend implements BatchGetter the keys
+// are requested together, otherwise GetBatch falls back to calling backend.Get once per key
+// in parallel
+func GetBatch(ctx context.Context, backend Backend, keys []string) ([]string, []error) {
+	if batchGetter, ok := backend.(BatchGetter); ok {
+		return batchGetter.GetBatch(ctx, keys)
+	}
+
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(len(keys))
+	for i := range keys {
+		go func(i int) {
+			defer waitGroup.Done()
+			values[i], errs[i] = backend.Get(ctx, keys[i])
+		}(i)
+	}
+	waitGroup.Wait()
+
+	return values, errs
+}
+
+// batchError returns a slice of n errors, all of them set to err. Useful when a batch request
+// fails as a whole before any individual result is known
+func batchError(n int, err error) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
 }
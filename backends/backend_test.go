@@ -0,0 +1,98 @@
+package backends
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPutBatch(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		inBackend      Backend
+		expectedErrors []error
+		expectedStored map[string]string
+	}{
+		{
+			desc:      "Backend doesn't implement BatchPutter. Expect a Put call per item",
+			inBackend: NewMemoryBackend(config.Memory{}),
+			expectedErrors: []error{
+				nil,
+				utils.NewPBCError(utils.RECORD_EXISTS),
+				nil,
+			},
+			expectedStored: map[string]string{
+				"existingKey": "original value",
+				"key1":        "value1",
+				"key2":        "value2",
+			},
+		},
+		{
+			desc:      "Backend implements BatchPutter. Expect items to be sent together",
+			inBackend: NewFakeRedisBackend(FakeRedisClient{StoredData: map[string]string{}, Success: true}),
+			expectedErrors: []error{
+				nil,
+				utils.NewPBCError(utils.RECORD_EXISTS),
+				nil,
+			},
+			expectedStored: map[string]string{
+				"existingKey": "original value",
+				"key1":        "value1",
+				"key2":        "value2",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Set up test
+		assert.NoError(t, tc.inBackend.Put(context.Background(), "existingKey", "original value", 0), tc.desc)
+		items := []BatchPutItem{
+			{Key: "key1", Value: "value1", TTLSeconds: 10},
+			{Key: "existingKey", Value: "overwrite value", TTLSeconds: 10},
+			{Key: "key2", Value: "value2", TTLSeconds: 10},
+		}
+
+		// Run test
+		errs := PutBatch(context.Background(), tc.inBackend, items)
+
+		// Assertions
+		assert.Equal(t, tc.expectedErrors, errs, tc.desc)
+		for key, expectedValue := range tc.expectedStored {
+			value, err := tc.inBackend.Get(context.Background(), key)
+			assert.NoError(t, err, tc.desc)
+			assert.Equal(t, expectedValue, value, tc.desc)
+		}
+	}
+}
+
+func TestGetBatch(t *testing.T) {
+	memoryBackend := NewMemoryBackend(config.Memory{})
+	memoryBackend.Put(context.Background(), "key1", "value1", 0)
+	memoryBackend.Put(context.Background(), "key2", "value2", 0)
+
+	testCases := []struct {
+		desc      string
+		inBackend Backend
+	}{
+		{
+			desc:      "Backend doesn't implement BatchGetter. Expect a Get call per key",
+			inBackend: memoryBackend,
+		},
+		{
+			desc:      "Backend implements BatchGetter. Expect keys to be requested together",
+			inBackend: NewFakeRedisBackend(FakeRedisClient{StoredData: map[string]string{"key1": "value1", "key2": "value2"}}),
+		},
+	}
+
+	for _, tc := range testCases {
+		// Run test
+		values, errs := GetBatch(context.Background(), tc.inBackend, []string{"key1", "unknownKey", "key2"})
+
+		// Assertions
+		assert.Equal(t, []string{"value1", "", "value2"}, values, tc.desc)
+		assert.Equal(t, []error{nil, utils.NewPBCError(utils.KEY_NOT_FOUND), nil}, errs, tc.desc)
+	}
+}
@@ -2,10 +2,12 @@ package backends
 
 import (
 	"context"
+	"time"
 
 	"git.pubmatic.com/PubMatic/go-common/logger"
 	"github.com/gocql/gocql"
 	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/utils"
 )
 
@@ -15,14 +17,18 @@ type CassandraDB interface {
 	Init() error
 	Get(ctx context.Context, key string) (string, error)
 	Put(ctx context.Context, key string, value string, ttlSeconds int) (bool, error)
+	Delete(ctx context.Context, key string) (bool, error)
+	GetBatch(ctx context.Context, keys []string) (map[string]string, error)
+	PutBatch(ctx context.Context, items []BatchPutItem) error
 }
 
 // CassandraDBClient is a wrapper for the Cassandra client 'gocql' that
 // interacts with the Cassandra server and implements the CassandraDB interface
 type CassandraDBClient struct {
-	cfg     config.Cassandra
-	cluster *gocql.ClusterConfig
-	session *gocql.Session
+	cfg             config.Cassandra
+	cluster         *gocql.ClusterConfig
+	session         *gocql.Session
+	readConsistency gocql.Consistency
 }
 
 // Get returns the value associated with the provided `key` parameter
@@ -31,7 +37,7 @@ func (c *CassandraDBClient) Get(ctx context.Context, key string) (string, error)
 
 	err := c.session.Query(`SELECT value FROM cache WHERE key = ? LIMIT 1`, key).
 		WithContext(ctx).
-		Consistency(gocql.One).
+		Consistency(c.readConsistency).
 		Scan(&res)
 
 	return res, err
@@ -39,8 +45,13 @@ func (c *CassandraDBClient) Get(ctx context.Context, key string) (string, error)
 
 // Put writes the `value` under the provided `key` in the Cassandra DB server
 // only if it doesn't already exist. We make sure of this by adding the 'IF NOT EXISTS'
-// clause to the 'INSERT' query
+// clause to the 'INSERT' query. If config.backend.cassandra.write_mode is "plain", the
+// lightweight transaction is skipped and `key` gets overwritten if it already existed
 func (c *CassandraDBClient) Put(ctx context.Context, key string, value string, ttlSeconds int) (bool, error) {
+	if c.cfg.WriteMode == config.CassandraWritePlain {
+		return c.putPlain(ctx, key, value, ttlSeconds)
+	}
+
 	var insertedKey, insertedValue string
 
 	return c.session.Query(`INSERT INTO cache (key, value) VALUES (?, ?) IF NOT EXISTS USING TTL ?`, key, value, ttlSeconds).
@@ -48,34 +59,168 @@ func (c *CassandraDBClient) Put(ctx context.Context, key string, value string, t
 		ScanCAS(&insertedKey, &insertedValue)
 }
 
+// putPlain writes `value` under `key` without a lightweight transaction, which saves the Paxos
+// round trips but can't tell whether `key` already existed, so the write is always reported
+// as applied. gocql prepares the statement on first use and caches it per host afterwards.
+// Unlike the conditional insert, the query is idempotent and therefore safe to retry
+func (c *CassandraDBClient) putPlain(ctx context.Context, key string, value string, ttlSeconds int) (bool, error) {
+	err := c.session.Query(`INSERT INTO cache (key, value) VALUES (?, ?) USING TTL ?`, key, value, ttlSeconds).
+		WithContext(ctx).
+		Idempotent(true).
+		Exec()
+
+	return true, err
+}
+
+// PutBatch writes every item with a single unlogged batch of the plain inserts putPlain runs.
+// Items may belong to different partitions, so the batch only saves round trips and its
+// outcome, which the server reports for the batch as a whole, applies to every item
+func (c *CassandraDBClient) PutBatch(ctx context.Context, items []BatchPutItem) error {
+	batch := c.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+	for _, item := range items {
+		batch.Entries = append(batch.Entries, gocql.BatchEntry{
+			Stmt:       `INSERT INTO cache (key, value) VALUES (?, ?) USING TTL ?`,
+			Args:       []interface{}{item.Key, item.Value, item.TTLSeconds},
+			Idempotent: true,
+		})
+	}
+
+	return c.session.ExecuteBatch(batch)
+}
+
+// Delete removes the row stored under `key` from the Cassandra DB server. The 'IF EXISTS'
+// clause makes the server report whether or not the row was there to begin with. If
+// config.backend.cassandra.write_mode is "plain", the lightweight transaction is skipped
+// as well, so the delete can't tell whether `key` existed and is always reported as applied
+func (c *CassandraDBClient) Delete(ctx context.Context, key string) (bool, error) {
+	if c.cfg.WriteMode == config.CassandraWritePlain {
+		err := c.session.Query(`DELETE FROM cache WHERE key = ?`, key).
+			WithContext(ctx).
+			Idempotent(true).
+			Exec()
+
+		return true, err
+	}
+
+	var deletedKey, deletedValue string
+
+	return c.session.Query(`DELETE FROM cache WHERE key = ? IF EXISTS`, key).
+		WithContext(ctx).
+		ScanCAS(&deletedKey, &deletedValue)
+}
+
+// GetBatch returns the values associated with the provided `keys` in a single query. Keys that
+// don't exist are absent from the returned map
+func (c *CassandraDBClient) GetBatch(ctx context.Context, keys []string) (map[string]string, error) {
+	var key, value string
+	values := make(map[string]string, len(keys))
+
+	iter := c.session.Query(`SELECT key, value FROM cache WHERE key IN ?`, keys).
+		WithContext(ctx).
+		Consistency(c.readConsistency).
+		Iter()
+	for iter.Scan(&key, &value) {
+		values[key] = value
+	}
+
+	return values, iter.Close()
+}
+
 // Init initializes Cassandra cluster and session with the configuration
-// loaded from environment variables or configuration files at startup
+// loaded from environment variables or configuration files at startup, and
+// verifies the live schema matches the one the queries expect
 func (c *CassandraDBClient) Init() error {
-	c.cluster = gocql.NewCluster(c.cfg.Hosts)
-	c.cluster.Keyspace = c.cfg.Keyspace
-	c.cluster.Consistency = gocql.LocalOne
-
 	var err error
+	c.readConsistency, err = gocql.ParseConsistencyWrapper(c.cfg.ReadConsistency)
+	if err != nil {
+		return err
+	}
+
+	c.cluster, err = newCassandraCluster(c.cfg)
+	if err != nil {
+		return err
+	}
+
 	c.session, err = c.cluster.CreateSession()
 	if err != nil {
 		logger.Fatal("Error creating Cassandra backend: %v", err)
+		return err
 	}
 
-	return err
+	return checkCassandraSchema(c.session, c.cfg)
+}
+
+// newCassandraCluster translates cfg into the gocql cluster configuration sessions get created
+// from. Queries that don't set their own consistency level, namely writes, use the cluster's
+func newCassandraCluster(cfg config.Cassandra) (*gocql.ClusterConfig, error) {
+	cluster := gocql.NewCluster(cfg.Hosts)
+	cluster.Keyspace = cfg.Keyspace
+
+	var err error
+	cluster.Consistency, err = gocql.ParseConsistencyWrapper(cfg.WriteConsistency)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Username) > 0 {
+		cluster.Authenticator = gocql.PasswordAuthenticator{
+			Username: cfg.Username,
+			Password: cfg.Password,
+		}
+	}
+
+	if cfg.TLS.Enabled {
+		tlsConfig, err := cfg.TLS.NewTLSConfig()
+		if err != nil {
+			return nil, err
+		}
+		cluster.SslOpts = &gocql.SslOptions{
+			Config:                 tlsConfig,
+			EnableHostVerification: !cfg.TLS.InsecureSkipVerify,
+		}
+	}
+
+	if len(cfg.LocalDC) > 0 {
+		cluster.PoolConfig.HostSelectionPolicy = gocql.TokenAwareHostPolicy(gocql.DCAwareRoundRobinPolicy(cfg.LocalDC))
+	}
+
+	// Non-positive values keep the gocql defaults
+	if cfg.ConnectTimeoutMillis > 0 {
+		cluster.ConnectTimeout = time.Duration(cfg.ConnectTimeoutMillis) * time.Millisecond
+	}
+	if cfg.TimeoutMillis > 0 {
+		cluster.Timeout = time.Duration(cfg.TimeoutMillis) * time.Millisecond
+	}
+
+	if cfg.Retry.MaxRetries > 0 {
+		cluster.RetryPolicy = &gocql.ExponentialBackoffRetryPolicy{
+			NumRetries: cfg.Retry.MaxRetries,
+			Min:        time.Duration(cfg.Retry.MinBackoffMillis) * time.Millisecond,
+			Max:        time.Duration(cfg.Retry.MaxBackoffMillis) * time.Millisecond,
+		}
+	}
+
+	return cluster, nil
 }
 
 // CassandraBackend implements the Backend interface and get called from
-// our Prebid Cache's endpoint handle functions.
+// our Prebid Cache's endpoint handle functions. It also implements BatchGetter and
+// BatchPutter, although items only get batched together in "plain" write mode: Cassandra
+// rejects conditional batches that span more than one partition.
 type CassandraBackend struct {
 	defaultTTL int
 	client     CassandraDB
+	writeMode  config.CassandraWriteMode
+	metrics    *metrics.Metrics
 }
 
 // NewCassandraBackend expects a valid config.Cassandra object
-func NewCassandraBackend(cfg config.Cassandra) *CassandraBackend {
+func NewCassandraBackend(cfg config.Cassandra, metrics *metrics.Metrics) *CassandraBackend {
 	backend := &CassandraBackend{
-		cfg.DefaultTTL,
-		&CassandraDBClient{cfg: cfg},
+		defaultTTL: cfg.DefaultTTL,
+		client:     &CassandraDBClient{cfg: cfg},
+		writeMode:  cfg.WriteMode,
+		metrics:    metrics,
 	}
 
 	if err := backend.client.Init(); err != nil {
@@ -100,12 +245,68 @@ func (back *CassandraBackend) Get(ctx context.Context, key string) (string, erro
 
 // Put makes the Cassandra client to store `value` only if `key` doesn't
 // exist in the storage already. If it does, no operation is performed and Put
-// returns RecordExistsError
+// returns RecordExistsError. The duration of the write gets logged under the configured
+// write mode so lightweight transactions and plain inserts can be compared
 func (back *CassandraBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
-
+	start := time.Now()
 	applied, err := back.client.Put(ctx, key, value, ttlSeconds)
+	back.metrics.RecordCassandraWriteDuration(back.writeMode, time.Since(start))
+
 	if !applied {
 		return utils.NewPBCError(utils.RECORD_EXISTS)
 	}
 	return err
 }
+
+// Delete makes the Cassandra client remove the value stored under `key`. Returns
+// KeyNotFoundError if no such key existed in the storage, which deletes can't report
+// when config.backend.cassandra.write_mode is "plain"
+func (back *CassandraBackend) Delete(ctx context.Context, key string) error {
+	applied, err := back.client.Delete(ctx, key)
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+	return nil
+}
+
+// GetBatch makes the Cassandra client retrieve the values stored under every key with a
+// single query. Keys that were never stored come with a KeyNotFoundError
+func (back *CassandraBackend) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	stored, err := back.client.GetBatch(ctx, keys)
+	if err != nil {
+		return make([]string, len(keys)), batchError(len(keys), err)
+	}
+
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		if value, found := stored[key]; found {
+			values[i] = value
+		} else {
+			errs[i] = utils.NewPBCError(utils.KEY_NOT_FOUND)
+		}
+	}
+	return values, errs
+}
+
+// PutBatch makes the Cassandra client store every item with a single unlogged batch when
+// config.backend.cassandra.write_mode is "plain". The 'IF NOT EXISTS' lightweight transactions
+// of the "lwt" write mode can't be batched across partitions, so every item gets its own Put
+// in that mode
+func (back *CassandraBackend) PutBatch(ctx context.Context, items []BatchPutItem) []error {
+	if back.writeMode != config.CassandraWritePlain {
+		return putEach(ctx, back, items)
+	}
+
+	start := time.Now()
+	err := back.client.PutBatch(ctx, items)
+	back.metrics.RecordCassandraWriteDuration(back.writeMode, time.Since(start))
+
+	if err != nil {
+		return batchError(len(items), err)
+	}
+	return make([]error, len(items))
+}
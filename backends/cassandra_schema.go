@@ -0,0 +1,239 @@
+package backends
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"git.pubmatic.com/PubMatic/go-common/logger"
+	"github.com/gocql/gocql"
+	"github.com/prebid/prebid-cache/config"
+)
+
+// cassandraTable is the table CassandraDBClient queries inside config.backend.cassandra.keyspace
+const cassandraTable = "cache"
+
+// cassandraColumns lists the columns of cassandraTable along with the CQL type and kind the
+// queries in this package rely on
+var cassandraColumns = map[string]cassandraColumn{
+	"key":   {cqlType: "text", kind: "partition_key"},
+	"value": {cqlType: "text", kind: "regular"},
+}
+
+// Keyspace names get embedded in CQL statements, so they are restricted to the unquoted
+// identifiers Cassandra accepts
+var cassandraKeyspaceName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,47}$`)
+
+type cassandraColumn struct {
+	cqlType string
+	kind    string
+}
+
+// cassandraLiveSchema holds what the cluster reports about the keyspace and table. A nil
+// replication map means the keyspace doesn't exist and nil columns mean the table doesn't
+type cassandraLiveSchema struct {
+	replication map[string]string
+	columns     map[string]cassandraColumn
+	defaultTTL  int
+	compaction  map[string]string
+}
+
+// CreateCassandraSchema creates the keyspace and table the Cassandra backend stores data in,
+// as described by cfg.Schema. Existing keyspaces and tables are left untouched
+func CreateCassandraSchema(cfg config.Cassandra) error {
+	statements, err := cassandraSchemaStatements(cfg)
+	if err != nil {
+		return err
+	}
+
+	cluster, err := newCassandraCluster(cfg)
+	if err != nil {
+		return err
+	}
+	// The keyspace might not exist yet, so the session can't be bound to it
+	cluster.Keyspace = ""
+	// Schema changes must reach every node before the statements that depend on them run
+	cluster.Consistency = gocql.All
+
+	session, err := cluster.CreateSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	for _, statement := range statements {
+		logger.Info("Running CQL statement: %s", statement)
+		if err := session.Query(statement).Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cassandraSchemaStatements returns the CQL statements that create the keyspace and table
+func cassandraSchemaStatements(cfg config.Cassandra) ([]string, error) {
+	if !cassandraKeyspaceName.MatchString(cfg.Keyspace) {
+		return nil, fmt.Errorf("invalid config.backend.cassandra.keyspace: %q. Keyspace names must start with a letter, contain only letters, digits and underscores, and be at most 48 characters long", cfg.Keyspace)
+	}
+
+	keyspace := fmt.Sprintf("CREATE KEYSPACE IF NOT EXISTS %s WITH replication = %s;",
+		cfg.Keyspace, cqlMap(expectedCassandraReplication(cfg.Schema)))
+
+	table := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s.%s (key text, value text, PRIMARY KEY (key)) WITH default_time_to_live = %d AND compaction = %s;",
+		cfg.Keyspace, cassandraTable, cfg.Schema.DefaultTTLSeconds, cqlMap(map[string]string{"class": cfg.Schema.CompactionClass}))
+
+	return []string{keyspace, table}, nil
+}
+
+// expectedCassandraReplication translates cfg into the replication options of the keyspace
+func expectedCassandraReplication(cfg config.CassandraSchema) map[string]string {
+	replication := map[string]string{"class": cfg.ReplicationClass}
+	if cfg.ReplicationClass == config.CassandraNetworkTopologyStrategy {
+		for _, dc := range cfg.DatacenterReplicationFactors {
+			replication[dc.Name] = fmt.Sprintf("%d", dc.ReplicationFactor)
+		}
+	} else {
+		replication["replication_factor"] = fmt.Sprintf("%d", cfg.ReplicationFactor)
+	}
+	return replication
+}
+
+// cqlMap formats m as a CQL map literal with its keys sorted so statements are deterministic
+func cqlMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, 0, len(keys))
+	for _, k := range keys {
+		entries = append(entries, fmt.Sprintf("'%s': '%s'", k, m[k]))
+	}
+	return "{" + strings.Join(entries, ", ") + "}"
+}
+
+// checkCassandraSchema compares the live schema against the one the backend expects. Missing
+// or incompatible keyspaces, tables and columns are returned as an error because queries would
+// fail on them, while replication, TTL and compaction differences only get logged. The check
+// is skipped with a warning when the schema can't be read, as happens with Cassandra versions
+// older than 3.0 which don't have the system_schema keyspace
+func checkCassandraSchema(session *gocql.Session, cfg config.Cassandra) error {
+	live, err := readCassandraSchema(session, strings.ToLower(cfg.Keyspace))
+	if err != nil {
+		logger.Warn("Couldn't read the Cassandra schema, skipping its verification: %v", err)
+		return nil
+	}
+
+	mismatches, differences := compareCassandraSchema(cfg, live)
+	for _, difference := range differences {
+		logger.Warn("Cassandra schema differs from config.backend.cassandra.schema: %s", difference)
+	}
+	if len(mismatches) > 0 {
+		return fmt.Errorf("the Cassandra schema doesn't match what the backend expects: %s. Missing keyspaces and tables can be created with the \"schema\" command", strings.Join(mismatches, "; "))
+	}
+	return nil
+}
+
+// readCassandraSchema queries the system_schema keyspace about keyspace and its cache table
+func readCassandraSchema(session *gocql.Session, keyspace string) (cassandraLiveSchema, error) {
+	var live cassandraLiveSchema
+
+	err := session.Query(`SELECT replication FROM system_schema.keyspaces WHERE keyspace_name = ?`, keyspace).
+		Scan(&live.replication)
+	if err == gocql.ErrNotFound {
+		return live, nil
+	} else if err != nil {
+		return live, err
+	}
+
+	err = session.Query(`SELECT default_time_to_live, compaction FROM system_schema.tables WHERE keyspace_name = ? AND table_name = ?`, keyspace, cassandraTable).
+		Scan(&live.defaultTTL, &live.compaction)
+	if err == gocql.ErrNotFound {
+		return live, nil
+	} else if err != nil {
+		return live, err
+	}
+
+	var name string
+	var column cassandraColumn
+	live.columns = make(map[string]cassandraColumn)
+	iter := session.Query(`SELECT column_name, type, kind FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?`, keyspace, cassandraTable).Iter()
+	for iter.Scan(&name, &column.cqlType, &column.kind) {
+		live.columns[name] = column
+	}
+
+	return live, iter.Close()
+}
+
+// compareCassandraSchema returns the mismatches that keep the backend from working and the
+// differences with the settings in cfg.Schema that don't
+func compareCassandraSchema(cfg config.Cassandra, live cassandraLiveSchema) (mismatches []string, differences []string) {
+	keyspace := strings.ToLower(cfg.Keyspace)
+	if live.replication == nil {
+		return []string{fmt.Sprintf("keyspace %s doesn't exist", keyspace)}, nil
+	}
+	if live.columns == nil {
+		return []string{fmt.Sprintf("table %s.%s doesn't exist", keyspace, cassandraTable)}, nil
+	}
+
+	names := make([]string, 0, len(live.columns))
+	for name := range live.columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range []string{"key", "value"} {
+		expected := cassandraColumns[name]
+		column, found := live.columns[name]
+		switch {
+		case !found:
+			mismatches = append(mismatches, fmt.Sprintf("column %s is missing", name))
+		case column.cqlType != expected.cqlType:
+			mismatches = append(mismatches, fmt.Sprintf("column %s is of type %s instead of %s", name, column.cqlType, expected.cqlType))
+		case column.kind != expected.kind:
+			mismatches = append(mismatches, fmt.Sprintf("column %s is a %s column instead of a %s one", name, column.kind, expected.kind))
+		}
+	}
+	for _, name := range names {
+		if _, expected := cassandraColumns[name]; !expected && live.columns[name].kind != "regular" {
+			mismatches = append(mismatches, fmt.Sprintf("column %s is an unexpected %s column", name, live.columns[name].kind))
+		}
+	}
+
+	expectedReplication := expectedCassandraReplication(cfg.Schema)
+	if !sameCassandraOptions(expectedReplication, live.replication) {
+		differences = append(differences, fmt.Sprintf("keyspace %s replication is %s instead of %s", keyspace, cqlMap(live.replication), cqlMap(expectedReplication)))
+	}
+	if live.defaultTTL != cfg.Schema.DefaultTTLSeconds {
+		differences = append(differences, fmt.Sprintf("table %s.%s default_time_to_live is %d instead of %d", keyspace, cassandraTable, live.defaultTTL, cfg.Schema.DefaultTTLSeconds))
+	}
+	if liveClass := unqualifiedCassandraClass(live.compaction["class"]); liveClass != cfg.Schema.CompactionClass {
+		differences = append(differences, fmt.Sprintf("table %s.%s compaction class is %s instead of %s", keyspace, cassandraTable, liveClass, cfg.Schema.CompactionClass))
+	}
+
+	return mismatches, differences
+}
+
+// sameCassandraOptions compares replication options. The system_schema tables report
+// fully qualified class names, such as "org.apache.cassandra.locator.SimpleStrategy"
+func sameCassandraOptions(expected map[string]string, live map[string]string) bool {
+	if len(expected) != len(live) {
+		return false
+	}
+	for k, v := range expected {
+		liveValue := live[k]
+		if k == "class" {
+			liveValue = unqualifiedCassandraClass(liveValue)
+		}
+		if liveValue != v {
+			return false
+		}
+	}
+	return true
+}
+
+func unqualifiedCassandraClass(class string) string {
+	return class[strings.LastIndex(class, ".")+1:]
+}
@@ -0,0 +1,166 @@
+package backends
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassandraSchemaStatements(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		inCfg              config.Cassandra
+		expectedStatements []string
+		expectedError      error
+	}{
+		{
+			desc: "SimpleStrategy replication without a table TTL",
+			inCfg: config.Cassandra{
+				Keyspace: "prebid",
+				Schema: config.CassandraSchema{
+					ReplicationClass:  config.CassandraSimpleStrategy,
+					ReplicationFactor: 1,
+					CompactionClass:   "SizeTieredCompactionStrategy",
+				},
+			},
+			expectedStatements: []string{
+				"CREATE KEYSPACE IF NOT EXISTS prebid WITH replication = {'class': 'SimpleStrategy', 'replication_factor': '1'};",
+				"CREATE TABLE IF NOT EXISTS prebid.cache (key text, value text, PRIMARY KEY (key)) WITH default_time_to_live = 0 AND compaction = {'class': 'SizeTieredCompactionStrategy'};",
+			},
+		},
+		{
+			desc: "NetworkTopologyStrategy replication with a table TTL",
+			inCfg: config.Cassandra{
+				Keyspace: "prebid_cache",
+				Schema: config.CassandraSchema{
+					ReplicationClass:             config.CassandraNetworkTopologyStrategy,
+					ReplicationFactor:            1,
+					DatacenterReplicationFactors: []config.CassandraDatacenterReplication{{Name: "us_east", ReplicationFactor: 3}, {Name: "eu_west", ReplicationFactor: 2}},
+					DefaultTTLSeconds:            3600,
+					CompactionClass:              "TimeWindowCompactionStrategy",
+				},
+			},
+			expectedStatements: []string{
+				"CREATE KEYSPACE IF NOT EXISTS prebid_cache WITH replication = {'class': 'NetworkTopologyStrategy', 'eu_west': '2', 'us_east': '3'};",
+				"CREATE TABLE IF NOT EXISTS prebid_cache.cache (key text, value text, PRIMARY KEY (key)) WITH default_time_to_live = 3600 AND compaction = {'class': 'TimeWindowCompactionStrategy'};",
+			},
+		},
+		{
+			desc: "Keyspace name that isn't a valid identifier",
+			inCfg: config.Cassandra{
+				Keyspace: "prebid; DROP KEYSPACE system",
+			},
+			expectedError: fmt.Errorf(`invalid config.backend.cassandra.keyspace: "prebid; DROP KEYSPACE system". Keyspace names must start with a letter, contain only letters, digits and underscores, and be at most 48 characters long`),
+		},
+	}
+
+	for _, test := range testCases {
+		statements, err := cassandraSchemaStatements(test.inCfg)
+		assert.Equal(t, test.expectedError, err, test.desc)
+		assert.Equal(t, test.expectedStatements, statements, test.desc)
+	}
+}
+
+func TestCompareCassandraSchema(t *testing.T) {
+	cfg := config.Cassandra{
+		Keyspace: "Prebid",
+		Schema: config.CassandraSchema{
+			ReplicationClass:  config.CassandraSimpleStrategy,
+			ReplicationFactor: 3,
+			CompactionClass:   "SizeTieredCompactionStrategy",
+		},
+	}
+	matchingSchema := func() cassandraLiveSchema {
+		return cassandraLiveSchema{
+			replication: map[string]string{"class": "org.apache.cassandra.locator.SimpleStrategy", "replication_factor": "3"},
+			columns: map[string]cassandraColumn{
+				"key":   {cqlType: "text", kind: "partition_key"},
+				"value": {cqlType: "text", kind: "regular"},
+			},
+			compaction: map[string]string{"class": "org.apache.cassandra.db.compaction.SizeTieredCompactionStrategy", "max_threshold": "32"},
+		}
+	}
+
+	testCases := []struct {
+		desc                string
+		inLive              func() cassandraLiveSchema
+		expectedMismatches  []string
+		expectedDifferences []string
+	}{
+		{
+			desc:   "Live schema matches",
+			inLive: matchingSchema,
+		},
+		{
+			desc: "Additional regular columns are tolerated",
+			inLive: func() cassandraLiveSchema {
+				live := matchingSchema()
+				live.columns["created_at"] = cassandraColumn{cqlType: "timestamp", kind: "regular"}
+				return live
+			},
+		},
+		{
+			desc:               "Keyspace doesn't exist",
+			inLive:             func() cassandraLiveSchema { return cassandraLiveSchema{} },
+			expectedMismatches: []string{"keyspace prebid doesn't exist"},
+		},
+		{
+			desc: "Table doesn't exist",
+			inLive: func() cassandraLiveSchema {
+				live := matchingSchema()
+				live.columns = nil
+				return live
+			},
+			expectedMismatches: []string{"table prebid.cache doesn't exist"},
+		},
+		{
+			desc: "Incompatible columns",
+			inLive: func() cassandraLiveSchema {
+				live := matchingSchema()
+				live.columns = map[string]cassandraColumn{
+					"key":     {cqlType: "uuid", kind: "partition_key"},
+					"bucket":  {cqlType: "int", kind: "clustering"},
+					"payload": {cqlType: "blob", kind: "regular"},
+				}
+				return live
+			},
+			expectedMismatches: []string{
+				"column key is of type uuid instead of text",
+				"column value is missing",
+				"column bucket is an unexpected clustering column",
+			},
+		},
+		{
+			desc: "Value is part of the primary key",
+			inLive: func() cassandraLiveSchema {
+				live := matchingSchema()
+				live.columns["value"] = cassandraColumn{cqlType: "text", kind: "clustering"}
+				return live
+			},
+			expectedMismatches: []string{"column value is a clustering column instead of a regular one"},
+		},
+		{
+			desc: "Replication, TTL and compaction differ from the configuration",
+			inLive: func() cassandraLiveSchema {
+				live := matchingSchema()
+				live.replication = map[string]string{"class": "org.apache.cassandra.locator.NetworkTopologyStrategy", "dc1": "3"}
+				live.defaultTTL = 600
+				live.compaction = map[string]string{"class": "org.apache.cassandra.db.compaction.LeveledCompactionStrategy"}
+				return live
+			},
+			expectedDifferences: []string{
+				"keyspace prebid replication is {'class': 'org.apache.cassandra.locator.NetworkTopologyStrategy', 'dc1': '3'} instead of {'class': 'SimpleStrategy', 'replication_factor': '3'}",
+				"table prebid.cache default_time_to_live is 600 instead of 0",
+				"table prebid.cache compaction class is LeveledCompactionStrategy instead of SizeTieredCompactionStrategy",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		mismatches, differences := compareCassandraSchema(cfg, test.inLive())
+		assert.Equal(t, test.expectedMismatches, mismatches, test.desc)
+		assert.Equal(t, test.expectedDifferences, differences, test.desc)
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/gocql/gocql"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
 	"github.com/prebid/prebid-cache/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -78,8 +82,15 @@ func TestCassandraClientGet(t *testing.T) {
 }
 
 func TestCassandraClientPut(t *testing.T) {
+	mockMetrics := metricstest.CreateMockMetrics()
 	cassandraBackend := &CassandraBackend{
 		defaultTTL: 50,
+		writeMode:  config.CassandraWriteLWT,
+		metrics: &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		},
 	}
 
 	type testInput struct {
@@ -155,10 +166,14 @@ func TestCassandraClientPut(t *testing.T) {
 
 	for _, tt := range testCases {
 		cassandraBackend.client = tt.in.cassandraClient
+		mockMetrics.Calls = nil
 
 		// Run test
 		actualErr := cassandraBackend.Put(context.Background(), tt.in.key, tt.in.valueToStore, tt.in.ttl)
 
+		// Assert the write got timed whether or not it succeeded
+		metricstest.AssertMetrics(t, []string{"RecordCassandraWriteDuration"}, mockMetrics)
+
 		// Assert Put error
 		assert.Equal(t, tt.expected.err, actualErr, tt.desc)
 
@@ -171,3 +186,249 @@ func TestCassandraClientPut(t *testing.T) {
 		}
 	}
 }
+
+func TestCassandraClientDelete(t *testing.T) {
+	cassandraBackend := &CassandraBackend{}
+
+	testCases := []struct {
+		desc            string
+		cassandraClient CassandraDB
+		expectedErr     error
+	}{
+		{
+			desc:            "CassandraBackend.Delete() throws a server error",
+			cassandraClient: &ErrorProneCassandraClient{Applied: false, ServerError: errors.New("some delete error")},
+			expectedErr:     errors.New("some delete error"),
+		},
+		{
+			desc:            "CassandraBackend.Delete() was not applied because the key doesn't exist",
+			cassandraClient: &GoodCassandraClient{StoredData: map[string]string{}},
+			expectedErr:     utils.NewPBCError(utils.KEY_NOT_FOUND),
+		},
+		{
+			desc:            "CassandraBackend.Delete() doesn't throw an error",
+			cassandraClient: &GoodCassandraClient{StoredData: map[string]string{"defaultKey": "aValue"}},
+			expectedErr:     nil,
+		},
+		{
+			desc:            "CassandraBackend.Delete() in plain write mode can't tell the key doesn't exist, expect no error",
+			cassandraClient: &GoodCassandraClient{StoredData: map[string]string{}, WriteMode: config.CassandraWritePlain},
+			expectedErr:     nil,
+		},
+	}
+
+	for _, tt := range testCases {
+		cassandraBackend.client = tt.cassandraClient
+
+		// Run test
+		actualErr := cassandraBackend.Delete(context.Background(), "defaultKey")
+
+		// Assertions
+		assert.Equal(t, tt.expectedErr, actualErr, tt.desc)
+	}
+}
+
+func TestCassandraClientGetBatch(t *testing.T) {
+	cassandraBackend := &CassandraBackend{}
+
+	testCases := []struct {
+		desc            string
+		cassandraClient CassandraDB
+		expectedValues  []string
+		expectedErrors  []error
+	}{
+		{
+			desc:            "CassandraBackend.GetBatch() throws a server error. Expect it for every key",
+			cassandraClient: &ErrorProneCassandraClient{ServerError: errors.New("some get error")},
+			expectedValues:  []string{"", ""},
+			expectedErrors:  []error{errors.New("some get error"), errors.New("some get error")},
+		},
+		{
+			desc:            "CassandraBackend.GetBatch() returns a KEY_NOT_FOUND error for keys that don't exist",
+			cassandraClient: &GoodCassandraClient{StoredData: map[string]string{"key1": "value1"}},
+			expectedValues:  []string{"value1", ""},
+			expectedErrors:  []error{nil, utils.NewPBCError(utils.KEY_NOT_FOUND)},
+		},
+	}
+
+	for _, tt := range testCases {
+		cassandraBackend.client = tt.cassandraClient
+
+		// Run test
+		values, errs := cassandraBackend.GetBatch(context.Background(), []string{"key1", "key2"})
+
+		// Assertions
+		assert.Equal(t, tt.expectedValues, values, tt.desc)
+		assert.Equal(t, tt.expectedErrors, errs, tt.desc)
+	}
+}
+
+func TestCassandraClientPutBatch(t *testing.T) {
+	items := []BatchPutItem{
+		{Key: "key1", Value: "value1", TTLSeconds: 10},
+		{Key: "key2", Value: "value2", TTLSeconds: 10},
+	}
+
+	testCases := []struct {
+		desc            string
+		writeMode       config.CassandraWriteMode
+		cassandraClient CassandraDB
+		expectedErrors  []error
+		expectedBatches int
+		expectedStored  map[string]string
+	}{
+		{
+			desc:            "Plain write mode stores every item with a single batch",
+			writeMode:       config.CassandraWritePlain,
+			cassandraClient: &GoodCassandraClient{StoredData: map[string]string{"key2": "original value"}},
+			expectedErrors:  []error{nil, nil},
+			expectedBatches: 1,
+			expectedStored:  map[string]string{"key1": "value1", "key2": "value2"},
+		},
+		{
+			desc:            "Plain write mode batch fails, expect the error for every item",
+			writeMode:       config.CassandraWritePlain,
+			cassandraClient: &ErrorProneCassandraClient{ServerError: errors.New("some batch error")},
+			expectedErrors:  []error{errors.New("some batch error"), errors.New("some batch error")},
+		},
+		{
+			desc:            "LWT write mode puts every item on its own",
+			writeMode:       config.CassandraWriteLWT,
+			cassandraClient: &GoodCassandraClient{StoredData: map[string]string{"key2": "original value"}},
+			expectedErrors:  []error{nil, nil},
+			expectedBatches: 0,
+			expectedStored:  map[string]string{"key1": "value1", "key2": "original value"},
+		},
+		{
+			desc:            "LWT write mode keeps reporting existing records item by item",
+			writeMode:       config.CassandraWriteLWT,
+			cassandraClient: &ErrorProneCassandraClient{Applied: false},
+			expectedErrors:  []error{utils.NewPBCError(utils.RECORD_EXISTS), utils.NewPBCError(utils.RECORD_EXISTS)},
+		},
+	}
+
+	for _, tt := range testCases {
+		mockMetrics := metricstest.CreateMockMetrics()
+		cassandraBackend := &CassandraBackend{
+			client:    tt.cassandraClient,
+			writeMode: tt.writeMode,
+			metrics:   &metrics.Metrics{MetricEngines: []metrics.CacheMetrics{&mockMetrics}},
+		}
+
+		// Run test
+		errs := cassandraBackend.PutBatch(context.Background(), items)
+
+		// Assertions
+		assert.Equal(t, tt.expectedErrors, errs, tt.desc)
+		if client, isGoodClient := tt.cassandraClient.(*GoodCassandraClient); isGoodClient {
+			assert.Equal(t, tt.expectedBatches, client.Batches, tt.desc)
+			assert.Equal(t, tt.expectedStored, client.StoredData, tt.desc)
+		}
+	}
+}
+
+func TestNewCassandraCluster(t *testing.T) {
+	type testExpectedValues struct {
+		consistency       gocql.Consistency
+		authenticator     gocql.Authenticator
+		tls               bool
+		dcAwareHostPolicy bool
+		connectTimeout    time.Duration
+		timeout           time.Duration
+		retryPolicy       gocql.RetryPolicy
+		err               string
+	}
+
+	testCases := []struct {
+		desc     string
+		inCfg    config.Cassandra
+		expected testExpectedValues
+	}{
+		{
+			desc: "Only the required options are set, gocql defaults are kept",
+			inCfg: config.Cassandra{
+				Hosts:            "127.0.0.1",
+				Keyspace:         "prebid",
+				WriteConsistency: "local_one",
+			},
+			expected: testExpectedValues{
+				consistency:    gocql.LocalOne,
+				connectTimeout: 600 * time.Millisecond,
+				timeout:        600 * time.Millisecond,
+			},
+		},
+		{
+			desc: "Every option is set",
+			inCfg: config.Cassandra{
+				Hosts:            "127.0.0.1",
+				Keyspace:         "prebid",
+				Username:         "cache",
+				Password:         "secret",
+				WriteConsistency: "LOCAL_QUORUM",
+				TLS: config.CassandraTLS{
+					Enabled:  true,
+					CAFile:   "../config/configtest/tls/ca.pem",
+					CertFile: "../config/configtest/tls/client.pem",
+					KeyFile:  "../config/configtest/tls/client-key.pem",
+				},
+				LocalDC:              "us-east",
+				ConnectTimeoutMillis: 2000,
+				TimeoutMillis:        300,
+				Retry: config.CassandraRetry{
+					MaxRetries:       3,
+					MinBackoffMillis: 50,
+					MaxBackoffMillis: 500,
+				},
+			},
+			expected: testExpectedValues{
+				consistency:       gocql.LocalQuorum,
+				authenticator:     gocql.PasswordAuthenticator{Username: "cache", Password: "secret"},
+				tls:               true,
+				dcAwareHostPolicy: true,
+				connectTimeout:    2 * time.Second,
+				timeout:           300 * time.Millisecond,
+				retryPolicy:       &gocql.ExponentialBackoffRetryPolicy{NumRetries: 3, Min: 50 * time.Millisecond, Max: 500 * time.Millisecond},
+			},
+		},
+		{
+			desc:     "Unknown write consistency",
+			inCfg:    config.Cassandra{WriteConsistency: "most"},
+			expected: testExpectedValues{err: `invalid consistency "MOST"`},
+		},
+		{
+			desc: "TLS enabled with an unreadable CA bundle",
+			inCfg: config.Cassandra{
+				WriteConsistency: "one",
+				TLS:              config.CassandraTLS{Enabled: true, CAFile: "../config/configtest/tls/missing.pem"},
+			},
+			expected: testExpectedValues{err: "invalid config.backend.cassandra.tls.ca_file: open ../config/configtest/tls/missing.pem: no such file or directory"},
+		},
+	}
+
+	for _, tc := range testCases {
+		cluster, err := newCassandraCluster(tc.inCfg)
+		if len(tc.expected.err) > 0 {
+			assert.EqualError(t, err, tc.expected.err, tc.desc)
+			assert.Nil(t, cluster, tc.desc)
+			continue
+		}
+		if !assert.NoError(t, err, tc.desc) {
+			continue
+		}
+
+		assert.Equal(t, []string{tc.inCfg.Hosts}, cluster.Hosts, tc.desc)
+		assert.Equal(t, tc.inCfg.Keyspace, cluster.Keyspace, tc.desc)
+		assert.Equal(t, tc.expected.consistency, cluster.Consistency, tc.desc)
+		assert.Equal(t, tc.expected.authenticator, cluster.Authenticator, tc.desc)
+		assert.Equal(t, tc.expected.tls, cluster.SslOpts != nil, tc.desc)
+		if cluster.SslOpts != nil {
+			assert.True(t, cluster.SslOpts.EnableHostVerification, tc.desc)
+			assert.NotNil(t, cluster.SslOpts.Config.RootCAs, tc.desc)
+			assert.Len(t, cluster.SslOpts.Config.Certificates, 1, tc.desc)
+		}
+		assert.Equal(t, tc.expected.dcAwareHostPolicy, cluster.PoolConfig.HostSelectionPolicy != nil, tc.desc)
+		assert.Equal(t, tc.expected.connectTimeout, cluster.ConnectTimeout, tc.desc)
+		assert.Equal(t, tc.expected.timeout, cluster.Timeout, tc.desc)
+		assert.Equal(t, tc.expected.retryPolicy, cluster.RetryPolicy, tc.desc)
+	}
+}
@@ -14,13 +14,21 @@ import (
 )
 
 func NewBackend(cfg config.Configuration, appMetrics *metrics.Metrics) backends.Backend {
-	backend := newBaseBackend(cfg.Backend, appMetrics)
+	backend := newBaseBackend(cfg.Backend, time.Duration(cfg.Timeouts.BackendConnectMs)*time.Millisecond, appMetrics)
 	backend = DecorateBackend(cfg, appMetrics, backend)
 
 	return backend
 }
 
 func DecorateBackend(cfg config.Configuration, appMetrics *metrics.Metrics, backend backends.Backend) backends.Backend {
+	// Retries go right on top of the backend, and the circuit breaker on top of them so that it
+	// counts the requests that failed for good and an open circuit doesn't get retried
+	if cfg.Retry.Enabled {
+		backend = decorators.Retry(backend, cfg.Retry, appMetrics)
+	}
+	if cfg.CircuitBreaker.Enabled {
+		backend = decorators.CircuitBreaker(backend, cfg.CircuitBreaker, appMetrics)
+	}
 	backend = applyCompression(cfg.Compression, backend)
 	if cfg.RequestLimits.MaxSize > 0 {
 		backend = decorators.EnforceSizeLimit(backend, cfg.RequestLimits.MaxSize)
@@ -29,6 +37,15 @@ func DecorateBackend(cfg config.Configuration, appMetrics *metrics.Metrics, back
 	// "json" or "xml" prefix on the payload. Compression might munge this.
 	// We should re-work this strategy at some point.
 	backend = decorators.LogMetrics(backend, appMetrics)
+	// Gets are coalesced on top of the metrics so that those only count the gets that reached
+	// the backend
+	if cfg.Coalescing.Enabled {
+		backend = decorators.CoalesceGets(backend, appMetrics)
+	}
+	// Known misses don't get coalesced either
+	if cfg.NegativeCache.Enabled {
+		backend = decorators.NegativeCache(backend, cfg.NegativeCache, getMaxTTLSeconds(cfg), appMetrics)
+	}
 	backend = decorators.LimitTTLs(backend, getMaxTTLSeconds(cfg))
 
 	return backend
@@ -47,21 +64,40 @@ func applyCompression(cfg config.Compression, backend backends.Backend) backends
 	panic("Error applying compression. This shouldn't happen.")
 }
 
-func newBaseBackend(cfg config.Backend, appMetrics *metrics.Metrics) backends.Backend {
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+// newBaseBackend builds the backend cfg.Type names. Backends that connect or check their
+// connection when built are given connectTimeout to do so
+func newBaseBackend(cfg config.Backend, connectTimeout time.Duration, appMetrics *metrics.Metrics) backends.Backend {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
 	defer cancel()
 
 	switch cfg.Type {
 	case config.BackendCassandra:
-		return backends.NewCassandraBackend(cfg.Cassandra)
+		return backends.NewCassandraBackend(cfg.Cassandra, appMetrics)
 	case config.BackendMemory:
-		return backends.NewMemoryBackend()
+		return backends.NewMemoryBackend(cfg.Memory)
+	case config.BackendFile:
+		return backends.NewFileBackend(cfg.File)
+	case config.BackendSQL:
+		return backends.NewSQLBackend(cfg.SQL, ctx)
 	case config.BackendMemcache:
-		return backends.NewMemcacheBackend(cfg.Memcache)
+		return backends.NewMemcacheBackend(cfg.Memcache, appMetrics)
 	case config.BackendAerospike:
 		return backends.NewAerospikeBackend(cfg.Aerospike, appMetrics)
 	case config.BackendRedis:
 		return backends.NewRedisBackend(cfg.Redis, ctx)
+	case config.BackendTiered:
+		l2 := newBaseBackend(*cfg.Tiered.L2.Backend, connectTimeout, appMetrics)
+		return backends.NewTieredBackend(cfg.Tiered, l2, appMetrics)
+	case config.BackendMigration:
+		primary := newBaseBackend(*cfg.Migration.Primary.Backend, connectTimeout, appMetrics)
+		secondary := newBaseBackend(*cfg.Migration.Secondary.Backend, connectTimeout, appMetrics)
+		return backends.NewMigrationBackend(cfg.Migration, primary, secondary, appMetrics)
+	case config.BackendSharded:
+		shards := make([]backends.Backend, len(cfg.Sharded.Shards))
+		for i, shard := range cfg.Sharded.Shards {
+			shards[i] = newBaseBackend(shard.Backend, connectTimeout, appMetrics)
+		}
+		return backends.NewShardedBackend(cfg.Sharded, shards, appMetrics)
 	default:
 		logger.Fatal("Unknown backend type: %s", cfg.Type)
 	}
@@ -78,26 +114,32 @@ func newBaseBackend(cfg config.Backend, appMetrics *metrics.Metrics) backends.Ba
 // Notice that both config.backend.aerospike.default_ttl_seconds and backend.redis.expiration
 // are getting deprecated in favor of config.request_limits.max_ttl_seconds
 func getMaxTTLSeconds(cfg config.Configuration) int {
-	maxTTLSeconds := cfg.RequestLimits.MaxTTLSeconds
+	return limitTTLSeconds(cfg.Backend, cfg.RequestLimits.MaxTTLSeconds)
+}
 
-	switch cfg.Backend.Type {
-	case config.BackendCassandra:
-		// If config.request_limits.max_ttl_seconds was defined to be less than 2400 seconds, go
-		// with 2400 as it has been the TTL limit hardcoded in the Cassandra backend so far.
-		if maxTTLSeconds > utils.CASSANDRA_DEFAULT_TTL_SECONDS {
-			maxTTLSeconds = utils.CASSANDRA_DEFAULT_TTL_SECONDS
-		}
-	case config.BackendAerospike:
-		// If both config.request_limits.max_ttl_seconds and config.backend.aerospike.default_ttl_seconds
-		// were defined, the smallest value takes preference
-		if cfg.Backend.Aerospike.DefaultTTLSecs > 0 && maxTTLSeconds > cfg.Backend.Aerospike.DefaultTTLSecs {
-			maxTTLSeconds = cfg.Backend.Aerospike.DefaultTTLSecs
-		}
-	case config.BackendRedis:
-		// If both config.request_limits.max_ttl_seconds and backend.redis.expiration
-		// were defined, the smallest value takes preference
-		if cfg.Backend.Redis.ExpirationMinutes > 0 && maxTTLSeconds > cfg.Backend.Redis.ExpirationMinutes*60 {
-			maxTTLSeconds = cfg.Backend.Redis.ExpirationMinutes * 60
+// limitTTLSeconds lowers maxTTLSeconds to the TTL limits of the backends cfg stores values in.
+// Composite backends store values in several backends, so the limits of all of them apply
+func limitTTLSeconds(cfg config.Backend, maxTTLSeconds int) int {
+	for _, backend := range cfg.StorageBackends() {
+		switch backend.Type {
+		case config.BackendCassandra:
+			// If config.request_limits.max_ttl_seconds was defined to be less than 2400 seconds, go
+			// with 2400 as it has been the TTL limit hardcoded in the Cassandra backend so far.
+			if maxTTLSeconds > utils.CASSANDRA_DEFAULT_TTL_SECONDS {
+				maxTTLSeconds = utils.CASSANDRA_DEFAULT_TTL_SECONDS
+			}
+		case config.BackendAerospike:
+			// If both config.request_limits.max_ttl_seconds and config.backend.aerospike.default_ttl_seconds
+			// were defined, the smallest value takes preference
+			if backend.Aerospike.DefaultTTLSecs > 0 && maxTTLSeconds > backend.Aerospike.DefaultTTLSecs {
+				maxTTLSeconds = backend.Aerospike.DefaultTTLSecs
+			}
+		case config.BackendRedis:
+			// If both config.request_limits.max_ttl_seconds and backend.redis.expiration
+			// were defined, the smallest value takes preference
+			if backend.Redis.ExpirationMinutes > 0 && maxTTLSeconds > backend.Redis.ExpirationMinutes*60 {
+				maxTTLSeconds = backend.Redis.ExpirationMinutes * 60
+			}
 		}
 	}
 	return maxTTLSeconds
@@ -3,6 +3,7 @@ package config
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-cache/backends"
 	"github.com/prebid/prebid-cache/compression"
@@ -57,7 +58,7 @@ func TestNewMemoryOrMemcacheBackend(t *testing.T) {
 		{
 			desc:            "Memory",
 			inConfig:        config.Backend{Type: config.BackendMemory},
-			expectedBackend: backends.NewMemoryBackend(),
+			expectedBackend: backends.NewMemoryBackend(config.Memory{}),
 		},
 		{
 			desc:            "Memcache",
@@ -75,7 +76,7 @@ func TestNewMemoryOrMemcacheBackend(t *testing.T) {
 		}
 
 		// run
-		actualBackend := newBaseBackend(tc.inConfig, m)
+		actualBackend := newBaseBackend(tc.inConfig, 500*time.Millisecond, m)
 
 		// assertions
 		assert.IsType(t, tc.expectedBackend, actualBackend, tc.desc)
@@ -223,6 +224,98 @@ func TestGetMaxTTLSeconds(t *testing.T) {
 				},
 			},
 		},
+		{
+			groupDesc: "Tiered backend",
+			unitTests: []testCases{
+				{
+					desc: "L2 backend TTL limits apply",
+					inConfig: config.Configuration{
+						Backend: config.Backend{
+							Type: config.BackendTiered,
+							Tiered: config.Tiered{
+								L2: config.TieredL2{
+									Backend: &config.Backend{
+										Type:  config.BackendRedis,
+										Redis: config.Redis{ExpirationMinutes: 1},
+									},
+								},
+							},
+						},
+						RequestLimits: config.RequestLimits{
+							MaxTTLSeconds: utils.REQUEST_MAX_TTL_SECONDS,
+						},
+					},
+					expectedMaxTTLSeconds: SIXTY_SECONDS,
+				},
+			},
+		},
+		{
+			groupDesc: "Migration backend",
+			unitTests: []testCases{
+				{
+					desc: "Smallest TTL limit of both backends applies",
+					inConfig: config.Configuration{
+						Backend: config.Backend{
+							Type: config.BackendMigration,
+							Migration: config.Migration{
+								Primary: config.MigrationSide{
+									Backend: &config.Backend{
+										Type:      config.BackendAerospike,
+										Aerospike: config.Aerospike{DefaultTTLSecs: 2000},
+									},
+								},
+								Secondary: config.MigrationSide{
+									Backend: &config.Backend{Type: config.BackendCassandra},
+								},
+							},
+						},
+						RequestLimits: config.RequestLimits{
+							MaxTTLSeconds: 5000,
+						},
+					},
+					expectedMaxTTLSeconds: 2000,
+				},
+			},
+		},
+		{
+			groupDesc: "Sharded backend",
+			unitTests: []testCases{
+				{
+					desc: "Smallest TTL limit of every shard applies",
+					inConfig: config.Configuration{
+						Backend: config.Backend{
+							Type: config.BackendSharded,
+							Sharded: config.Sharded{
+								Shards: []config.Shard{
+									{
+										Name: "aerospike",
+										Backend: config.Backend{
+											Type:      config.BackendAerospike,
+											Aerospike: config.Aerospike{DefaultTTLSecs: 3600},
+										},
+									},
+									{
+										Name: "redis",
+										Backend: config.Backend{
+											Type:  config.BackendRedis,
+											Redis: config.Redis{ExpirationMinutes: 30},
+										},
+									},
+									{
+										Name:    "memcache",
+										Backend: config.Backend{Type: config.BackendMemcache},
+									},
+								},
+							},
+						},
+						RequestLimits: config.RequestLimits{
+							MaxTTLSeconds: 5000,
+						},
+					},
+					expectedMaxTTLSeconds: 1800,
+				},
+			},
+		},
 	}
 
 	for _, tgroup := range tests {
@@ -241,3 +334,7 @@ func (c *fakeBackend) Put(ctx context.Context, key string, value string, ttlSeco
 func (c *fakeBackend) Get(ctx context.Context, key string) (string, error) {
 	return "", nil
 }
+
+func (c *fakeBackend) Delete(ctx context.Context, key string) error {
+	return nil
+}
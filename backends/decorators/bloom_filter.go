@@ -0,0 +1,130 @@
+package decorators
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// bloomFilter tells whether a key may have been added to it, with no false negatives and a
+// rate of false positives that depends on how many keys were added. Keys can be added and
+// tested concurrently
+type bloomFilter struct {
+	bits   []atomic.Uint64
+	hashes uint64
+}
+
+// newBloomFilter sizes the filter for falsePositiveRate once expectedKeys keys were added
+func newBloomFilter(expectedKeys int, falsePositiveRate float64) *bloomFilter {
+	bits := math.Ceil(-float64(expectedKeys) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	hashes := math.Max(1, math.Round(bits/float64(expectedKeys)*math.Ln2))
+	return &bloomFilter{
+		bits:   make([]atomic.Uint64, int(bits+63)/64),
+		hashes: uint64(hashes),
+	}
+}
+
+func (f *bloomFilter) add(key string) {
+	h1, h2 := bloomHashes(key)
+	size := uint64(len(f.bits)) * 64
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % size
+		f.bits[bit/64].Or(1 << (bit % 64))
+	}
+}
+
+func (f *bloomFilter) mayContain(key string) bool {
+	h1, h2 := bloomHashes(key)
+	size := uint64(len(f.bits)) * 64
+	for i := uint64(0); i < f.hashes; i++ {
+		bit := (h1 + i*h2) % size
+		if f.bits[bit/64].Load()&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reset removes every key. It must not run concurrently with add or mayContain
+func (f *bloomFilter) reset() {
+	for i := range f.bits {
+		f.bits[i].Store(0)
+	}
+}
+
+// bloomHashes splits the 64-bit FNV-1a hash of key into the two hashes every bit position of
+// key is derived from
+func bloomHashes(key string) (uint64, uint64) {
+	var hash uint64 = 14695981039346656037
+	for i := 0; i < len(key); i++ {
+		hash ^= uint64(key[i])
+		hash *= 1099511628211
+	}
+	// An odd second hash keeps the positions of a key from repeating
+	return hash & math.MaxUint32, hash>>32 | 1
+}
+
+// rotatingBloomFilter keeps track of the keys added over the last period at least. Keys go to
+// the current filter, which becomes the previous one once period passed, so a key is found
+// in either of them for no less than period after it was added.
+type rotatingBloomFilter struct {
+	period time.Duration
+	now    func() time.Time
+
+	mu        sync.RWMutex
+	current   *bloomFilter
+	previous  *bloomFilter
+	started   time.Time
+	rotatedAt time.Time
+}
+
+func newRotatingBloomFilter(expectedKeys int, falsePositiveRate float64, period time.Duration, now func() time.Time) *rotatingBloomFilter {
+	started := now()
+	return &rotatingBloomFilter{
+		period:    period,
+		now:       now,
+		current:   newBloomFilter(expectedKeys, falsePositiveRate),
+		previous:  newBloomFilter(expectedKeys, falsePositiveRate),
+		started:   started,
+		rotatedAt: started,
+	}
+}
+
+func (f *rotatingBloomFilter) add(key string) {
+	f.rotate()
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	f.current.add(key)
+}
+
+// knownMissing tells whether key was surely not added over the last period. Nothing is known
+// until the filter has been up for a whole period, since keys added before it started are missing
+func (f *rotatingBloomFilter) knownMissing(key string) bool {
+	f.rotate()
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if f.now().Sub(f.started) < f.period {
+		return false
+	}
+	return !f.current.mayContain(key) && !f.previous.mayContain(key)
+}
+
+func (f *rotatingBloomFilter) rotate() {
+	f.mu.RLock()
+	due := f.now().Sub(f.rotatedAt) >= f.period
+	f.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	now := f.now()
+	if now.Sub(f.rotatedAt) < f.period {
+		return
+	}
+	f.previous, f.current = f.current, f.previous
+	f.current.reset()
+	f.rotatedAt = now
+}
@@ -0,0 +1,47 @@
+package decorators
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBloomFilter(t *testing.T) {
+	const keys = 10000
+	filter := newBloomFilter(keys, 0.01)
+
+	for i := 0; i < keys; i++ {
+		filter.add(fmt.Sprintf("added-%d", i))
+	}
+	for i := 0; i < keys; i++ {
+		assert.True(t, filter.mayContain(fmt.Sprintf("added-%d", i)), "No false negatives")
+	}
+
+	falsePositives := 0
+	for i := 0; i < keys; i++ {
+		if filter.mayContain(fmt.Sprintf("other-%d", i)) {
+			falsePositives++
+		}
+	}
+	assert.InDelta(t, 100, falsePositives, 50, "About 1% of false positives")
+
+	filter.reset()
+	assert.False(t, filter.mayContain("added-0"))
+}
+
+func TestRotatingBloomFilter(t *testing.T) {
+	now := time.Now()
+	filter := newRotatingBloomFilter(100, 0.01, time.Hour, func() time.Time { return now })
+
+	filter.add("key")
+	assert.False(t, filter.knownMissing("other"), "Nothing is known during the first period")
+
+	now = now.Add(time.Hour)
+	assert.False(t, filter.knownMissing("key"), "Keys added over the last period are kept")
+	assert.True(t, filter.knownMissing("other"))
+
+	now = now.Add(time.Hour)
+	assert.True(t, filter.knownMissing("key"), "Keys added before the last two periods are forgotten")
+}
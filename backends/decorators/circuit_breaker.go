@@ -0,0 +1,256 @@
+package decorators
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"git.pubmatic.com/PubMatic/go-common/logger"
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// CircuitBreaker wraps the delegate so that gets, puts and deletes fail fast with a
+// BACKEND_UNAVAILABLE error once too many of them fail or time out, instead of waiting on a
+// backend that is struggling. After cfg.OpenSeconds a few probe requests are let through, and
+// the circuit closes again if all of them succeed.
+func CircuitBreaker(delegate backends.Backend, cfg config.CircuitBreaker, m *metrics.Metrics) backends.Backend {
+	return &circuitBreaker{
+		delegate: delegate,
+		get:      newCircuit("get", cfg, m, time.Now),
+		put:      newCircuit("put", cfg, m, time.Now),
+		delete:   newCircuit("delete", cfg, m, time.Now),
+	}
+}
+
+// circuitBreaker implements the backends.Backend interface to serve as a decorator that keeps
+// a circuit per operation
+type circuitBreaker struct {
+	delegate backends.Backend
+	get      *circuit
+	put      *circuit
+	delete   *circuit
+}
+
+func (b *circuitBreaker) Get(ctx context.Context, key string) (string, error) {
+	generation, allowed := b.get.allow()
+	if !allowed {
+		return "", utils.NewPBCError(utils.BACKEND_UNAVAILABLE)
+	}
+	value, err := b.delegate.Get(ctx, key)
+	b.get.done(generation, circuitOutcomeOf(err))
+	return value, err
+}
+
+// GetBatch counts the batch as a single request, which fails if any of its keys does
+func (b *circuitBreaker) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	generation, allowed := b.get.allow()
+	if !allowed {
+		return make([]string, len(keys)), unavailableErrors(len(keys))
+	}
+	values, errs := backends.GetBatch(ctx, b.delegate, keys)
+	b.get.done(generation, batchCircuitOutcome(errs))
+	return values, errs
+}
+
+func (b *circuitBreaker) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	generation, allowed := b.put.allow()
+	if !allowed {
+		return utils.NewPBCError(utils.BACKEND_UNAVAILABLE)
+	}
+	err := b.delegate.Put(ctx, key, value, ttlSeconds)
+	b.put.done(generation, circuitOutcomeOf(err))
+	return err
+}
+
+// PutBatch counts the batch as a single request, which fails if any of its items does
+func (b *circuitBreaker) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+	generation, allowed := b.put.allow()
+	if !allowed {
+		return unavailableErrors(len(items))
+	}
+	errs := backends.PutBatch(ctx, b.delegate, items)
+	b.put.done(generation, batchCircuitOutcome(errs))
+	return errs
+}
+
+func (b *circuitBreaker) Delete(ctx context.Context, key string) error {
+	generation, allowed := b.delete.allow()
+	if !allowed {
+		return utils.NewPBCError(utils.BACKEND_UNAVAILABLE)
+	}
+	err := b.delegate.Delete(ctx, key)
+	b.delete.done(generation, circuitOutcomeOf(err))
+	return err
+}
+
+// HealthCheck reaches the delegate even while circuits are open, since it's how readiness
+// probes find out whether the backend recovered
+func (b *circuitBreaker) HealthCheck(ctx context.Context) error {
+	return backends.HealthCheck(ctx, b.delegate)
+}
+
+func unavailableErrors(n int) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = utils.NewPBCError(utils.BACKEND_UNAVAILABLE)
+	}
+	return errs
+}
+
+type circuitOutcome int
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+	// Requests the caller gave up on tell nothing about the health of the backend
+	circuitIgnored
+)
+
+// circuitOutcomeOf tells whether err means the backend is failing. Prebid Cache errors, such as
+// a missing key or a key that's already taken, are answers of a healthy backend unless they
+// report a timeout
+func circuitOutcomeOf(err error) circuitOutcome {
+	if err == nil {
+		return circuitSuccess
+	}
+	if errors.Is(err, context.Canceled) {
+		return circuitIgnored
+	}
+	if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
+		switch pbcErr.Type {
+		case utils.GET_DEADLINE_EXCEEDED, utils.PUT_DEADLINE_EXCEEDED:
+			return circuitFailure
+		default:
+			return circuitSuccess
+		}
+	}
+	return circuitFailure
+}
+
+func batchCircuitOutcome(errs []error) circuitOutcome {
+	outcome := circuitSuccess
+	for _, err := range errs {
+		switch circuitOutcomeOf(err) {
+		case circuitFailure:
+			return circuitFailure
+		case circuitIgnored:
+			outcome = circuitIgnored
+		}
+	}
+	return outcome
+}
+
+// circuit tracks the requests of a single operation. While closed, requests are counted over
+// fixed windows and the circuit opens as soon as the failure rate of the current window reaches
+// the threshold. Every state change starts a new generation so that requests let through in
+// an earlier one don't count towards the current state once they complete.
+type circuit struct {
+	operation string
+	cfg       config.CircuitBreaker
+	metrics   *metrics.Metrics
+	now       func() time.Time
+
+	mu         sync.Mutex
+	state      config.CircuitState
+	generation uint64
+	// Start of the window of a closed circuit, or time an open circuit opened at
+	since    time.Time
+	requests int
+	failures int
+	// Probes a half-open circuit let through and the ones that succeeded so far
+	probes    int
+	successes int
+}
+
+func newCircuit(operation string, cfg config.CircuitBreaker, m *metrics.Metrics, now func() time.Time) *circuit {
+	return &circuit{
+		operation: operation,
+		cfg:       cfg,
+		metrics:   m,
+		now:       now,
+		state:     config.CircuitClosed,
+		since:     now(),
+	}
+}
+
+// allow tells whether a request can go through and, if so, returns the generation its outcome
+// must be reported for
+func (c *circuit) allow() (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	switch c.state {
+	case config.CircuitClosed:
+		if now.Sub(c.since) >= time.Duration(c.cfg.WindowSeconds)*time.Second {
+			c.since = now
+			c.requests, c.failures = 0, 0
+		}
+		return c.generation, true
+	case config.CircuitOpen:
+		if now.Sub(c.since) < time.Duration(c.cfg.OpenSeconds)*time.Second {
+			return 0, false
+		}
+		c.setState(config.CircuitHalfOpen, now)
+	}
+
+	if c.probes >= c.cfg.HalfOpenRequests {
+		return 0, false
+	}
+	c.probes++
+	return c.generation, true
+}
+
+// done reports the outcome of a request allow let through in generation
+func (c *circuit) done(generation uint64, outcome circuitOutcome) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+
+	switch c.state {
+	case config.CircuitClosed:
+		if outcome == circuitIgnored {
+			return
+		}
+		c.requests++
+		if outcome == circuitFailure {
+			c.failures++
+		}
+		if c.requests >= c.cfg.MinRequests && c.failures*100 >= c.cfg.FailureRatePercent*c.requests {
+			logger.Warn("Opening the %s circuit: %d of the last %d requests failed", c.operation, c.failures, c.requests)
+			c.setState(config.CircuitOpen, c.now())
+		}
+	case config.CircuitHalfOpen:
+		switch outcome {
+		case circuitIgnored:
+			// Let another probe through in its place
+			c.probes--
+		case circuitFailure:
+			logger.Warn("Opening the %s circuit again: a probe request failed", c.operation)
+			c.setState(config.CircuitOpen, c.now())
+		case circuitSuccess:
+			c.successes++
+			if c.successes >= c.cfg.HalfOpenRequests {
+				logger.Info("Closing the %s circuit: %d probe requests succeeded", c.operation, c.successes)
+				c.setState(config.CircuitClosed, c.now())
+			}
+		}
+	}
+}
+
+// setState moves the circuit into state, starting a new generation. Callers must hold c.mu
+func (c *circuit) setState(state config.CircuitState, now time.Time) {
+	c.state = state
+	c.generation++
+	c.since = now
+	c.requests, c.failures = 0, 0
+	c.probes, c.successes = 0, 0
+	c.metrics.RecordCircuitBreakerTransition(c.operation, state)
+}
@@ -0,0 +1,188 @@
+package decorators
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCircuitBreaker wraps delegate in a circuit breaker that opens once half of the last
+// four or more requests fail, stays open for 5 seconds and closes after 2 successful probes.
+// Its clock is set to whatever now points to
+func newTestCircuitBreaker(delegate backends.Backend, now *time.Time) (*circuitBreaker, *metricstest.MockMetrics) {
+	cfg := config.CircuitBreaker{
+		Enabled:            true,
+		FailureRatePercent: 50,
+		MinRequests:        4,
+		WindowSeconds:      10,
+		OpenSeconds:        5,
+		HalfOpenRequests:   2,
+	}
+	clock := func() time.Time { return *now }
+
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	}
+	return &circuitBreaker{
+		delegate: delegate,
+		get:      newCircuit("get", cfg, m, clock),
+		put:      newCircuit("put", cfg, m, clock),
+		delete:   newCircuit("delete", cfg, m, clock),
+	}, &mockMetrics
+}
+
+func TestCircuitBreakerOpens(t *testing.T) {
+	now := time.Now()
+	backendErr := errors.New("connection refused")
+	breaker, mockMetrics := newTestCircuitBreaker(&failedBackend{returnError: backendErr}, &now)
+	ctx := context.Background()
+
+	for i := 0; i < 4; i++ {
+		assert.Equal(t, backendErr, breaker.Put(ctx, "key", "value", 0), "Put %d reaches the backend", i)
+	}
+	assert.Equal(t, config.CircuitOpen, breaker.put.state, "Circuit opens once the failure rate reaches the threshold")
+	mockMetrics.AssertNumberOfCalls(t, "RecordCircuitBreakerTransition", 1)
+
+	assert.Equal(t, utils.NewPBCError(utils.BACKEND_UNAVAILABLE), breaker.Put(ctx, "key", "value", 0), "Open circuit fails fast")
+	assert.Equal(t, []error{utils.NewPBCError(utils.BACKEND_UNAVAILABLE), utils.NewPBCError(utils.BACKEND_UNAVAILABLE)},
+		breaker.PutBatch(ctx, []backends.BatchPutItem{{Key: "a"}, {Key: "b"}}), "Open circuit fails batches fast")
+	assert.Equal(t, backendErr, breaker.Delete(ctx, "key"), "Other operations have circuits of their own")
+}
+
+func TestCircuitBreakerWindow(t *testing.T) {
+	now := time.Now()
+	breaker, _ := newTestCircuitBreaker(&failedBackend{returnError: errors.New("connection refused")}, &now)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		breaker.Get(ctx, "key")
+	}
+	now = now.Add(10 * time.Second)
+	breaker.Get(ctx, "key")
+
+	assert.Equal(t, config.CircuitClosed, breaker.get.state, "Failures of earlier windows aren't counted")
+}
+
+func TestCircuitBreakerRecovery(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		inProbeErr    error
+		expectedState config.CircuitState
+	}{
+		{
+			desc:          "Circuit closes once every probe succeeds",
+			expectedState: config.CircuitClosed,
+		},
+		{
+			desc:          "Circuit opens again if a probe fails",
+			inProbeErr:    errors.New("connection refused"),
+			expectedState: config.CircuitOpen,
+		},
+	}
+
+	for _, test := range testCases {
+		now := time.Now()
+		delegate := &failedBackend{returnError: errors.New("connection refused")}
+		breaker, mockMetrics := newTestCircuitBreaker(delegate, &now)
+		ctx := context.Background()
+		for i := 0; i < 4; i++ {
+			breaker.Get(ctx, "key")
+		}
+
+		now = now.Add(4 * time.Second)
+		_, err := breaker.Get(ctx, "key")
+		assert.Equal(t, utils.NewPBCError(utils.BACKEND_UNAVAILABLE), err, "%s: circuit stays open for open_seconds", test.desc)
+
+		now = now.Add(time.Second)
+		delegate.returnError = test.inProbeErr
+		breaker.Get(ctx, "key")
+		breaker.Get(ctx, "key")
+
+		assert.Equal(t, test.expectedState, breaker.get.state, test.desc)
+		mockMetrics.AssertNumberOfCalls(t, "RecordCircuitBreakerTransition", 3)
+	}
+}
+
+func TestCircuitHalfOpenProbes(t *testing.T) {
+	now := time.Now()
+	breaker, _ := newTestCircuitBreaker(&failedBackend{}, &now)
+	c := breaker.put
+	c.setState(config.CircuitOpen, now)
+	now = now.Add(5 * time.Second)
+
+	first, allowed := c.allow()
+	assert.True(t, allowed, "First probe")
+	_, allowed = c.allow()
+	assert.True(t, allowed, "Second probe")
+	_, allowed = c.allow()
+	assert.False(t, allowed, "No more than half_open_requests probes are in flight")
+
+	c.done(first, circuitIgnored)
+	_, allowed = c.allow()
+	assert.True(t, allowed, "Probe the caller gave up on is replaced")
+
+	c.done(first-1, circuitFailure)
+	assert.Equal(t, config.CircuitHalfOpen, c.state, "Requests let through before the circuit half-opened aren't probes")
+}
+
+func TestCircuitOutcome(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		inErr    error
+		expected circuitOutcome
+	}{
+		{
+			desc:     "Success",
+			expected: circuitSuccess,
+		},
+		{
+			desc:     "Backend error",
+			inErr:    errors.New("connection refused"),
+			expected: circuitFailure,
+		},
+		{
+			desc:     "Timeout reported by the backend",
+			inErr:    utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+			expected: circuitFailure,
+		},
+		{
+			desc:     "Request deadline exceeded",
+			inErr:    fmt.Errorf("read: %w", context.DeadlineExceeded),
+			expected: circuitFailure,
+		},
+		{
+			desc:     "Key not found",
+			inErr:    utils.NewPBCError(utils.KEY_NOT_FOUND),
+			expected: circuitSuccess,
+		},
+		{
+			desc:     "Record exists",
+			inErr:    utils.NewPBCError(utils.RECORD_EXISTS),
+			expected: circuitSuccess,
+		},
+		{
+			desc:     "Request canceled by the caller",
+			inErr:    context.Canceled,
+			expected: circuitIgnored,
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expected, circuitOutcomeOf(test.inErr), test.desc)
+	}
+
+	assert.Equal(t, circuitFailure, batchCircuitOutcome([]error{nil, context.Canceled, errors.New("connection refused")}), "Batch with a failed item")
+	assert.Equal(t, circuitSuccess, batchCircuitOutcome([]error{nil, utils.NewPBCError(utils.KEY_NOT_FOUND)}), "Batch without failed items")
+}
@@ -0,0 +1,140 @@
+package decorators
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// CoalesceGets wraps the delegate so that gets of a key that another request is already getting
+// from the backend wait for that get and share its result instead of sending one of their own.
+// Every caller stops waiting when its own context is done, and the backend get is only canceled
+// once none of them is waiting anymore. It runs with the deadline of the request that sent it.
+// Batches, puts and deletes go straight to the delegate.
+func CoalesceGets(delegate backends.Backend, m *metrics.Metrics) backends.Backend {
+	return &coalescingBackend{
+		delegate: delegate,
+		metrics:  m,
+		inFlight: make(map[string]*inFlightGet),
+	}
+}
+
+// coalescingBackend implements the backends.Backend interface to serve as a decorator that
+// keeps track of the gets in flight by key
+type coalescingBackend struct {
+	delegate backends.Backend
+	metrics  *metrics.Metrics
+
+	mu       sync.Mutex
+	inFlight map[string]*inFlightGet
+}
+
+// inFlightGet is a backend get shared by every caller that asked for its key while it was in
+// flight. value and err are set before done is closed
+type inFlightGet struct {
+	done   chan struct{}
+	value  string
+	err    error
+	cancel context.CancelFunc
+	// Callers still waiting for the result. Guarded by the mutex of the coalescingBackend
+	waiters int
+}
+
+func (b *coalescingBackend) Get(ctx context.Context, key string) (string, error) {
+	b.mu.Lock()
+	get, coalesced := b.inFlight[key]
+	if coalesced {
+		get.waiters++
+	} else {
+		get = b.send(ctx, key)
+	}
+	b.mu.Unlock()
+
+	if coalesced {
+		b.metrics.RecordCoalescedGet()
+	}
+
+	select {
+	case <-get.done:
+		return get.value, get.err
+	case <-ctx.Done():
+		b.leave(key, get)
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED)
+		}
+		return "", ctx.Err()
+	}
+}
+
+// send starts a backend get of key that ctx is the first caller of. Callers must hold b.mu
+func (b *coalescingBackend) send(ctx context.Context, key string) *inFlightGet {
+	// The get must outlive ctx if other callers are still waiting when it's canceled, but still
+	// carry its values and its deadline
+	sharedCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	if deadline, ok := ctx.Deadline(); ok {
+		sharedCtx, cancel = context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	}
+
+	get := &inFlightGet{
+		done:    make(chan struct{}),
+		cancel:  cancel,
+		waiters: 1,
+	}
+	b.inFlight[key] = get
+
+	go func() {
+		defer cancel()
+		value, err := b.delegate.Get(sharedCtx, key)
+
+		b.mu.Lock()
+		if b.inFlight[key] == get {
+			delete(b.inFlight, key)
+		}
+		b.mu.Unlock()
+
+		get.value, get.err = value, err
+		close(get.done)
+	}()
+	return get
+}
+
+// leave stops a caller from waiting for get, canceling it if no other caller is waiting
+func (b *coalescingBackend) leave(key string, get *inFlightGet) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	get.waiters--
+	if get.waiters > 0 {
+		return
+	}
+	get.cancel()
+	// Callers coming later must not join a canceled get
+	if b.inFlight[key] == get {
+		delete(b.inFlight, key)
+	}
+}
+
+// GetBatch isn't coalesced: batches rarely overlap and the delegate already gets their keys together
+func (b *coalescingBackend) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	return backends.GetBatch(ctx, b.delegate, keys)
+}
+
+func (b *coalescingBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	return b.delegate.Put(ctx, key, value, ttlSeconds)
+}
+
+func (b *coalescingBackend) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+	return backends.PutBatch(ctx, b.delegate, items)
+}
+
+func (b *coalescingBackend) Delete(ctx context.Context, key string) error {
+	return b.delegate.Delete(ctx, key)
+}
+
+// HealthCheck is delegated without coalescing so that every check reaches the backend
+func (b *coalescingBackend) HealthCheck(ctx context.Context) error {
+	return backends.HealthCheck(ctx, b.delegate)
+}
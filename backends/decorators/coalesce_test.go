@@ -0,0 +1,120 @@
+package decorators
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// gatedBackend holds gets until release is closed or their context is done, in which case
+// canceled gets closed
+type gatedBackend struct {
+	flakyBackend
+	release  chan struct{}
+	canceled chan struct{}
+}
+
+func newGatedBackend() *gatedBackend {
+	return &gatedBackend{release: make(chan struct{}), canceled: make(chan struct{})}
+}
+
+func (b *gatedBackend) Get(ctx context.Context, key string) (string, error) {
+	b.call(key)
+	select {
+	case <-b.release:
+		return "value", nil
+	case <-ctx.Done():
+		close(b.canceled)
+		return "", ctx.Err()
+	}
+}
+
+func newTestCoalescing(delegate *gatedBackend) (*coalescingBackend, *metricstest.MockMetrics) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	}
+	return CoalesceGets(delegate, m).(*coalescingBackend), &mockMetrics
+}
+
+// waiters returns the number of callers waiting for the get of key in flight
+func (b *coalescingBackend) waiters(key string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if get, ok := b.inFlight[key]; ok {
+		return get.waiters
+	}
+	return 0
+}
+
+func TestCoalescedGets(t *testing.T) {
+	const callers = 5
+	delegate := newGatedBackend()
+	backend, mockMetrics := newTestCoalescing(delegate)
+
+	var wg sync.WaitGroup
+	values := make([]string, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			values[i], _ = backend.Get(context.Background(), "key")
+		}(i)
+	}
+	assert.Eventually(t, func() bool { return backend.waiters("key") == callers }, time.Second, time.Millisecond)
+	close(delegate.release)
+	wg.Wait()
+
+	assert.Equal(t, []string{"value", "value", "value", "value", "value"}, values)
+	assert.Equal(t, 1, delegate.calls, "Concurrent gets of the same key share a backend get")
+	mockMetrics.AssertNumberOfCalls(t, "RecordCoalescedGet", callers-1)
+
+	backend.Get(context.Background(), "key")
+	assert.Equal(t, 2, delegate.calls, "Gets coming after the shared one completed send their own")
+}
+
+func TestCoalescedGetCancellation(t *testing.T) {
+	delegate := newGatedBackend()
+	backend, _ := newTestCoalescing(delegate)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := backend.Get(firstCtx, "key")
+		firstErr <- err
+	}()
+	assert.Eventually(t, func() bool { return backend.waiters("key") == 1 }, time.Second, time.Millisecond)
+
+	secondCtx, cancelSecond := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancelSecond()
+	secondErr := make(chan error)
+	go func() {
+		_, err := backend.Get(secondCtx, "key")
+		secondErr <- err
+	}()
+	assert.Eventually(t, func() bool { return backend.waiters("key") == 2 }, time.Second, time.Millisecond)
+
+	cancelFirst()
+	assert.Equal(t, context.Canceled, <-firstErr, "Caller that gave up stops waiting")
+	select {
+	case <-delegate.canceled:
+		t.Error("Backend get must go on while a caller is still waiting for it")
+	default:
+	}
+
+	assert.Equal(t, utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED), <-secondErr, "Caller past its deadline stops waiting")
+	select {
+	case <-delegate.canceled:
+	case <-time.After(time.Second):
+		t.Error("Backend get must be canceled once no caller is waiting for it")
+	}
+	assert.Equal(t, 0, backend.waiters("key"), "Canceled get can't be joined")
+}
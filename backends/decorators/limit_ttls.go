@@ -30,15 +30,48 @@ type ttlLimited struct {
 // Put will make the delegate.Put() call with the default l.maxTTLSeconds whenever the
 // request-defined ttl value is out of bounds
 func (l ttlLimited) Put(ctx context.Context, key string, value string, requestTTLSeconds int) error {
+	return l.Backend.Put(ctx, key, value, l.limitTTL(requestTTLSeconds))
+}
+
+// PutBatch applies the same TTL limits Put does to every item before forwarding the batch
+// to the delegate
+func (l ttlLimited) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+	limited := make([]backends.BatchPutItem, len(items))
+	for i, item := range items {
+		limited[i] = item
+		limited[i].TTLSeconds = l.limitTTL(item.TTLSeconds)
+	}
+	return backends.PutBatch(ctx, l.Backend, limited)
+}
+
+// limitTTL returns the request-defined ttl value unless it's out of bounds, in which case
+// l.maxTTLSeconds is returned
+func (l ttlLimited) limitTTL(requestTTLSeconds int) int {
 	ttl := l.maxTTLSeconds
 
 	if l.maxTTLSeconds > requestTTLSeconds && requestTTLSeconds > 0 {
 		ttl = requestTTLSeconds
 	}
-	return l.Backend.Put(ctx, key, value, ttl)
+	return ttl
 }
 
 // Get will somply make the delegate.Get() call given that no TTL check is needed on the GET side
 func (l ttlLimited) Get(ctx context.Context, key string) (string, error) {
 	return l.Backend.Get(ctx, key)
 }
+
+// Delete will simply make the delegate.Delete() call given that no TTL check is needed on the DELETE side
+func (l ttlLimited) Delete(ctx context.Context, key string) error {
+	return l.Backend.Delete(ctx, key)
+}
+
+// HealthCheck will simply make the delegate health check given that it involves no TTL
+func (l ttlLimited) HealthCheck(ctx context.Context) error {
+	return backends.HealthCheck(ctx, l.Backend)
+}
+
+// GetBatch will simply forward the batch to the delegate given that no TTL check is needed
+// on the GET side
+func (l ttlLimited) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	return backends.GetBatch(ctx, l.Backend, keys)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/prebid/prebid-cache/backends"
 	"github.com/prebid/prebid-cache/backends/decorators"
 	"github.com/prebid/prebid-cache/utils"
 	"github.com/stretchr/testify/assert"
@@ -109,6 +110,32 @@ func TestLimitTTLDecorator(t *testing.T) {
 	}
 }
 
+func TestLimitTTLDecoratorBatch(t *testing.T) {
+	delegate := &batchTTLCapturer{}
+	wrapped := decorators.LimitTTLs(delegate, 10)
+
+	backends.PutBatch(context.Background(), wrapped, []backends.BatchPutItem{
+		{Key: "negative", Value: "value", TTLSeconds: -1},
+		{Key: "zero", Value: "value", TTLSeconds: 0},
+		{Key: "withinLimit", Value: "value", TTLSeconds: 5},
+		{Key: "pastLimit", Value: "value", TTLSeconds: 50},
+	})
+
+	assert.Equal(t, []int{10, 10, 5, 10}, delegate.ttls, "Every item in the batch should get the same TTL limits Put applies")
+}
+
+type batchTTLCapturer struct {
+	ttlCapturer
+	ttls []int
+}
+
+func (c *batchTTLCapturer) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+	for _, item := range items {
+		c.ttls = append(c.ttls, item.TTLSeconds)
+	}
+	return make([]error, len(items))
+}
+
 type ttlCapturer struct {
 	lastTTL int
 }
@@ -121,3 +148,7 @@ func (c *ttlCapturer) Put(ctx context.Context, key string, value string, ttlSeco
 func (c *ttlCapturer) Get(ctx context.Context, key string) (string, error) {
 	return "", nil
 }
+
+func (c *ttlCapturer) Delete(ctx context.Context, key string) error {
+	return nil
+}
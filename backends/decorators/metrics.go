@@ -21,26 +21,75 @@ func (b *backendWithMetrics) Get(ctx context.Context, key string) (string, error
 	b.metrics.RecordGetBackendTotal()
 	start := time.Now()
 	val, err := b.delegate.Get(ctx, key)
+	b.recordGetResult(err, time.Since(start))
+	return val, err
+}
+
+// GetBatch records the size of the batch and then the same metrics Get would have
+// recorded for every one of the keys
+func (b *backendWithMetrics) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+
+	b.metrics.RecordGetBackendBatchSize(len(keys))
+	for range keys {
+		b.metrics.RecordGetBackendTotal()
+	}
+	start := time.Now()
+	values, errs := backends.GetBatch(ctx, b.delegate, keys)
+	elapsed := time.Since(start)
+	for _, err := range errs {
+		b.recordGetResult(err, elapsed)
+	}
+	return values, errs
+}
+
+func (b *backendWithMetrics) recordGetResult(err error, elapsed time.Duration) {
 	if err == nil {
-		b.metrics.RecordGetBackendDuration(time.Since(start))
-	} else {
-		if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
-			// If error Type is either KEY_NOT_FOUND or MISSING_KEY, account under the
-			// metrics below in addition of RecordGetBackendError()
-			switch pbcErr.Type {
-			case utils.KEY_NOT_FOUND:
-				b.metrics.RecordKeyNotFoundError()
-			case utils.MISSING_KEY:
-				b.metrics.RecordMissingKeyError()
-			}
+		b.metrics.RecordGetBackendDuration(elapsed)
+		return
+	}
+
+	if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
+		// If error Type is either KEY_NOT_FOUND or MISSING_KEY, account under the
+		// metrics below in addition of RecordGetBackendError()
+		switch pbcErr.Type {
+		case utils.KEY_NOT_FOUND:
+			b.metrics.RecordKeyNotFoundError()
+		case utils.MISSING_KEY:
+			b.metrics.RecordMissingKeyError()
 		}
-		b.metrics.RecordGetBackendError()
 	}
-	return val, err
+	b.metrics.RecordGetBackendError()
 }
 
 func (b *backendWithMetrics) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
 
+	b.recordPutRequest(value, ttlSeconds)
+
+	start := time.Now()
+	err := b.delegate.Put(ctx, key, value, ttlSeconds)
+	b.recordPutResult(value, err, time.Since(start))
+	return err
+}
+
+// PutBatch records the size of the batch and then the same metrics Put would have
+// recorded for every one of the items
+func (b *backendWithMetrics) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+
+	b.metrics.RecordPutBackendBatchSize(len(items))
+	for _, item := range items {
+		b.recordPutRequest(item.Value, item.TTLSeconds)
+	}
+
+	start := time.Now()
+	errs := backends.PutBatch(ctx, b.delegate, items)
+	elapsed := time.Since(start)
+	for i, item := range items {
+		b.recordPutResult(item.Value, errs[i], elapsed)
+	}
+	return errs
+}
+
+func (b *backendWithMetrics) recordPutRequest(value string, ttlSeconds int) {
 	if strings.HasPrefix(value, utils.XML_PREFIX) {
 		b.metrics.RecordPutBackendXml()
 	} else if strings.HasPrefix(value, utils.JSON_PREFIX) {
@@ -50,18 +99,38 @@ func (b *backendWithMetrics) Put(ctx context.Context, key string, value string,
 	}
 	ttl, _ := time.ParseDuration(fmt.Sprintf("%ds", ttlSeconds))
 	b.metrics.RecordPutBackendTTLSeconds(ttl)
+}
 
-	start := time.Now()
-	err := b.delegate.Put(ctx, key, value, ttlSeconds)
+func (b *backendWithMetrics) recordPutResult(value string, err error, elapsed time.Duration) {
 	if err == nil {
-		b.metrics.RecordPutBackendDuration(time.Since(start))
+		b.metrics.RecordPutBackendDuration(elapsed)
 	} else {
 		b.metrics.RecordPutBackendError()
 	}
 	b.metrics.RecordPutBackendSize(float64(len(value)))
+}
+
+func (b *backendWithMetrics) Delete(ctx context.Context, key string) error {
+
+	b.metrics.RecordDeleteBackendTotal()
+	start := time.Now()
+	err := b.delegate.Delete(ctx, key)
+	if err == nil {
+		b.metrics.RecordDeleteBackendDuration(time.Since(start))
+	} else {
+		if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr && pbcErr.Type == utils.KEY_NOT_FOUND {
+			b.metrics.RecordKeyNotFoundError()
+		}
+		b.metrics.RecordDeleteBackendError()
+	}
 	return err
 }
 
+// HealthCheck isn't a request, so no metrics get recorded for it
+func (b *backendWithMetrics) HealthCheck(ctx context.Context) error {
+	return backends.HealthCheck(ctx, b.delegate)
+}
+
 func LogMetrics(backend backends.Backend, m *metrics.Metrics) backends.Backend {
 	return &backendWithMetrics{
 		delegate: backend,
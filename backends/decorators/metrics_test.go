@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/metrics/metricstest"
 	"github.com/prebid/prebid-cache/utils"
@@ -24,6 +25,10 @@ func (b *failedBackend) Put(ctx context.Context, key string, value string, ttlSe
 	return b.returnError
 }
 
+func (b *failedBackend) Delete(ctx context.Context, key string) error {
+	return b.returnError
+}
+
 func TestGetBackendMetrics(t *testing.T) {
 	// Expected values
 	expectedMetrics := []string{
@@ -39,7 +44,7 @@ func TestGetBackendMetrics(t *testing.T) {
 		},
 	}
 
-	rawBackend := backends.NewMemoryBackend()
+	rawBackend := backends.NewMemoryBackend(config.Memory{})
 	rawBackend.Put(context.Background(), "foo", "xml<vast></vast>", 0)
 	backendWithMetrics := LogMetrics(rawBackend, m)
 
@@ -138,7 +143,7 @@ func TestPutSuccessMetrics(t *testing.T) {
 			&mockMetrics,
 		},
 	}
-	backend := LogMetrics(backends.NewMemoryBackend(), m)
+	backend := LogMetrics(backends.NewMemoryBackend(config.Memory{}), m)
 
 	// Run test
 	backend.Put(context.Background(), "foo", "xml<vast></vast>", 60)
@@ -188,7 +193,7 @@ func TestJsonPayloadMetrics(t *testing.T) {
 			&mockMetrics,
 		},
 	}
-	backend := LogMetrics(backends.NewMemoryBackend(), m)
+	backend := LogMetrics(backends.NewMemoryBackend(config.Memory{}), m)
 
 	// Run test
 	backend.Put(context.Background(), "foo", "json{\"key\":\"value\"", 0)
@@ -213,7 +218,7 @@ func TestInvalidPayloadMetrics(t *testing.T) {
 			&mockMetrics,
 		},
 	}
-	backend := LogMetrics(backends.NewMemoryBackend(), m)
+	backend := LogMetrics(backends.NewMemoryBackend(config.Memory{}), m)
 
 	// Run test
 	backend.Put(context.Background(), "foo", "bar", 0)
@@ -221,3 +226,128 @@ func TestInvalidPayloadMetrics(t *testing.T) {
 	// Assert
 	metricstest.AssertMetrics(t, expectedMetrics, mockMetrics)
 }
+
+func TestDeleteBackendMetrics(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		inBackend       backends.Backend
+		expectedMetrics []string
+	}{
+		{
+			desc: "Successful delete",
+			inBackend: func() backends.Backend {
+				b := backends.NewMemoryBackend(config.Memory{})
+				b.Put(context.Background(), "foo", "xml<vast></vast>", 0)
+				return b
+			}(),
+			expectedMetrics: []string{
+				"RecordDeleteBackendTotal",
+				"RecordDeleteBackendDuration",
+			},
+		},
+		{
+			desc:      "Delete of a key that doesn't exist should be accounted as a key not found error",
+			inBackend: &failedBackend{utils.NewPBCError(utils.KEY_NOT_FOUND)},
+			expectedMetrics: []string{
+				"RecordDeleteBackendTotal",
+				"RecordDeleteBackendError",
+				"RecordKeyNotFoundError",
+			},
+		},
+		{
+			desc:      "Other backend error",
+			inBackend: &failedBackend{errors.New("some backend storage service error")},
+			expectedMetrics: []string{
+				"RecordDeleteBackendTotal",
+				"RecordDeleteBackendError",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		// Fresh mock metrics
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		}
+		backend := LogMetrics(tc.inBackend, m)
+
+		// Run test
+		backend.Delete(context.Background(), "foo")
+
+		// Assert
+		metricstest.AssertMetrics(t, tc.expectedMetrics, mockMetrics)
+	}
+}
+
+func TestPutBatchMetrics(t *testing.T) {
+	// Expected values
+	expectedMetrics := []string{
+		"RecordPutBackendBatchSize",
+		"RecordPutBackendXml",
+		"RecordPutBackendJson",
+		"RecordPutBackendTTLSeconds",
+		"RecordPutBackendDuration",
+		"RecordPutBackendError",
+		"RecordPutBackendSize",
+	}
+
+	// Test setup
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	}
+	rawBackend := backends.NewMemoryBackend(config.Memory{})
+	rawBackend.Put(context.Background(), "existing", "xml<vast></vast>", 0)
+	backend := LogMetrics(rawBackend, m)
+
+	// Run test
+	errs := backends.PutBatch(context.Background(), backend, []backends.BatchPutItem{
+		{Key: "foo", Value: "xml<vast></vast>", TTLSeconds: 60},
+		{Key: "bar", Value: "json{\"key\":\"value\"", TTLSeconds: 60},
+		{Key: "existing", Value: "xml<vast></vast>", TTLSeconds: 60},
+	})
+
+	// Assert
+	assert.Equal(t, []error{nil, nil, utils.NewPBCError(utils.RECORD_EXISTS)}, errs)
+	metricstest.AssertMetrics(t, expectedMetrics, mockMetrics)
+	mockMetrics.AssertNumberOfCalls(t, "RecordPutBackendBatchSize", 1)
+	mockMetrics.AssertNumberOfCalls(t, "RecordPutBackendDuration", 2)
+	mockMetrics.AssertNumberOfCalls(t, "RecordPutBackendError", 1)
+}
+
+func TestGetBatchMetrics(t *testing.T) {
+	// Expected values
+	expectedMetrics := []string{
+		"RecordGetBackendBatchSize",
+		"RecordGetBackendTotal",
+		"RecordGetBackendDuration",
+		"RecordGetBackendError",
+		"RecordKeyNotFoundError",
+	}
+
+	// Test setup
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	}
+	rawBackend := backends.NewMemoryBackend(config.Memory{})
+	rawBackend.Put(context.Background(), "foo", "xml<vast></vast>", 0)
+	backend := LogMetrics(rawBackend, m)
+
+	// Run test
+	values, errs := backends.GetBatch(context.Background(), backend, []string{"foo", "unknown"})
+
+	// Assert
+	assert.Equal(t, []string{"xml<vast></vast>", ""}, values)
+	assert.Equal(t, []error{nil, utils.NewPBCError(utils.KEY_NOT_FOUND)}, errs)
+	metricstest.AssertMetrics(t, expectedMetrics, mockMetrics)
+	mockMetrics.AssertNumberOfCalls(t, "RecordGetBackendBatchSize", 1)
+	mockMetrics.AssertNumberOfCalls(t, "RecordGetBackendTotal", 2)
+}
@@ -0,0 +1,181 @@
+package decorators
+
+import (
+	"context"
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+const (
+	// Number of counters puts are spread over to keep gets racing with them from remembering
+	// their keys as missing
+	negativeCacheWriteStripes    = 256
+	bloomFilterFalsePositiveRate = 0.01
+)
+
+// NegativeCache wraps the delegate so that keys the backend reported missing are answered as
+// missing for cfg.TTLSeconds without reaching it again. Puts of a key forget it was missing.
+//
+// If cfg.BloomFilter is enabled, the keys written through this instance are also tracked over
+// maxTTLSeconds, the longest TTL values are stored with, and gets of any other key are answered
+// as missing once the instance has been up for that long.
+func NegativeCache(delegate backends.Backend, cfg config.NegativeCache, maxTTLSeconds int, m *metrics.Metrics) backends.Backend {
+	cache := &negativeCache{
+		delegate: delegate,
+		misses: backends.NewMemoryBackend(config.Memory{
+			MaxItems:             cfg.MaxItems,
+			SweepIntervalSeconds: utils.MEMORY_SWEEP_INTERVAL_SECONDS,
+		}),
+		ttlSeconds: cfg.TTLSeconds,
+		metrics:    m,
+	}
+	if cfg.BloomFilter.Enabled {
+		// Same fallback LimitTTLs uses
+		if maxTTLSeconds <= 0 {
+			maxTTLSeconds = utils.REQUEST_MAX_TTL_SECONDS
+		}
+		cache.written = newRotatingBloomFilter(cfg.BloomFilter.ExpectedKeys, bloomFilterFalsePositiveRate, time.Duration(maxTTLSeconds)*time.Second, time.Now)
+	}
+	return cache
+}
+
+// negativeCache implements the backends.Backend interface to serve as a decorator that keeps
+// track of missing keys
+type negativeCache struct {
+	delegate   backends.Backend
+	misses     *backends.MemoryBackend
+	ttlSeconds int
+	// Keys written through this instance, nil if the bloom filter is disabled
+	written *rotatingBloomFilter
+	// Every put of a key bumps one of these counters. A get only remembers its key as missing
+	// if no put bumped its counter meanwhile
+	writes  [negativeCacheWriteStripes]atomic.Uint64
+	metrics *metrics.Metrics
+}
+
+func (c *negativeCache) Get(ctx context.Context, key string) (string, error) {
+	if c.knownMissing(ctx, key) {
+		return "", utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+
+	writes := c.writesTo(key).Load()
+	value, err := c.delegate.Get(ctx, key)
+	if isKeyNotFound(err) {
+		c.remember(key, writes)
+	}
+	return value, err
+}
+
+// GetBatch only sends the delegate the keys that aren't known to be missing
+func (c *negativeCache) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+
+	var pending []int
+	var pendingKeys []string
+	var writes []uint64
+	for i, key := range keys {
+		if c.knownMissing(ctx, key) {
+			errs[i] = utils.NewPBCError(utils.KEY_NOT_FOUND)
+			continue
+		}
+		pending = append(pending, i)
+		pendingKeys = append(pendingKeys, key)
+		writes = append(writes, c.writesTo(key).Load())
+	}
+	if len(pending) == 0 {
+		return values, errs
+	}
+
+	pendingValues, pendingErrs := backends.GetBatch(ctx, c.delegate, pendingKeys)
+	for j, i := range pending {
+		values[i], errs[i] = pendingValues[j], pendingErrs[j]
+		if isKeyNotFound(errs[i]) {
+			c.remember(keys[i], writes[j])
+		}
+	}
+	return values, errs
+}
+
+func (c *negativeCache) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	c.track(key)
+	err := c.delegate.Put(ctx, key, value, ttlSeconds)
+	// Even a put that failed may have gone through
+	c.forget(key)
+	return err
+}
+
+func (c *negativeCache) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+	for _, item := range items {
+		c.track(item.Key)
+	}
+	errs := backends.PutBatch(ctx, c.delegate, items)
+	for _, item := range items {
+		c.forget(item.Key)
+	}
+	return errs
+}
+
+func (c *negativeCache) Delete(ctx context.Context, key string) error {
+	return c.delegate.Delete(ctx, key)
+}
+
+// HealthCheck is delegated so that remembered misses never hide an unavailable backend
+func (c *negativeCache) HealthCheck(ctx context.Context) error {
+	return backends.HealthCheck(ctx, c.delegate)
+}
+
+// track adds key to the bloom filter, if enabled, before it's written so that gets racing with
+// the put aren't answered as missing once the value can be read
+func (c *negativeCache) track(key string) {
+	if c.written != nil {
+		c.written.add(key)
+	}
+}
+
+// knownMissing tells whether key can be answered as missing without reaching the delegate
+func (c *negativeCache) knownMissing(ctx context.Context, key string) bool {
+	if c.written != nil && c.written.knownMissing(key) {
+		c.metrics.RecordNegativeCacheHit(config.NegativeCacheBloomFilter)
+		return true
+	}
+	if _, err := c.misses.Get(ctx, key); err == nil {
+		c.metrics.RecordNegativeCacheHit(config.NegativeCacheEntry)
+		return true
+	}
+	return false
+}
+
+// remember keeps key as missing, unless a put of it may have come after the get that found it
+// missing. writes is the value of its write counter from before that get
+func (c *negativeCache) remember(key string, writes uint64) {
+	c.misses.Put(context.Background(), key, "", c.ttlSeconds)
+	// A put that bumped the counter after this check forgets key after the entry was stored
+	if c.writesTo(key).Load() != writes {
+		c.misses.Delete(context.Background(), key)
+	}
+}
+
+// forget stops answering key as missing. Gets that found key missing before the put that
+// called it completed won't remember it
+func (c *negativeCache) forget(key string) {
+	c.writesTo(key).Add(1)
+	c.misses.Delete(context.Background(), key)
+}
+
+func (c *negativeCache) writesTo(key string) *atomic.Uint64 {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return &c.writes[hash.Sum32()%negativeCacheWriteStripes]
+}
+
+func isKeyNotFound(err error) bool {
+	pbcErr, isPBCErr := err.(utils.PBCError)
+	return isPBCErr && pbcErr.Type == utils.KEY_NOT_FOUND
+}
@@ -0,0 +1,119 @@
+package decorators
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// countingBackend is a memory backend that counts the keys it was asked to get
+type countingBackend struct {
+	*backends.MemoryBackend
+	gets atomic.Int32
+}
+
+func (b *countingBackend) Get(ctx context.Context, key string) (string, error) {
+	b.gets.Add(1)
+	return b.MemoryBackend.Get(ctx, key)
+}
+
+func newTestNegativeCache(bloomFilter bool) (*negativeCache, *countingBackend, *metricstest.MockMetrics) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	}
+	delegate := &countingBackend{MemoryBackend: backends.NewMemoryBackend(config.Memory{})}
+	cfg := config.NegativeCache{
+		Enabled:     true,
+		TTLSeconds:  60,
+		MaxItems:    100,
+		BloomFilter: config.BloomFilter{Enabled: bloomFilter, ExpectedKeys: 100},
+	}
+	return NegativeCache(delegate, cfg, 3600, m).(*negativeCache), delegate, &mockMetrics
+}
+
+func TestNegativeCacheGet(t *testing.T) {
+	cache, delegate, mockMetrics := newTestNegativeCache(false)
+	ctx := context.Background()
+	notFound := utils.NewPBCError(utils.KEY_NOT_FOUND)
+
+	_, err := cache.Get(ctx, "key")
+	assert.Equal(t, notFound, err)
+	assert.Equal(t, int32(1), delegate.gets.Load(), "Key that isn't known to be missing reaches the backend")
+
+	_, err = cache.Get(ctx, "key")
+	assert.Equal(t, notFound, err)
+	assert.Equal(t, int32(1), delegate.gets.Load(), "Key found missing is answered locally")
+	metricstest.AssertMetrics(t, []string{"RecordNegativeCacheHit"}, *mockMetrics)
+
+	assert.NoError(t, cache.Put(ctx, "key", "value", 0))
+	value, err := cache.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value, "Put forgets the key was missing")
+	assert.Equal(t, int32(2), delegate.gets.Load())
+}
+
+func TestNegativeCacheGetBatch(t *testing.T) {
+	cache, delegate, _ := newTestNegativeCache(false)
+	ctx := context.Background()
+	notFound := utils.NewPBCError(utils.KEY_NOT_FOUND)
+	cache.Get(ctx, "missing")
+	delegate.Put(ctx, "stored", "value", 0)
+	delegate.gets.Store(0)
+
+	values, errs := cache.GetBatch(ctx, []string{"missing", "stored", "unknown"})
+
+	assert.Equal(t, []string{"", "value", ""}, values)
+	assert.Equal(t, []error{notFound, nil, notFound}, errs)
+	assert.Equal(t, int32(2), delegate.gets.Load(), "Only keys that aren't known to be missing reach the backend")
+
+	cache.PutBatch(ctx, []backends.BatchPutItem{{Key: "unknown", Value: "value"}})
+	_, err := cache.Get(ctx, "unknown")
+	assert.NoError(t, err, "PutBatch forgets the keys were missing")
+}
+
+func TestNegativeCacheRacingPut(t *testing.T) {
+	cache, delegate, _ := newTestNegativeCache(false)
+	ctx := context.Background()
+
+	// A get found the key missing right before a put of it went through
+	writes := cache.writesTo("key").Load()
+	cache.Put(ctx, "key", "value", 0)
+	cache.remember("key", writes)
+
+	value, err := cache.Get(ctx, "key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value, "Keys put while being found missing aren't remembered")
+	assert.Equal(t, int32(1), delegate.gets.Load())
+}
+
+func TestNegativeCacheBloomFilter(t *testing.T) {
+	now := time.Now()
+	cache, delegate, mockMetrics := newTestNegativeCache(true)
+	cache.written = newRotatingBloomFilter(100, bloomFilterFalsePositiveRate, time.Hour, func() time.Time { return now })
+	ctx := context.Background()
+
+	cache.Put(ctx, "written", "value", 0)
+	cache.Get(ctx, "unknown")
+	assert.Equal(t, int32(1), delegate.gets.Load(), "Keys written before the instance started may exist")
+
+	now = now.Add(time.Hour)
+	_, err := cache.Get(ctx, "other")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err)
+	assert.Equal(t, int32(1), delegate.gets.Load(), "Keys never written through this instance are answered locally")
+	mockMetrics.AssertNumberOfCalls(t, "RecordNegativeCacheHit", 1)
+
+	value, err := cache.Get(ctx, "written")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", value, "Keys written through this instance reach the backend")
+}
@@ -0,0 +1,300 @@
+package decorators
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+const (
+	// Most retries the budget can save up while requests succeed, so that a burst of errors
+	// after a quiet period can still be retried
+	retryBudgetCapacity = 10
+	// Number of recent get latencies hedging thresholds are computed over
+	hedgeLatencySamples = 1000
+	// Number of gets between updates of the hedging threshold. Gets aren't hedged until that
+	// many latencies were observed
+	hedgeThresholdInterval = 100
+)
+
+// Retry wraps the delegate so that requests failing with transient errors get retried after a
+// jittered exponential backoff, as long as the request deadline leaves time for it and the
+// retry budget allows it. If cfg.HedgePercentile is set, gets that take longer than that
+// percentile of recent gets are sent a second time and the first answer wins.
+func Retry(delegate backends.Backend, cfg config.Retry, m *metrics.Metrics) backends.Backend {
+	return &retrying{
+		delegate:  delegate,
+		cfg:       cfg,
+		metrics:   m,
+		budget:    &retryBudget{tokens: retryBudgetCapacity, ratio: float64(cfg.BudgetPercent) / 100},
+		latencies: &latencyTracker{percentile: cfg.HedgePercentile},
+	}
+}
+
+// retrying implements the backends.Backend interface to serve as a decorator that retries
+// and hedges requests to its delegate
+type retrying struct {
+	delegate  backends.Backend
+	cfg       config.Retry
+	metrics   *metrics.Metrics
+	budget    *retryBudget
+	latencies *latencyTracker
+}
+
+func (r *retrying) Get(ctx context.Context, key string) (string, error) {
+	var value string
+	err := r.retry(ctx, r.cfg.MaxReadRetries, "get", func() error {
+		var err error
+		value, err = r.hedgedGet(ctx, key)
+		return err
+	})
+	return value, err
+}
+
+// GetBatch retries the keys that failed with transient errors together. Batches aren't hedged
+func (r *retrying) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	values, errs := backends.GetBatch(ctx, r.delegate, keys)
+	r.retryBatch(ctx, r.cfg.MaxReadRetries, "get", errs, func(failed []int) []error {
+		retryKeys := make([]string, len(failed))
+		for j, i := range failed {
+			retryKeys[j] = keys[i]
+		}
+		retryValues, retryErrs := backends.GetBatch(ctx, r.delegate, retryKeys)
+		for j, i := range failed {
+			values[i] = retryValues[j]
+		}
+		return retryErrs
+	})
+	return values, errs
+}
+
+func (r *retrying) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	return r.retry(ctx, r.cfg.MaxWriteRetries, "put", func() error {
+		return r.delegate.Put(ctx, key, value, ttlSeconds)
+	})
+}
+
+// PutBatch retries the items that failed with transient errors together
+func (r *retrying) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+	errs := backends.PutBatch(ctx, r.delegate, items)
+	r.retryBatch(ctx, r.cfg.MaxWriteRetries, "put", errs, func(failed []int) []error {
+		retryItems := make([]backends.BatchPutItem, len(failed))
+		for j, i := range failed {
+			retryItems[j] = items[i]
+		}
+		return backends.PutBatch(ctx, r.delegate, retryItems)
+	})
+	return errs
+}
+
+func (r *retrying) Delete(ctx context.Context, key string) error {
+	return r.retry(ctx, r.cfg.MaxWriteRetries, "delete", func() error {
+		return r.delegate.Delete(ctx, key)
+	})
+}
+
+// HealthCheck isn't retried so that readiness probes see transient errors as they happen
+func (r *retrying) HealthCheck(ctx context.Context) error {
+	return backends.HealthCheck(ctx, r.delegate)
+}
+
+// retry calls attempt until it succeeds, fails with an error that isn't transient, or runs out
+// of retries, time or budget. The error of the last attempt is returned
+func (r *retrying) retry(ctx context.Context, maxRetries int, operation string, attempt func() error) error {
+	r.budget.deposit(1)
+	err := attempt()
+	for retry := 0; retry < maxRetries && isTransient(ctx, err); retry++ {
+		if !r.budget.withdraw() || !r.backoff(ctx, retry) {
+			break
+		}
+		r.metrics.RecordBackendRetry(operation)
+		err = attempt()
+	}
+	return err
+}
+
+// retryBatch is retry for batches. Every round calls attempt with the indexes of the elements
+// of errs that are still transient errors and updates them with the errors it returns
+func (r *retrying) retryBatch(ctx context.Context, maxRetries int, operation string, errs []error, attempt func(failed []int) []error) {
+	r.budget.deposit(len(errs))
+	for retry := 0; retry < maxRetries; retry++ {
+		var failed []int
+		for i, err := range errs {
+			if isTransient(ctx, err) {
+				failed = append(failed, i)
+			}
+		}
+		if len(failed) == 0 || !r.budget.withdraw() || !r.backoff(ctx, retry) {
+			return
+		}
+		r.metrics.RecordBackendRetry(operation)
+		for j, err := range attempt(failed) {
+			errs[failed[j]] = err
+		}
+	}
+}
+
+// backoff waits before the retry numbered retry, starting from 0. Returns false without waiting
+// if the request deadline comes before the wait is over
+func (r *retrying) backoff(ctx context.Context, retry int) bool {
+	maxBackoff := time.Duration(r.cfg.MaxBackoffMs) * time.Millisecond
+	ceiling := time.Duration(r.cfg.MinBackoffMs) * time.Millisecond
+	for i := 0; i < retry && ceiling < maxBackoff; i++ {
+		ceiling *= 2
+	}
+	wait := time.Duration(rand.Int64N(int64(min(ceiling, maxBackoff)) + 1))
+
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= wait {
+		return false
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+type getResult struct {
+	value   string
+	err     error
+	elapsed time.Duration
+}
+
+// hedgedGet sends a second get if the first one takes longer than the hedging threshold and
+// the budget allows it. The first successful answer, or the first one that isn't a transient
+// error, wins and the other get is canceled
+func (r *retrying) hedgedGet(ctx context.Context, key string) (string, error) {
+	threshold := r.latencies.threshold()
+	if threshold <= 0 {
+		start := time.Now()
+		value, err := r.delegate.Get(ctx, key)
+		r.observe(ctx, err, time.Since(start))
+		return value, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan getResult, 2)
+	send := func() {
+		start := time.Now()
+		value, err := r.delegate.Get(ctx, key)
+		results <- getResult{value: value, err: err, elapsed: time.Since(start)}
+	}
+	go send()
+	pending := 1
+
+	timer := time.NewTimer(threshold)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			if r.budget.withdraw() {
+				r.metrics.RecordHedgedGet()
+				go send()
+				pending++
+			}
+		case result := <-results:
+			pending--
+			r.observe(ctx, result.err, result.elapsed)
+			if pending == 0 || !isTransient(ctx, result.err) {
+				return result.value, result.err
+			}
+		}
+	}
+}
+
+// observe feeds the latency of a get that got an answer from the backend to the hedging
+// threshold
+func (r *retrying) observe(ctx context.Context, err error, elapsed time.Duration) {
+	if r.cfg.HedgePercentile > 0 && !isTransient(ctx, err) && ctx.Err() == nil {
+		r.latencies.observe(elapsed)
+	}
+}
+
+// isTransient tells whether err is worth retrying. Prebid Cache errors, such as a missing key,
+// are final answers unless they report a timeout of the backend client. Nothing is retried once
+// the request itself is canceled or past its deadline
+func isTransient(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
+		return pbcErr.Type == utils.GET_DEADLINE_EXCEEDED || pbcErr.Type == utils.PUT_DEADLINE_EXCEEDED
+	}
+	return true
+}
+
+// retryBudget limits retries and hedged gets to a share of the requests. Every request deposits
+// that share of a token and every retry withdraws a whole one
+type retryBudget struct {
+	mu     sync.Mutex
+	tokens float64
+	ratio  float64
+}
+
+func (b *retryBudget) deposit(requests int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = min(b.tokens+b.ratio*float64(requests), retryBudgetCapacity)
+}
+
+func (b *retryBudget) withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// latencyTracker keeps the latencies of the most recent gets along with the percentile of them
+// gets are hedged after
+type latencyTracker struct {
+	mu         sync.Mutex
+	percentile int
+	samples    []time.Duration
+	next       int
+	sinceSort  int
+	current    time.Duration
+}
+
+func (l *latencyTracker) observe(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.samples) < hedgeLatencySamples {
+		l.samples = append(l.samples, latency)
+	} else {
+		l.samples[l.next] = latency
+		l.next = (l.next + 1) % hedgeLatencySamples
+	}
+
+	l.sinceSort++
+	if l.sinceSort >= hedgeThresholdInterval {
+		l.sinceSort = 0
+		sorted := slices.Clone(l.samples)
+		slices.Sort(sorted)
+		l.current = sorted[len(sorted)*l.percentile/100]
+	}
+}
+
+// threshold returns how long a get can take before being hedged, or 0 if not enough latencies
+// were observed yet
+func (l *latencyTracker) threshold() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.current
+}
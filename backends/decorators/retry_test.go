@@ -0,0 +1,266 @@
+package decorators
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyBackend fails the first requests for every key with err, as many times as failures
+// says, and succeeds afterwards
+type flakyBackend struct {
+	mu       sync.Mutex
+	err      error
+	failures map[string]int
+	calls    int
+}
+
+func (b *flakyBackend) call(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls++
+	if b.failures[key] > 0 {
+		b.failures[key]--
+		return b.err
+	}
+	return nil
+}
+
+func (b *flakyBackend) Get(ctx context.Context, key string) (string, error) {
+	if err := b.call(key); err != nil {
+		return "", err
+	}
+	return "value", nil
+}
+
+func (b *flakyBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	return b.call(key)
+}
+
+func (b *flakyBackend) Delete(ctx context.Context, key string) error {
+	return b.call(key)
+}
+
+func newTestRetry(delegate *flakyBackend, cfg config.Retry) (*retrying, *metricstest.MockMetrics) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	}
+	return Retry(delegate, cfg, m).(*retrying), &mockMetrics
+}
+
+var testRetryConfig = config.Retry{
+	Enabled:         true,
+	MaxReadRetries:  2,
+	MaxWriteRetries: 1,
+	MinBackoffMs:    1,
+	MaxBackoffMs:    2,
+	BudgetPercent:   20,
+}
+
+func TestRetryGet(t *testing.T) {
+	connErr := errors.New("connection reset by peer")
+
+	testCases := []struct {
+		desc            string
+		inErr           error
+		inFailures      int
+		expectedValue   string
+		expectedErr     error
+		expectedCalls   int
+		expectedMetrics []string
+	}{
+		{
+			desc:            "Transient error followed by a success",
+			inErr:           connErr,
+			inFailures:      1,
+			expectedValue:   "value",
+			expectedCalls:   2,
+			expectedMetrics: []string{"RecordBackendRetry"},
+		},
+		{
+			desc:            "Timeout of the backend client followed by a success",
+			inErr:           utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED),
+			inFailures:      1,
+			expectedValue:   "value",
+			expectedCalls:   2,
+			expectedMetrics: []string{"RecordBackendRetry"},
+		},
+		{
+			desc:            "Transient errors past max_read_retries",
+			inErr:           connErr,
+			inFailures:      5,
+			expectedErr:     connErr,
+			expectedCalls:   3,
+			expectedMetrics: []string{"RecordBackendRetry"},
+		},
+		{
+			desc:          "Missing keys aren't retried",
+			inErr:         utils.NewPBCError(utils.KEY_NOT_FOUND),
+			inFailures:    1,
+			expectedErr:   utils.NewPBCError(utils.KEY_NOT_FOUND),
+			expectedCalls: 1,
+		},
+	}
+
+	for _, test := range testCases {
+		delegate := &flakyBackend{err: test.inErr, failures: map[string]int{"key": test.inFailures}}
+		backend, mockMetrics := newTestRetry(delegate, testRetryConfig)
+
+		value, err := backend.Get(context.Background(), "key")
+
+		assert.Equal(t, test.expectedValue, value, test.desc)
+		assert.Equal(t, test.expectedErr, err, test.desc)
+		assert.Equal(t, test.expectedCalls, delegate.calls, test.desc)
+		metricstest.AssertMetrics(t, test.expectedMetrics, *mockMetrics)
+	}
+}
+
+func TestRetryWrites(t *testing.T) {
+	connErr := errors.New("connection reset by peer")
+
+	testCases := []struct {
+		desc          string
+		inMaxRetries  int
+		expectedErr   error
+		expectedCalls int
+	}{
+		{
+			desc:          "Writes aren't retried by default",
+			expectedErr:   connErr,
+			expectedCalls: 2,
+		},
+		{
+			desc:          "Writes retried up to max_write_retries",
+			inMaxRetries:  1,
+			expectedCalls: 4,
+		},
+	}
+
+	for _, test := range testCases {
+		delegate := &flakyBackend{err: connErr, failures: map[string]int{"put": 1, "delete": 1}}
+		cfg := testRetryConfig
+		cfg.MaxWriteRetries = test.inMaxRetries
+		backend, _ := newTestRetry(delegate, cfg)
+
+		assert.Equal(t, test.expectedErr, backend.Put(context.Background(), "put", "value", 0), "%s: put", test.desc)
+		assert.Equal(t, test.expectedErr, backend.Delete(context.Background(), "delete"), "%s: delete", test.desc)
+		assert.Equal(t, test.expectedCalls, delegate.calls, test.desc)
+	}
+}
+
+func TestRetryGetBatch(t *testing.T) {
+	connErr := errors.New("connection reset by peer")
+	delegate := &flakyBackend{err: connErr, failures: map[string]int{"flaky": 1, "broken": 5}}
+	backend, _ := newTestRetry(delegate, testRetryConfig)
+
+	values, errs := backend.GetBatch(context.Background(), []string{"healthy", "flaky", "broken"})
+
+	assert.Equal(t, []string{"value", "value", ""}, values)
+	assert.Equal(t, []error{nil, nil, connErr}, errs)
+	assert.Equal(t, 6, delegate.calls, "Only failed keys are retried")
+}
+
+func TestRetryBudget(t *testing.T) {
+	delegate := &flakyBackend{err: errors.New("connection reset by peer"), failures: map[string]int{"key": 1000}}
+	cfg := testRetryConfig
+	cfg.MaxReadRetries = 1
+	cfg.BudgetPercent = 10
+	backend, _ := newTestRetry(delegate, cfg)
+
+	for i := 0; i < 100; i++ {
+		backend.Get(context.Background(), "key")
+	}
+
+	// The budget starts full and every get deposits a tenth of a retry
+	assert.InDelta(t, 120, delegate.calls, 2, "Retries are limited by the budget")
+}
+
+func TestRetryCanceledRequest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	delegate := &cancelingBackend{cancel: cancel}
+	backend, _ := newTestRetry(&flakyBackend{}, testRetryConfig)
+	backend.delegate = delegate
+
+	_, err := backend.Get(ctx, "key")
+
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 1, delegate.calls, "Requests the caller gave up on aren't retried")
+}
+
+// cancelingBackend cancels the request it gets as if the caller went away
+type cancelingBackend struct {
+	flakyBackend
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBackend) Get(ctx context.Context, key string) (string, error) {
+	b.calls++
+	b.cancel()
+	return "", ctx.Err()
+}
+
+// slowFirstBackend answers every get but the first one right away. The first one waits until
+// canceled
+type slowFirstBackend struct {
+	flakyBackend
+	canceled chan struct{}
+}
+
+func (b *slowFirstBackend) Get(ctx context.Context, key string) (string, error) {
+	b.mu.Lock()
+	b.calls++
+	first := b.calls == 1
+	b.mu.Unlock()
+
+	if first {
+		<-ctx.Done()
+		close(b.canceled)
+		return "", ctx.Err()
+	}
+	return "hedged value", nil
+}
+
+func TestHedgedGet(t *testing.T) {
+	delegate := &slowFirstBackend{canceled: make(chan struct{})}
+	cfg := testRetryConfig
+	cfg.HedgePercentile = 95
+	backend, mockMetrics := newTestRetry(&flakyBackend{}, cfg)
+	backend.delegate = delegate
+	backend.latencies.current = time.Millisecond
+
+	value, err := backend.Get(context.Background(), "key")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "hedged value", value, "First answer wins")
+	metricstest.AssertMetrics(t, []string{"RecordHedgedGet"}, *mockMetrics)
+	select {
+	case <-delegate.canceled:
+	case <-time.After(time.Second):
+		t.Error("Slower get should be canceled")
+	}
+}
+
+func TestLatencyTracker(t *testing.T) {
+	tracker := &latencyTracker{percentile: 95}
+
+	for i := 1; i < hedgeThresholdInterval; i++ {
+		tracker.observe(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, time.Duration(0), tracker.threshold(), "Gets aren't hedged until enough latencies are observed")
+
+	for i := hedgeThresholdInterval; i <= 2*hedgeThresholdInterval; i++ {
+		tracker.observe(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 191*time.Millisecond, tracker.threshold(), "95th percentile of the observed latencies")
+}
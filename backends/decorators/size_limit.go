@@ -25,7 +25,51 @@ func (b *sizeCappedBackend) Get(ctx context.Context, key string) (string, error)
 	return b.delegate.Get(ctx, key)
 }
 
+func (b *sizeCappedBackend) Delete(ctx context.Context, key string) error {
+	return b.delegate.Delete(ctx, key)
+}
+
 func (b *sizeCappedBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	if err := b.checkSize(value); err != nil {
+		return err
+	}
+
+	return b.delegate.Put(ctx, key, value, ttlSeconds)
+}
+
+// PutBatch rejects the items whose payload is too large and forwards the rest to the delegate
+func (b *sizeCappedBackend) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+	errs := make([]error, len(items))
+
+	indexes := make([]int, 0, len(items))
+	accepted := make([]backends.BatchPutItem, 0, len(items))
+	for i, item := range items {
+		if err := b.checkSize(item.Value); err != nil {
+			errs[i] = err
+			continue
+		}
+		indexes = append(indexes, i)
+		accepted = append(accepted, item)
+	}
+
+	if len(accepted) > 0 {
+		for j, err := range backends.PutBatch(ctx, b.delegate, accepted) {
+			errs[indexes[j]] = err
+		}
+	}
+	return errs
+}
+
+func (b *sizeCappedBackend) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	return backends.GetBatch(ctx, b.delegate, keys)
+}
+
+// HealthCheck will simply make the delegate health check given that it stores no value
+func (b *sizeCappedBackend) HealthCheck(ctx context.Context) error {
+	return backends.HealthCheck(ctx, b.delegate)
+}
+
+func (b *sizeCappedBackend) checkSize(value string) error {
 	valueLen := len(value)
 	if valueLen == 0 || valueLen > b.limit {
 		return &BadPayloadSize{
@@ -33,8 +77,7 @@ func (b *sizeCappedBackend) Put(ctx context.Context, key string, value string, t
 			Size:  valueLen,
 		}
 	}
-
-	return b.delegate.Put(ctx, key, value, ttlSeconds)
+	return nil
 }
 
 type BadPayloadSize struct {
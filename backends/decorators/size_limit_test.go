@@ -3,6 +3,8 @@ package decorators
 import (
 	"context"
 	"testing"
+
+	"github.com/prebid/prebid-cache/backends"
 )
 
 func TestLargePayload(t *testing.T) {
@@ -17,6 +19,24 @@ func TestAcceptablePayload(t *testing.T) {
 	assertNilError(t, wrapped.Put(context.Background(), "foo", "12345", 0))
 }
 
+func TestPutBatchPayloadSizes(t *testing.T) {
+	delegate := &successfulBackend{}
+	wrapped := EnforceSizeLimit(delegate, 5)
+
+	errs := backends.PutBatch(context.Background(), wrapped, []backends.BatchPutItem{
+		{Key: "foo", Value: "12345"},
+		{Key: "bar", Value: "123456"},
+		{Key: "baz", Value: ""},
+	})
+
+	if len(errs) != 3 {
+		t.Fatalf("Expected 3 errors, got %d", len(errs))
+	}
+	assertNilError(t, errs[0])
+	assertBadPayloadError(t, errs[1])
+	assertBadPayloadError(t, errs[2])
+}
+
 func assertBadPayloadError(t *testing.T, err error) {
 	t.Helper()
 
@@ -45,3 +65,7 @@ func (b *successfulBackend) Get(ctx context.Context, key string) (string, error)
 func (b *successfulBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
 	return nil
 }
+
+func (b *successfulBackend) Delete(ctx context.Context, key string) error {
+	return nil
+}
@@ -0,0 +1,591 @@
+package backends
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"git.pubmatic.com/PubMatic/go-common/logger"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// FileBackend persists values to local disk so they survive restarts without a separate
+// storage service. Every write is appended as a record to the open segment file, which gets
+// closed once it reaches config.backend.file.segment_size_bytes, while an in-memory index
+// points every key to the record holding its value.
+//
+// Records carry a sequence number, so on startup the index is rebuilt by replaying every
+// segment and keeping the latest record of each key. A record left incomplete by a crash fails
+// its checksum and the segment gets truncated before it. Closed segments are periodically
+// compacted: the values still live in them are copied to a new segment, dropping expired,
+// deleted and overwritten ones, and the old segments get removed.
+//
+// Records are written without syncing, so values survive the process crashing but may not
+// survive the host losing power.
+type FileBackend struct {
+	dir         string
+	segmentSize int64
+	index       map[string]fileEntry
+	segments    map[uint64]*fileSegment
+	active      *fileSegment
+	nextID      uint64
+	nextSeq     uint64
+	now         func() time.Time
+	mu          sync.RWMutex
+	// compactMu keeps compactions from overlapping. Compaction relies on every closed segment
+	// being compacted together, so that deletion records can be dropped along with the values
+	// they delete
+	compactMu sync.Mutex
+}
+
+// fileSegment is one of the files records get appended to
+type fileSegment struct {
+	id   uint64
+	file *os.File
+	// Bytes of valid records in the file
+	size int64
+	// Bytes of records that are no longer referenced by the index and compaction can drop
+	dead int64
+}
+
+// fileEntry locates the record holding the value of a key
+type fileEntry struct {
+	segment   *fileSegment
+	offset    int64
+	size      int64
+	seq       uint64
+	expiresAt time.Time
+}
+
+// expired returns true if the entry was stored with a TTL that has already elapsed
+func (e fileEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// fileRecord is a decoded record. Records are laid out as a CRC-32 checksum of the rest of the
+// record, the sequence number, the operation, the expiration time in Unix nanoseconds (0 if
+// none), the key and value lengths, and finally the key and value bytes. Integers are big endian
+const fileRecordHeaderSize = 4 + 8 + 1 + 8 + 4 + 4
+
+type fileRecord struct {
+	op        byte
+	seq       uint64
+	expiresAt time.Time
+	key       string
+	size      int64
+}
+
+const (
+	fileOpPut    byte = 1
+	fileOpDelete byte = 2
+)
+
+const (
+	fileSegmentExt    = ".seg"
+	fileCompactingExt = ".seg.tmp"
+)
+
+// NewFileBackend opens the segments in cfg.Directory, creating it if needed, and starts the
+// periodic compaction if cfg.CompactionIntervalSeconds is positive
+func NewFileBackend(cfg config.File) *FileBackend {
+	backend, err := openFileBackend(cfg.Directory, int64(cfg.SegmentSizeBytes), time.Now)
+	if err != nil {
+		logger.Fatal("Error creating file backend: %v", err)
+		panic("FileBackend failure. This shouldn't happen.")
+	}
+
+	if cfg.CompactionIntervalSeconds > 0 {
+		go backend.runCompactor(time.Duration(cfg.CompactionIntervalSeconds) * time.Second)
+	}
+
+	return backend
+}
+
+// openFileBackend rebuilds the index out of the segments in dir and starts a new segment to
+// write to
+func openFileBackend(dir string, segmentSize int64, now func() time.Time) (*FileBackend, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	b := &FileBackend{
+		dir:         dir,
+		segmentSize: segmentSize,
+		index:       make(map[string]fileEntry),
+		segments:    make(map[uint64]*fileSegment),
+		now:         now,
+	}
+
+	ids, err := b.listSegments()
+	if err != nil {
+		return nil, err
+	}
+
+	latest := make(map[string]uint64)
+	for _, id := range ids {
+		file, err := os.OpenFile(b.segmentPath(id, fileSegmentExt), os.O_RDWR, 0644)
+		if err != nil {
+			return nil, err
+		}
+		segment := &fileSegment{id: id, file: file}
+		b.segments[id] = segment
+		if err := b.recoverSegment(segment, latest); err != nil {
+			return nil, err
+		}
+		b.nextID = id + 1
+	}
+	b.expire(now())
+
+	if err := b.startSegment(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Get reads the value of key from the segment its record is in
+func (b *FileBackend) Get(ctx context.Context, key string) (string, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	entry, ok := b.index[key]
+	if !ok || entry.expired(b.now()) {
+		return "", utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+
+	valueOffset := int64(fileRecordHeaderSize + len(key))
+	value := make([]byte, entry.size-valueOffset)
+	if _, err := entry.segment.file.ReadAt(value, entry.offset+valueOffset); err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// Put appends a record holding value unless key already holds a value that hasn't expired. A
+// positive ttlSeconds value sets the time after which the value will no longer be served
+func (b *FileBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	previous, exists := b.index[key]
+	if exists && !previous.expired(now) {
+		return utils.NewPBCError(utils.RECORD_EXISTS)
+	}
+
+	var expiresAt time.Time
+	if ttlSeconds > 0 {
+		expiresAt = now.Add(time.Duration(ttlSeconds) * time.Second)
+	}
+
+	entry, err := b.appendRecord(fileOpPut, key, value, expiresAt)
+	if err != nil {
+		return err
+	}
+	if exists {
+		previous.segment.dead += previous.size
+	}
+	b.index[key] = entry
+	return nil
+}
+
+// Delete appends a record that deletes key. Returns a KEY_NOT_FOUND error if key holds no value
+// or if it already expired, in which case there's no need for such a record since expired
+// values aren't recovered
+func (b *FileBackend) Delete(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	entry, ok := b.index[key]
+	if !ok {
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+	if entry.expired(b.now()) {
+		b.drop(key, entry)
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+
+	deletion, err := b.appendRecord(fileOpDelete, key, "", time.Time{})
+	if err != nil {
+		return err
+	}
+	deletion.segment.dead += deletion.size
+	b.drop(key, entry)
+	return nil
+}
+
+// appendRecord writes a record to the open segment, starting a new one first if it's full. Must
+// be called with b.mu held
+func (b *FileBackend) appendRecord(op byte, key string, value string, expiresAt time.Time) (fileEntry, error) {
+	if b.active.size >= b.segmentSize {
+		if err := b.startSegment(); err != nil {
+			return fileEntry{}, err
+		}
+	}
+
+	record := encodeFileRecord(op, b.nextSeq, expiresAt, key, value)
+	if _, err := b.active.file.WriteAt(record, b.active.size); err != nil {
+		return fileEntry{}, err
+	}
+
+	entry := fileEntry{
+		segment:   b.active,
+		offset:    b.active.size,
+		size:      int64(len(record)),
+		seq:       b.nextSeq,
+		expiresAt: expiresAt,
+	}
+	b.active.size += entry.size
+	b.nextSeq++
+	return entry, nil
+}
+
+// startSegment closes the open segment, if any, and creates a new one to write to. Must be
+// called with b.mu held
+func (b *FileBackend) startSegment() error {
+	if b.active != nil {
+		if err := b.active.file.Sync(); err != nil {
+			return err
+		}
+	}
+
+	file, err := os.OpenFile(b.segmentPath(b.nextID, fileSegmentExt), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	b.active = &fileSegment{id: b.nextID, file: file}
+	b.segments[b.nextID] = b.active
+	b.nextID++
+	return nil
+}
+
+// drop removes key from the index and accounts its record as dead. Must be called with b.mu held
+func (b *FileBackend) drop(key string, entry fileEntry) {
+	entry.segment.dead += entry.size
+	delete(b.index, key)
+}
+
+// expire drops every expired entry from the index. Must be called with b.mu held
+func (b *FileBackend) expire(now time.Time) {
+	for key, entry := range b.index {
+		if entry.expired(now) {
+			b.drop(key, entry)
+		}
+	}
+}
+
+// compact copies the values still live in the closed segments to a new segment and removes the
+// closed segments. Values are copied without holding b.mu, since closed segments don't change,
+// and copies of values deleted meanwhile are accounted as dead. Returns how many segments were
+// removed
+func (b *FileBackend) compact() (int, error) {
+	b.compactMu.Lock()
+	defer b.compactMu.Unlock()
+
+	b.mu.Lock()
+	b.expire(b.now())
+	var closed []*fileSegment
+	var dead int64
+	for _, segment := range b.segments {
+		if segment != b.active {
+			closed = append(closed, segment)
+			dead += segment.dead
+		}
+	}
+	if dead == 0 {
+		b.mu.Unlock()
+		return 0, nil
+	}
+
+	var keys []string
+	var live []fileEntry
+	for key, entry := range b.index {
+		if entry.segment != b.active {
+			keys = append(keys, key)
+			live = append(live, entry)
+		}
+	}
+	id := b.nextID
+	b.nextID++
+	b.mu.Unlock()
+
+	// Reading records in the order they were written keeps reads sequential
+	sort.Sort(fileEntriesByPosition{keys: keys, entries: live})
+
+	compacted, copies, err := b.writeSegment(id, live)
+	if err != nil {
+		return 0, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, entry := range live {
+		key := keys[i]
+		if current, ok := b.index[key]; ok && current.seq == entry.seq {
+			b.index[key] = copies[i]
+		} else {
+			compacted.dead += copies[i].size
+		}
+	}
+	if compacted != nil {
+		b.segments[compacted.id] = compacted
+	}
+	for _, segment := range closed {
+		delete(b.segments, segment.id)
+		segment.file.Close()
+		if err := os.Remove(segment.file.Name()); err != nil {
+			logger.Warn("Error removing compacted file backend segment %s: %v", segment.file.Name(), err)
+		}
+	}
+	return len(closed), nil
+}
+
+// writeSegment copies the records entries point to into the segment id, which only appears in
+// the directory once completely written. Returns the segment along with the entries pointing
+// to the copies, or a nil segment if there's nothing to copy
+func (b *FileBackend) writeSegment(id uint64, entries []fileEntry) (*fileSegment, []fileEntry, error) {
+	if len(entries) == 0 {
+		return nil, nil, nil
+	}
+
+	tmpPath := b.segmentPath(id, fileCompactingExt)
+	file, err := os.OpenFile(tmpPath, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return nil, nil, err
+	}
+	fail := func(err error) (*fileSegment, []fileEntry, error) {
+		file.Close()
+		os.Remove(tmpPath)
+		return nil, nil, err
+	}
+
+	segment := &fileSegment{id: id, file: file}
+	copies := make([]fileEntry, len(entries))
+	writer := bufio.NewWriter(file)
+	for i, entry := range entries {
+		record := make([]byte, entry.size)
+		if _, err := entry.segment.file.ReadAt(record, entry.offset); err != nil {
+			return fail(err)
+		}
+		if _, err := writer.Write(record); err != nil {
+			return fail(err)
+		}
+		copies[i] = entry
+		copies[i].segment = segment
+		copies[i].offset = segment.size
+		segment.size += entry.size
+	}
+	if err := writer.Flush(); err != nil {
+		return fail(err)
+	}
+	if err := file.Sync(); err != nil {
+		return fail(err)
+	}
+	if err := os.Rename(tmpPath, b.segmentPath(id, fileSegmentExt)); err != nil {
+		return fail(err)
+	}
+	// The new name must be durable before the compacted segments get removed
+	if err := syncDir(b.dir); err != nil {
+		return nil, nil, err
+	}
+	return segment, copies, nil
+}
+
+// fileEntriesByPosition sorts entries, along with the keys they hold, by segment and offset
+type fileEntriesByPosition struct {
+	keys    []string
+	entries []fileEntry
+}
+
+func (p fileEntriesByPosition) Len() int { return len(p.entries) }
+
+func (p fileEntriesByPosition) Less(i, j int) bool {
+	if p.entries[i].segment.id != p.entries[j].segment.id {
+		return p.entries[i].segment.id < p.entries[j].segment.id
+	}
+	return p.entries[i].offset < p.entries[j].offset
+}
+
+func (p fileEntriesByPosition) Swap(i, j int) {
+	p.keys[i], p.keys[j] = p.keys[j], p.keys[i]
+	p.entries[i], p.entries[j] = p.entries[j], p.entries[i]
+}
+
+// runCompactor periodically compacts the closed segments so disk space gets released
+func (b *FileBackend) runCompactor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if removed, err := b.compact(); err != nil {
+			logger.Error("File backend compaction failed: %v", err)
+		} else if removed > 0 {
+			logger.Debug("File backend compaction removed %d segments", removed)
+		}
+	}
+}
+
+// listSegments returns the ids of the segments in the directory in ascending order. Segments
+// left behind by a compaction that didn't complete are removed, as the segments they were
+// being copied from are still there
+func (b *FileBackend) listSegments() ([]uint64, error) {
+	files, err := os.ReadDir(b.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []uint64
+	for _, file := range files {
+		name := file.Name()
+		switch {
+		case strings.HasSuffix(name, fileCompactingExt):
+			if err := os.Remove(filepath.Join(b.dir, name)); err != nil {
+				return nil, err
+			}
+		case strings.HasSuffix(name, fileSegmentExt):
+			id, err := strconv.ParseUint(strings.TrimSuffix(name, fileSegmentExt), 10, 64)
+			if err != nil {
+				logger.Warn("Ignoring file %s in the file backend directory", name)
+				continue
+			}
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, nil
+}
+
+// recoverSegment replays the records in segment. latest holds the sequence number of the last
+// record of every key replayed so far, deletions included, so that segments can be replayed in
+// any order. Whatever follows an incomplete or corrupt record is truncated
+func (b *FileBackend) recoverSegment(segment *fileSegment, latest map[string]uint64) error {
+	info, err := segment.file.Stat()
+	if err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(segment.file, 0, info.Size()))
+	for {
+		record, err := readFileRecord(reader, info.Size()-segment.size)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			logger.Warn("File backend segment %s is corrupt after byte %d: %v. Truncating it.", segment.file.Name(), segment.size, err)
+			return segment.file.Truncate(segment.size)
+		}
+
+		b.recoverRecord(segment, record, latest)
+		segment.size += record.size
+		if record.seq >= b.nextSeq {
+			b.nextSeq = record.seq + 1
+		}
+	}
+}
+
+// recoverRecord applies record, found at the current end of segment, to the index unless a
+// later record of the same key was already replayed
+func (b *FileBackend) recoverRecord(segment *fileSegment, record fileRecord, latest map[string]uint64) {
+	if seq, ok := latest[record.key]; ok && seq > record.seq {
+		segment.dead += record.size
+		return
+	}
+	latest[record.key] = record.seq
+
+	if previous, ok := b.index[record.key]; ok {
+		b.drop(record.key, previous)
+	}
+	if record.op == fileOpDelete {
+		segment.dead += record.size
+		return
+	}
+	b.index[record.key] = fileEntry{
+		segment:   segment,
+		offset:    segment.size,
+		size:      record.size,
+		seq:       record.seq,
+		expiresAt: record.expiresAt,
+	}
+}
+
+func (b *FileBackend) segmentPath(id uint64, ext string) string {
+	return filepath.Join(b.dir, fmt.Sprintf("%020d%s", id, ext))
+}
+
+func encodeFileRecord(op byte, seq uint64, expiresAt time.Time, key string, value string) []byte {
+	record := make([]byte, fileRecordHeaderSize+len(key)+len(value))
+	binary.BigEndian.PutUint64(record[4:], seq)
+	record[12] = op
+	if !expiresAt.IsZero() {
+		binary.BigEndian.PutUint64(record[13:], uint64(expiresAt.UnixNano()))
+	}
+	binary.BigEndian.PutUint32(record[21:], uint32(len(key)))
+	binary.BigEndian.PutUint32(record[25:], uint32(len(value)))
+	copy(record[fileRecordHeaderSize:], key)
+	copy(record[fileRecordHeaderSize+len(key):], value)
+	binary.BigEndian.PutUint32(record, crc32.ChecksumIEEE(record[4:]))
+	return record
+}
+
+// readFileRecord decodes the next record out of reader, which holds remaining bytes. Returns
+// io.EOF if there are no more records
+func readFileRecord(reader *bufio.Reader, remaining int64) (fileRecord, error) {
+	header := make([]byte, fileRecordHeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return fileRecord{}, err
+	}
+
+	keyLen := int64(binary.BigEndian.Uint32(header[21:]))
+	valueLen := int64(binary.BigEndian.Uint32(header[25:]))
+	size := fileRecordHeaderSize + keyLen + valueLen
+	if size > remaining {
+		return fileRecord{}, errors.New("record extends past the end of the segment")
+	}
+
+	body := make([]byte, keyLen+valueLen)
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return fileRecord{}, err
+	}
+
+	checksum := crc32.NewIEEE()
+	checksum.Write(header[4:])
+	checksum.Write(body)
+	if checksum.Sum32() != binary.BigEndian.Uint32(header) {
+		return fileRecord{}, errors.New("checksum mismatch")
+	}
+
+	record := fileRecord{
+		op:   header[12],
+		seq:  binary.BigEndian.Uint64(header[4:]),
+		key:  string(body[:keyLen]),
+		size: size,
+	}
+	if record.op != fileOpPut && record.op != fileOpDelete {
+		return fileRecord{}, fmt.Errorf("unknown operation %d", record.op)
+	}
+	if expiresAt := int64(binary.BigEndian.Uint64(header[13:])); expiresAt != 0 {
+		record.expiresAt = time.Unix(0, expiresAt)
+	}
+	return record, nil
+}
+
+// syncDir makes the creation, renaming and removal of the files in dir durable
+func syncDir(dir string) error {
+	file, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return file.Sync()
+}
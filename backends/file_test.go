@@ -0,0 +1,192 @@
+package backends
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestFileBackend opens a file backend in dir whose clock is set to whatever now points to
+func newTestFileBackend(t *testing.T, dir string, segmentSize int64, now *time.Time) *FileBackend {
+	backend, err := openFileBackend(dir, segmentSize, func() time.Time { return *now })
+	if !assert.NoError(t, err, "Open file backend") {
+		t.FailNow()
+	}
+	return backend
+}
+
+func countSegments(t *testing.T, dir string) int {
+	segments, err := filepath.Glob(filepath.Join(dir, "*"+fileSegmentExt))
+	assert.NoError(t, err)
+	return len(segments)
+}
+
+func TestFileBackend(t *testing.T) {
+	now := time.Now()
+	backend := newTestFileBackend(t, t.TempDir(), 1024, &now)
+	ctx := context.Background()
+
+	assert.NoError(t, backend.Put(ctx, "key", "value", 0), "Put")
+	assert.Equal(t, utils.NewPBCError(utils.RECORD_EXISTS), backend.Put(ctx, "key", "other value", 0), "Put of an existing key")
+
+	value, err := backend.Get(ctx, "key")
+	assert.NoError(t, err, "Get")
+	assert.Equal(t, "value", value, "Get")
+
+	assert.NoError(t, backend.Delete(ctx, "key"), "Delete")
+	_, err = backend.Get(ctx, "key")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Get of a deleted key")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), backend.Delete(ctx, "key"), "Delete of a deleted key")
+
+	assert.NoError(t, backend.Put(ctx, "key", "new value", 0), "Put of a deleted key")
+	value, _ = backend.Get(ctx, "key")
+	assert.Equal(t, "new value", value, "Get of a key stored again")
+}
+
+func TestFileBackendTTL(t *testing.T) {
+	now := time.Now()
+	backend := newTestFileBackend(t, t.TempDir(), 1024, &now)
+	ctx := context.Background()
+
+	assert.NoError(t, backend.Put(ctx, "key", "value", 10))
+
+	now = now.Add(9 * time.Second)
+	_, err := backend.Get(ctx, "key")
+	assert.NoError(t, err, "Value is served within its TTL")
+
+	now = now.Add(time.Second)
+	_, err = backend.Get(ctx, "key")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Value expires after its TTL")
+	assert.NoError(t, backend.Put(ctx, "key", "new value", 10), "Expired key can be stored again")
+}
+
+func TestFileBackendRecovery(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	backend := newTestFileBackend(t, dir, 64, &now)
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, backend.Put(ctx, fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i), 0))
+	}
+	assert.NoError(t, backend.Put(ctx, "short-lived", "value", 5))
+	assert.NoError(t, backend.Delete(ctx, "key-3"))
+
+	now = now.Add(5 * time.Second)
+	recovered := newTestFileBackend(t, dir, 64, &now)
+
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		value, err := recovered.Get(ctx, key)
+		if i == 3 {
+			assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Deleted key stays deleted")
+		} else {
+			assert.NoError(t, err, "Key %s", key)
+			assert.Equal(t, fmt.Sprintf("value-%d", i), value, "Key %s", key)
+		}
+	}
+	_, err := recovered.Get(ctx, "short-lived")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Expired values aren't recovered")
+
+	assert.NoError(t, recovered.Put(ctx, "key-3", "new value", 0), "Deleted key can be stored again")
+	value, _ := newTestFileBackend(t, dir, 64, &now).Get(ctx, "key-3")
+	assert.Equal(t, "new value", value, "Value stored after recovery replaces the deleted one")
+}
+
+func TestFileBackendTornWrite(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	backend := newTestFileBackend(t, dir, 1024, &now)
+	assert.NoError(t, backend.Put(ctx, "key", "value", 0))
+	segmentPath := backend.active.file.Name()
+	validSize := backend.active.size
+
+	// Simulate a crash in the middle of writing a record
+	record := encodeFileRecord(fileOpPut, backend.nextSeq, time.Time{}, "torn", "value")
+	file, err := os.OpenFile(segmentPath, os.O_WRONLY|os.O_APPEND, 0644)
+	assert.NoError(t, err)
+	file.Write(record[:len(record)-2])
+	file.Close()
+
+	recovered := newTestFileBackend(t, dir, 1024, &now)
+
+	value, err := recovered.Get(ctx, "key")
+	assert.NoError(t, err, "Complete record is recovered")
+	assert.Equal(t, "value", value, "Complete record is recovered")
+	_, err = recovered.Get(ctx, "torn")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Incomplete record isn't recovered")
+
+	info, err := os.Stat(segmentPath)
+	assert.NoError(t, err)
+	assert.Equal(t, validSize, info.Size(), "Incomplete record is truncated")
+}
+
+func TestFileBackendCompaction(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	backend := newTestFileBackend(t, dir, 128, &now)
+	for i := 0; i < 20; i++ {
+		ttl := 0
+		if i%4 == 1 {
+			ttl = 10
+		}
+		assert.NoError(t, backend.Put(ctx, fmt.Sprintf("key-%d", i), fmt.Sprintf("value-%d", i), ttl))
+	}
+	for i := 0; i < 20; i += 4 {
+		assert.NoError(t, backend.Delete(ctx, fmt.Sprintf("key-%d", i)))
+	}
+	now = now.Add(10 * time.Second)
+	segmentsBefore := countSegments(t, dir)
+
+	removed, err := backend.compact()
+	assert.NoError(t, err, "Compaction")
+	assert.Equal(t, segmentsBefore-1, removed, "Every closed segment is compacted")
+	assert.Equal(t, 2, countSegments(t, dir), "Closed segments are replaced by the compacted one")
+
+	removed, err = backend.compact()
+	assert.NoError(t, err, "Second compaction")
+	assert.Equal(t, 0, removed, "Segments without dead records aren't compacted again")
+
+	checkValues := func(backend *FileBackend, desc string) {
+		for i := 0; i < 20; i++ {
+			key := fmt.Sprintf("key-%d", i)
+			value, err := backend.Get(ctx, key)
+			if i%4 == 0 || i%4 == 1 {
+				assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "%s: deleted or expired key %s", desc, key)
+			} else {
+				assert.NoError(t, err, "%s: key %s", desc, key)
+				assert.Equal(t, fmt.Sprintf("value-%d", i), value, "%s: key %s", desc, key)
+			}
+		}
+	}
+	checkValues(backend, "After compaction")
+	checkValues(newTestFileBackend(t, dir, 128, &now), "After recovering the compacted segments")
+}
+
+func TestFileBackendIncompleteCompaction(t *testing.T) {
+	now := time.Now()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	backend := newTestFileBackend(t, dir, 1024, &now)
+	assert.NoError(t, backend.Put(ctx, "key", "value", 0))
+	leftover := filepath.Join(dir, fmt.Sprintf("%020d%s", backend.nextID, fileCompactingExt))
+	assert.NoError(t, os.WriteFile(leftover, []byte("partial copy"), 0644))
+
+	recovered := newTestFileBackend(t, dir, 1024, &now)
+
+	value, _ := recovered.Get(ctx, "key")
+	assert.Equal(t, "value", value, "Values are recovered from the segments being compacted")
+	_, err := os.Stat(leftover)
+	assert.True(t, os.IsNotExist(err), "Segment of the incomplete compaction is removed")
+}
@@ -0,0 +1,98 @@
+package backends
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"git.pubmatic.com/PubMatic/go-common/logger"
+	"github.com/prebid/prebid-cache/config"
+)
+
+// healthCheckKey is the key backends without a health check of their own get read from to
+// find out whether their storage service answers. It's never written
+const healthCheckKey = "prebid-cache-health-check"
+
+// HealthChecker is implemented by those backends that can tell whether their storage service
+// is reachable in a cheaper or more accurate way than by reading a key. Decorators and composite
+// backends implement it to reach the backends they delegate to
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// HealthCheck returns an error if backend can't serve requests. If backend implements
+// HealthChecker its own check is run, otherwise a key that's never written gets read, which
+// succeeds when the storage service answers that the key is missing
+func HealthCheck(ctx context.Context, backend Backend) error {
+	if checker, ok := backend.(HealthChecker); ok {
+		return checker.HealthCheck(ctx)
+	}
+
+	_, err := backend.Get(ctx, healthCheckKey)
+	if err != nil && !isKeyNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// HealthMonitor checks the health of a backend in the background so that readiness probes get
+// the outcome of the last check without reaching the backend themselves
+type HealthMonitor struct {
+	backend Backend
+	name    string
+	timeout time.Duration
+
+	mu  sync.RWMutex
+	err error
+}
+
+// NewHealthMonitor checks the health of backend, which name describes, right away and then every
+// cfg.IntervalSeconds
+func NewHealthMonitor(backend Backend, name string, cfg config.HealthCheck) *HealthMonitor {
+	monitor := &HealthMonitor{
+		backend: backend,
+		name:    name,
+		timeout: time.Duration(cfg.TimeoutMs) * time.Millisecond,
+	}
+	monitor.check()
+	go monitor.run(time.Duration(cfg.IntervalSeconds) * time.Second)
+	return monitor
+}
+
+// Name returns the name of the backend being monitored
+func (m *HealthMonitor) Name() string {
+	return m.name
+}
+
+// Err returns the error the last health check failed with, or nil if it succeeded
+func (m *HealthMonitor) Err() error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.err
+}
+
+func (m *HealthMonitor) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		m.check()
+	}
+}
+
+func (m *HealthMonitor) check() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	err := HealthCheck(ctx, m.backend)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch {
+	case err != nil && m.err == nil:
+		logger.Error("%s backend health check failed: %v", m.name, err)
+	case err == nil && m.err != nil:
+		logger.Info("%s backend is healthy again", m.name)
+	}
+	m.err = err
+}
@@ -0,0 +1,92 @@
+package backends
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// checkedBackend is a memory backend with a health check of its own that fails with err
+type checkedBackend struct {
+	*MemoryBackend
+	err error
+}
+
+func (b *checkedBackend) HealthCheck(ctx context.Context) error {
+	return b.err
+}
+
+func TestHealthCheck(t *testing.T) {
+	pingErr := errors.New("ping failed")
+	unhealthy := &checkedBackend{MemoryBackend: NewMemoryBackend(config.Memory{}), err: pingErr}
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	}
+
+	testCases := []struct {
+		desc          string
+		inBackend     Backend
+		expectedError error
+	}{
+		{
+			desc:      "Sentinel key not found",
+			inBackend: NewMemoryBackend(config.Memory{}),
+		},
+		{
+			desc:          "Sentinel key read fails",
+			inBackend:     NewErrorResponseMemoryBackend(),
+			expectedError: errors.New("Bakend error"),
+		},
+		{
+			desc:          "Backend with a health check of its own",
+			inBackend:     unhealthy,
+			expectedError: pingErr,
+		},
+		{
+			desc:          "Tiered backend checks L2",
+			inBackend:     NewTieredBackend(config.Tiered{L1: config.TieredL1{TTLSeconds: 60}}, unhealthy, m),
+			expectedError: pingErr,
+		},
+		{
+			desc: "Sharded backend checks every shard",
+			inBackend: NewShardedBackend(config.Sharded{VirtualNodes: 160, Shards: []config.Shard{{Name: "a", Weight: 1}, {Name: "b", Weight: 1}}},
+				[]Backend{NewMemoryBackend(config.Memory{}), unhealthy}, m),
+			expectedError: errors.New("shard b: ping failed"),
+		},
+		{
+			desc:          "Migration backend checks the secondary backend",
+			inBackend:     NewMigrationBackend(config.Migration{}, NewMemoryBackend(config.Memory{}), unhealthy, m),
+			expectedError: errors.New("secondary: ping failed"),
+		},
+	}
+
+	for _, test := range testCases {
+		err := HealthCheck(context.Background(), test.inBackend)
+		if test.expectedError == nil {
+			assert.NoError(t, err, test.desc)
+		} else {
+			assert.EqualError(t, err, test.expectedError.Error(), test.desc)
+		}
+	}
+}
+
+func TestHealthMonitor(t *testing.T) {
+	backend := &checkedBackend{err: utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED)}
+	monitor := NewHealthMonitor(backend, "memory", config.HealthCheck{Enabled: true, IntervalSeconds: 60, TimeoutMs: 100})
+
+	assert.Equal(t, "memory", monitor.Name())
+	assert.Equal(t, backend.err, monitor.Err(), "Backend is checked right away")
+
+	backend.err = nil
+	monitor.check()
+	assert.NoError(t, monitor.Err(), "Backend recovered")
+}
@@ -2,11 +2,14 @@ package backends
 
 import (
 	"context"
+	"errors"
+	"net"
 	"time"
 
 	"git.pubmatic.com/PubMatic/go-common/logger"
 	"github.com/google/gomemcache/memcache"
 	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/utils"
 )
 
@@ -16,6 +19,8 @@ import (
 type MemcacheDataStore interface {
 	Get(key string) (*memcache.Item, error)
 	Put(key string, value string, ttlSeconds int) error
+	Delete(key string) error
+	GetMulti(keys []string) (map[string]*memcache.Item, error)
 }
 
 // Memcache Object use to implement MemcacheDataStore interface
@@ -41,14 +46,35 @@ func (mc *Memcache) Put(key string, value string, ttlSeconds int) error {
 	})
 }
 
-// MemcacheBackend implements the Backend interface
+// Delete uses the github.com/bradfitz/gomemcache/memcache library to remove
+// the item stored under 'key'
+func (mc *Memcache) Delete(key string) error {
+	return mc.client.Delete(key)
+}
+
+// GetMulti uses the github.com/bradfitz/gomemcache/memcache library to retrieve
+// the values stored under 'keys' in a single round trip per memcache server. Keys
+// that are not found are absent from the returned map
+func (mc *Memcache) GetMulti(keys []string) (map[string]*memcache.Item, error) {
+	return mc.client.GetMulti(keys)
+}
+
+// MemcacheBackend implements the Backend and BatchGetter interfaces. Memcache has no
+// multi-key equivalent of Add(item *Item), so puts are never batched
 type MemcacheBackend struct {
 	memcache MemcacheDataStore
+	// servers maps keys to the memcache server that stores them so errors can be
+	// attributed to it. It's nil in auto discovery mode, where the client library
+	// keeps the node list to itself
+	servers memcache.ServerSelector
+	metrics *metrics.Metrics
 }
 
 // NewMemcacheBackend creates a new memcache backend and expects a valid
 // 'cfg config.Memcache' argument
-func NewMemcacheBackend(cfg config.Memcache) *MemcacheBackend {
+func NewMemcacheBackend(cfg config.Memcache, metrics *metrics.Metrics) *MemcacheBackend {
+	backend := &MemcacheBackend{metrics: metrics}
+
 	var mc *memcache.Client
 	if cfg.ConfigHost != "" {
 		var err error
@@ -58,25 +84,36 @@ func NewMemcacheBackend(cfg config.Memcache) *MemcacheBackend {
 			panic("Memcache failure. This shouldn't happen.")
 		}
 	} else {
-		mc = memcache.New(cfg.Hosts...)
+		servers := new(memcache.ServerList)
+		if err := servers.SetServers(cfg.Hosts...); err != nil {
+			logger.Fatal("Error resolving config.backend.memcache.hosts: %v", err)
+			panic("Memcache failure. This shouldn't happen.")
+		}
+		mc = memcache.NewFromSelector(servers)
+		backend.servers = servers
 	}
+	mc.Timeout = time.Duration(cfg.TimeoutMillis) * time.Millisecond
+	mc.MaxIdleConns = cfg.MaxIdleConns
 
-	return &MemcacheBackend{
-		memcache: &Memcache{mc},
-	}
+	backend.memcache = &Memcache{mc}
+	return backend
 }
 
 // Get makes the MemcacheDataStore client to retrieve the value that has been previously
 // stored under 'key'. If unseuccessful, returns an empty value and a KeyNotFoundError
 // or other, memcache-related error
 func (mc *MemcacheBackend) Get(ctx context.Context, key string) (string, error) {
-	res, err := mc.memcache.Get(key)
+	var res *memcache.Item
+	err := mc.run(ctx, key, func() (err error) {
+		res, err = mc.memcache.Get(key)
+		return err
+	})
 
 	if err != nil {
 		if err == memcache.ErrCacheMiss {
 			err = utils.NewPBCError(utils.KEY_NOT_FOUND)
 		}
-		return "", err
+		return "", classifyMemcacheContextError(err, utils.GET_DEADLINE_EXCEEDED)
 	}
 
 	return string(res.Value), nil
@@ -85,9 +122,114 @@ func (mc *MemcacheBackend) Get(ctx context.Context, key string) (string, error)
 // Put makes the MemcacheDataStore client to store `value` only if `key` doesn't exist
 // in the storage already. If it does, no operation is performed and Put returns RecordExistsError
 func (mc *MemcacheBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
-	err := mc.memcache.Put(key, value, ttlSeconds)
+	err := mc.run(ctx, key, func() error {
+		return mc.memcache.Put(key, value, ttlSeconds)
+	})
 	if err != nil && err == memcache.ErrNotStored {
 		return utils.NewPBCError(utils.RECORD_EXISTS)
 	}
+	return classifyMemcacheContextError(err, utils.PUT_DEADLINE_EXCEEDED)
+}
+
+// Delete makes the MemcacheDataStore client remove the value stored under `key`. Returns
+// KeyNotFoundError if no such key existed in the storage
+func (mc *MemcacheBackend) Delete(ctx context.Context, key string) error {
+	err := mc.run(ctx, key, func() error {
+		return mc.memcache.Delete(key)
+	})
+	if err == memcache.ErrCacheMiss {
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+	return classifyMemcacheContextError(err, utils.PUT_DEADLINE_EXCEEDED)
+}
+
+// GetBatch makes the MemcacheDataStore client retrieve the values stored under every key
+// at once. Keys that were never stored come with a KeyNotFoundError
+func (mc *MemcacheBackend) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	var items map[string]*memcache.Item
+	err := mc.run(ctx, "", func() (err error) {
+		items, err = mc.memcache.GetMulti(keys)
+		return err
+	})
+	if err != nil {
+		return make([]string, len(keys)), batchError(len(keys), classifyMemcacheContextError(err, utils.GET_DEADLINE_EXCEEDED))
+	}
+
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		if item, found := items[key]; found {
+			values[i] = string(item.Value)
+		} else {
+			errs[i] = utils.NewPBCError(utils.KEY_NOT_FOUND)
+		}
+	}
+	return values, errs
+}
+
+// run calls the memcache client in its own goroutine and stops waiting for it once ctx is
+// done, returning the context error. The client library takes no context, so abandoned
+// calls carry on in the background until config.backend.memcache.timeout_ms expires. Server
+// errors are recorded even then so a failing node shows up in the metrics regardless of
+// the deadlines requests come with
+func (mc *MemcacheBackend) run(ctx context.Context, key string, call func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		err := call()
+		if isMemcacheServerError(err) {
+			mc.metrics.RecordMemcacheServerError(mc.serverOf(key, err))
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// serverOf finds out the address of the memcache server behind err. Network errors carry it,
+// otherwise it's the server key is stored on. Batches span several servers, so they come
+// with an empty key and their errors are attributed to an "unknown" server when no address
+// can be found
+func (mc *MemcacheBackend) serverOf(key string, err error) string {
+	var connectTimeoutErr *memcache.ConnectTimeoutError
+	if errors.As(err, &connectTimeoutErr) && connectTimeoutErr.Addr != nil {
+		return connectTimeoutErr.Addr.String()
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Addr != nil {
+		return opErr.Addr.String()
+	}
+	if mc.servers != nil && key != "" {
+		if addr, err := mc.servers.PickServer(key); err == nil {
+			return addr.String()
+		}
+	}
+	return "unknown"
+}
+
+// isMemcacheServerError tells apart errors caused by a misbehaving or unreachable server from
+// the expected outcomes of a call, such as a cache miss
+func isMemcacheServerError(err error) bool {
+	switch err {
+	case nil, memcache.ErrCacheMiss, memcache.ErrNotStored, memcache.ErrMalformedKey:
+		return false
+	}
+	return true
+}
+
+// classifyMemcacheContextError turns an expired request deadline into the given PBCError type
+// so clients get told the backend timed out
+func classifyMemcacheContextError(err error, timeoutErrType int) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return utils.NewPBCError(timeoutErrType)
+	}
 	return err
 }
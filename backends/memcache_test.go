@@ -3,15 +3,26 @@ package backends
 import (
 	"context"
 	"errors"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/google/gomemcache/memcache"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
 	"github.com/prebid/prebid-cache/utils"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMemcacheGet(t *testing.T) {
-	mcBackend := &MemcacheBackend{}
+	mockMetrics := metricstest.CreateMockMetrics()
+	mcBackend := &MemcacheBackend{
+		metrics: &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		},
+	}
 
 	type testInput struct {
 		memcacheClient MemcacheDataStore
@@ -76,7 +87,14 @@ func TestMemcacheGet(t *testing.T) {
 }
 
 func TestMemcachePut(t *testing.T) {
-	mcBackend := &MemcacheBackend{}
+	mockMetrics := metricstest.CreateMockMetrics()
+	mcBackend := &MemcacheBackend{
+		metrics: &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		},
+	}
 
 	type testInput struct {
 		memcacheClient MemcacheDataStore
@@ -154,3 +172,252 @@ func TestMemcachePut(t *testing.T) {
 		}
 	}
 }
+
+func TestMemcacheDelete(t *testing.T) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	mcBackend := &MemcacheBackend{
+		metrics: &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc           string
+		memcacheClient MemcacheDataStore
+		expectedErr    error
+	}{
+		{
+			desc:           "Memcache.Delete() throws a memcache.ErrCacheMiss error",
+			memcacheClient: &ErrorProneMemcache{ServerError: memcache.ErrCacheMiss},
+			expectedErr:    utils.NewPBCError(utils.KEY_NOT_FOUND),
+		},
+		{
+			desc:           "Memcache.Delete() throws an error different from memcache.ErrCacheMiss",
+			memcacheClient: &ErrorProneMemcache{ServerError: errors.New("some other delete error")},
+			expectedErr:    errors.New("some other delete error"),
+		},
+		{
+			desc:           "Memcache.Delete() times out, expect a PUT_DEADLINE_EXCEEDED error",
+			memcacheClient: &ErrorProneMemcache{ServerError: context.DeadlineExceeded},
+			expectedErr:    utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+		},
+		{
+			desc:           "Memcache.Delete() doesn't throw an error",
+			memcacheClient: &GoodMemcache{StoredData: map[string]string{"defaultKey": "aValue"}},
+			expectedErr:    nil,
+		},
+	}
+
+	for _, tt := range testCases {
+		mcBackend.memcache = tt.memcacheClient
+
+		// Run test
+		actualErr := mcBackend.Delete(context.Background(), "defaultKey")
+
+		// Assertions
+		assert.Equal(t, tt.expectedErr, actualErr, tt.desc)
+	}
+}
+
+func TestMemcacheGetBatch(t *testing.T) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	mcBackend := &MemcacheBackend{
+		metrics: &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc           string
+		memcacheClient MemcacheDataStore
+		expectedValues []string
+		expectedErrors []error
+	}{
+		{
+			desc:           "Memcache.GetMulti() throws an error. Expect it for every key",
+			memcacheClient: &ErrorProneMemcache{ServerError: errors.New("some get error")},
+			expectedValues: []string{"", ""},
+			expectedErrors: []error{errors.New("some get error"), errors.New("some get error")},
+		},
+		{
+			desc:           "Memcache.GetMulti() doesn't find one of the keys. Expect a KEY_NOT_FOUND error for it",
+			memcacheClient: &GoodMemcache{StoredData: map[string]string{"key1": "value1"}},
+			expectedValues: []string{"value1", ""},
+			expectedErrors: []error{nil, utils.NewPBCError(utils.KEY_NOT_FOUND)},
+		},
+	}
+
+	for _, tt := range testCases {
+		mcBackend.memcache = tt.memcacheClient
+
+		// Run test
+		values, errs := mcBackend.GetBatch(context.Background(), []string{"key1", "key2"})
+
+		// Assertions
+		assert.Equal(t, tt.expectedValues, values, tt.desc)
+		assert.Equal(t, tt.expectedErrors, errs, tt.desc)
+	}
+}
+
+func TestMemcacheContext(t *testing.T) {
+	expiredCtx, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		desc              string
+		inCtx             context.Context
+		inTimeout         time.Duration
+		expectedGetErr    error
+		expectedPutErr    error
+		expectedDeleteErr error
+		expectedBatchErrs []error
+	}{
+		{
+			desc:              "Deadline expired before calling memcache",
+			inCtx:             expiredCtx,
+			expectedGetErr:    utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED),
+			expectedPutErr:    utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+			expectedDeleteErr: utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+			expectedBatchErrs: []error{utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED), utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED)},
+		},
+		{
+			desc:              "Context canceled before calling memcache",
+			inCtx:             canceledCtx,
+			expectedGetErr:    context.Canceled,
+			expectedPutErr:    context.Canceled,
+			expectedDeleteErr: context.Canceled,
+			expectedBatchErrs: []error{context.Canceled, context.Canceled},
+		},
+		{
+			desc:              "Deadline expires while memcache doesn't answer",
+			inCtx:             context.Background(),
+			inTimeout:         10 * time.Millisecond,
+			expectedGetErr:    utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED),
+			expectedPutErr:    utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+			expectedDeleteErr: utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+			expectedBatchErrs: []error{utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED), utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED)},
+		},
+	}
+
+	for _, test := range testCases {
+		mockMetrics := metricstest.CreateMockMetrics()
+		client := &UnresponsiveMemcache{Release: make(chan struct{})}
+		mcBackend := &MemcacheBackend{
+			memcache: client,
+			metrics: &metrics.Metrics{
+				MetricEngines: []metrics.CacheMetrics{
+					&mockMetrics,
+				},
+			},
+		}
+		newCtx := func() (context.Context, context.CancelFunc) {
+			if test.inTimeout > 0 {
+				return context.WithTimeout(test.inCtx, test.inTimeout)
+			}
+			return context.WithCancel(test.inCtx)
+		}
+
+		ctx, cancel := newCtx()
+		_, err := mcBackend.Get(ctx, "key1")
+		cancel()
+		assert.Equal(t, test.expectedGetErr, err, "%s: Get", test.desc)
+
+		ctx, cancel = newCtx()
+		err = mcBackend.Put(ctx, "key1", "value", 10)
+		cancel()
+		assert.Equal(t, test.expectedPutErr, err, "%s: Put", test.desc)
+
+		ctx, cancel = newCtx()
+		err = mcBackend.Delete(ctx, "key1")
+		cancel()
+		assert.Equal(t, test.expectedDeleteErr, err, "%s: Delete", test.desc)
+
+		ctx, cancel = newCtx()
+		values, errs := mcBackend.GetBatch(ctx, []string{"key1", "key2"})
+		cancel()
+		assert.Equal(t, []string{"", ""}, values, "%s: GetBatch", test.desc)
+		assert.Equal(t, test.expectedBatchErrs, errs, "%s: GetBatch", test.desc)
+
+		close(client.Release)
+	}
+}
+
+func TestMemcacheServerErrors(t *testing.T) {
+	servers := new(memcache.ServerList)
+	assert.NoError(t, servers.SetServers("10.0.0.1:11211"))
+	serverAddr := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 11211}
+
+	testCases := []struct {
+		desc             string
+		inServerError    error
+		inServers        memcache.ServerSelector
+		expectedServer   string
+		expectedRecorded bool
+	}{
+		{
+			desc:          "Cache misses aren't server errors",
+			inServerError: memcache.ErrCacheMiss,
+			inServers:     servers,
+		},
+		{
+			desc:          "Keys that already exist aren't server errors",
+			inServerError: memcache.ErrNotStored,
+			inServers:     servers,
+		},
+		{
+			desc:             "Connect timeouts are attributed to the server they name",
+			inServerError:    &memcache.ConnectTimeoutError{Addr: serverAddr},
+			inServers:        servers,
+			expectedServer:   "10.0.0.2:11211",
+			expectedRecorded: true,
+		},
+		{
+			desc:             "Network errors are attributed to the server they name",
+			inServerError:    &net.OpError{Op: "read", Net: "tcp", Addr: serverAddr, Err: errors.New("i/o timeout")},
+			inServers:        servers,
+			expectedServer:   "10.0.0.2:11211",
+			expectedRecorded: true,
+		},
+		{
+			desc:             "Other errors are attributed to the server that stores the key",
+			inServerError:    memcache.ErrServerError,
+			inServers:        servers,
+			expectedServer:   "10.0.0.1:11211",
+			expectedRecorded: true,
+		},
+		{
+			desc:             "Other errors in auto discovery mode are attributed to an unknown server",
+			inServerError:    memcache.ErrServerError,
+			expectedServer:   "unknown",
+			expectedRecorded: true,
+		},
+	}
+
+	for _, test := range testCases {
+		mockMetrics := metricstest.CreateMockMetrics()
+		mcBackend := &MemcacheBackend{
+			memcache: &ErrorProneMemcache{ServerError: test.inServerError},
+			servers:  test.inServers,
+			metrics: &metrics.Metrics{
+				MetricEngines: []metrics.CacheMetrics{
+					&mockMetrics,
+				},
+			},
+		}
+
+		mcBackend.Get(context.Background(), "key1")
+
+		if test.expectedRecorded {
+			assert.Equal(t, test.expectedServer, mcBackend.serverOf("key1", test.inServerError), test.desc)
+			metricstest.AssertMetrics(t, []string{"RecordMemcacheServerError"}, mockMetrics)
+		} else {
+			metricstest.AssertMetrics(t, []string{}, mockMetrics)
+		}
+	}
+}
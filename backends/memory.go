@@ -1,49 +1,291 @@
 package backends
 
 import (
+	"container/list"
 	"context"
+	"fmt"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"git.pubmatic.com/PubMatic/go-common/logger"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/utils"
 )
 
 // MemoryBackend stores information in the local memory heap. Stored data dissapears upon
-// Prebid Cache restart
+// Prebid Cache restart. Entries expire according to the TTL they were stored with and, if
+// configured, the least recently used entries get evicted once the item count or byte
+// limits are reached.
+//
+// The keyspace is split into independently locked shards so concurrent requests for
+// different keys don't contend on the same mutex. Item and byte limits apply to the backend
+// as a whole: a shard that takes the backend over a limit evicts its own least recently
+// used entries first, which makes eviction approximately, rather than strictly, least
+// recently used.
 type MemoryBackend struct {
-	db map[string]string
-	mu sync.Mutex
+	shards    []*memoryShard
+	usage     *memoryUsage
+	now       func() time.Time
+	stop      chan struct{}
+	closeOnce sync.Once
 }
 
-// Get retrieves from the local memory and uses a mutex lock to aviod data race scenarios
+// memoryShard holds a portion of the MemoryBackend keyspace along with its own LRU list
+type memoryShard struct {
+	db    map[string]*list.Element
+	lru   *list.List
+	usage *memoryUsage
+	mu    sync.Mutex
+}
+
+// memoryUsage keeps track of the items and bytes stored across all the shards of a
+// MemoryBackend along with the configured limits
+type memoryUsage struct {
+	maxItems int64
+	maxBytes int64
+	items    atomic.Int64
+	bytes    atomic.Int64
+}
+
+// exceeded returns true if either the item count or the byte limit is currently exceeded
+func (u *memoryUsage) exceeded() bool {
+	overItems := u.maxItems > 0 && u.items.Load() > u.maxItems
+	overBytes := u.maxBytes > 0 && u.bytes.Load() > u.maxBytes
+	return overItems || overBytes
+}
+
+// memoryEntry is the element stored in the memoryShard LRU list
+type memoryEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+// expired returns true if the entry was stored with a TTL that has already elapsed
+func (e *memoryEntry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// size is the number of bytes accounted against config.backend.memory.max_bytes
+func (e *memoryEntry) size() int {
+	return len(e.key) + len(e.value)
+}
+
+// Get retrieves from the local memory and locks the shard that owns key to aviod data
+// race scenarios. Expired entries are removed and reported as not found
 func (b *MemoryBackend) Get(ctx context.Context, key string) (string, error) {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+	return b.shardFor(key).get(key, b.now())
+}
+
+// Put stores data in local memory and locks the shard that owns key to aviod data race
+// scenarios. A positive ttlSeconds value sets the time after which the entry will no
+// longer be served
+func (b *MemoryBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	shard := b.shardFor(key)
+	if err := shard.put(key, value, ttlSeconds, b.now()); err != nil {
+		return err
+	}
+
+	// The shard only evicts its own entries and never the one just stored, so if that
+	// wasn't enough to honor the limits, keep evicting from the other shards
+	for _, other := range b.shards {
+		if !b.usage.exceeded() {
+			break
+		}
+		if other != shard {
+			other.evict(0)
+		}
+	}
+	return nil
+}
+
+// Delete removes the entry stored under key from local memory. Returns a KEY_NOT_FOUND
+// error if no such entry exists or if it already expired
+func (b *MemoryBackend) Delete(ctx context.Context, key string) error {
+	return b.shardFor(key).delete(key, b.now())
+}
+
+// shardFor hashes key using 32-bit FNV-1a in order to select the shard that owns it
+func (b *MemoryBackend) shardFor(key string) *memoryShard {
+	if len(b.shards) == 1 {
+		return b.shards[0]
+	}
+
+	var hash uint32 = 2166136261
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return b.shards[hash%uint32(len(b.shards))]
+}
+
+// sweep removes every expired entry from every shard and returns how many were removed
+func (b *MemoryBackend) sweep() int {
+	now := b.now()
+	removed := 0
+	for _, shard := range b.shards {
+		removed += shard.sweep(now)
+	}
+	return removed
+}
+
+// runSweeper periodically removes expired entries so memory gets released even if the
+// expired keys are never requested again. Returns once Close gets called
+func (b *MemoryBackend) runSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+			if removed := b.sweep(); removed > 0 {
+				logger.Debug("Memory backend sweeper removed %d expired entries", removed)
+			}
+		}
+	}
+}
+
+// Close stops the background sweeper, if any. Stored entries remain readable
+func (b *MemoryBackend) Close() {
+	b.closeOnce.Do(func() { close(b.stop) })
+}
 
-	v, ok := b.db[key]
+func (s *memoryShard) get(key string, now time.Time) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.db[key]
 	if !ok {
 		return "", utils.NewPBCError(utils.KEY_NOT_FOUND)
 	}
 
-	return v, nil
+	entry := elem.Value.(*memoryEntry)
+	if entry.expired(now) {
+		s.removeElement(elem)
+		return "", utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+
+	s.lru.MoveToFront(elem)
+	return entry.value, nil
 }
 
-// Put stores data in local memory and uses a mutex lock to aviod data race scenarios
-func (b *MemoryBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
-	b.mu.Lock()
-	defer b.mu.Unlock()
+func (s *memoryShard) put(key string, value string, ttlSeconds int, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
 	// If the record already exists, don't write and throw error
-	if _, ok := b.db[key]; ok {
-		return utils.NewPBCError(utils.RECORD_EXISTS)
+	if elem, ok := s.db[key]; ok {
+		if !elem.Value.(*memoryEntry).expired(now) {
+			return utils.NewPBCError(utils.RECORD_EXISTS)
+		}
+		s.removeElement(elem)
+	}
+
+	entry := &memoryEntry{key: key, value: value}
+	if ttlSeconds > 0 {
+		entry.expiresAt = now.Add(time.Duration(ttlSeconds) * time.Second)
+	}
+
+	if s.usage.maxBytes > 0 && int64(entry.size()) > s.usage.maxBytes {
+		return utils.NewPBCError(utils.BAD_PAYLOAD_SIZE, fmt.Sprintf("Payload size %d exceeded memory backend max_bytes %d", entry.size(), s.usage.maxBytes))
 	}
 
-	b.db[key] = value
+	s.db[key] = s.lru.PushFront(entry)
+	s.usage.items.Add(1)
+	s.usage.bytes.Add(int64(entry.size()))
+	s.evictLocked(1)
+
+	return nil
+}
+
+func (s *memoryShard) delete(key string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.db[key]
+	if !ok {
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+
+	expired := elem.Value.(*memoryEntry).expired(now)
+	s.removeElement(elem)
+	if expired {
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
 	return nil
 }
 
-// NewMemoryBackend instances a MemoryBackend struct
-func NewMemoryBackend() *MemoryBackend {
-	return &MemoryBackend{
-		db: make(map[string]string),
+// evict removes the least recently used entries of the shard until the backend honors both
+// the item count and byte limits or only keep entries are left
+func (s *memoryShard) evict(keep int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictLocked(keep)
+}
+
+// evictLocked is evict for callers that already hold s.mu
+func (s *memoryShard) evictLocked(keep int) {
+	for s.lru.Len() > keep && s.usage.exceeded() {
+		s.removeElement(s.lru.Back())
+	}
+}
+
+// removeElement deletes elem from both the map and the LRU list. Must be called with s.mu held
+func (s *memoryShard) removeElement(elem *list.Element) {
+	entry := s.lru.Remove(elem).(*memoryEntry)
+	delete(s.db, entry.key)
+	s.usage.items.Add(-1)
+	s.usage.bytes.Add(-int64(entry.size()))
+}
+
+func (s *memoryShard) sweep(now time.Time) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	removed := 0
+	for elem := s.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if elem.Value.(*memoryEntry).expired(now) {
+			s.removeElement(elem)
+			removed++
+		}
+		elem = prev
 	}
+	return removed
+}
+
+// NewMemoryBackend instances a MemoryBackend struct. Zero values in config.Memory leave the
+// corresponding limit disabled. If cfg.Shards is not positive, utils.MEMORY_DEFAULT_SHARDS
+// shards are used. The sweeper started for a positive cfg.SweepIntervalSeconds runs until
+// Close gets called
+func NewMemoryBackend(cfg config.Memory) *MemoryBackend {
+	numShards := cfg.Shards
+	if numShards <= 0 {
+		numShards = utils.MEMORY_DEFAULT_SHARDS
+	}
+
+	usage := &memoryUsage{maxItems: int64(cfg.MaxItems), maxBytes: int64(cfg.MaxBytes)}
+	backend := &MemoryBackend{
+		shards: make([]*memoryShard, numShards),
+		usage:  usage,
+		now:    time.Now,
+		stop:   make(chan struct{}),
+	}
+	for i := range backend.shards {
+		backend.shards[i] = &memoryShard{
+			db:    make(map[string]*list.Element),
+			lru:   list.New(),
+			usage: usage,
+		}
+	}
+
+	if cfg.SweepIntervalSeconds > 0 {
+		go backend.runSweeper(time.Duration(cfg.SweepIntervalSeconds) * time.Second)
+	}
+
+	return backend
 }
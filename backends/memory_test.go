@@ -2,8 +2,15 @@ package backends
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -31,7 +38,7 @@ func TestMemoryBackend(t *testing.T) {
 			[]aTest{
 				{
 					desc:    "succesful put",
-					backend: NewMemoryBackend(),
+					backend: NewMemoryBackend(config.Memory{}),
 					setup:   func(b *MemoryBackend) {},
 					run: func(b *MemoryBackend) (string, error) {
 						err := b.Put(context.Background(), "someKey", "someValye", 0)
@@ -41,7 +48,7 @@ func TestMemoryBackend(t *testing.T) {
 				},
 				{
 					desc:    "Put returns a RecordExistsError",
-					backend: NewMemoryBackend(),
+					backend: NewMemoryBackend(config.Memory{}),
 					setup: func(b *MemoryBackend) {
 						b.Put(context.Background(), "someKey", "someValue", 0)
 					},
@@ -58,7 +65,7 @@ func TestMemoryBackend(t *testing.T) {
 			[]aTest{
 				{
 					desc:    "succesful get",
-					backend: NewMemoryBackend(),
+					backend: NewMemoryBackend(config.Memory{}),
 					setup: func(b *MemoryBackend) {
 						b.Put(context.Background(), "someKey", "someValue", 0)
 					},
@@ -69,7 +76,7 @@ func TestMemoryBackend(t *testing.T) {
 				},
 				{
 					desc:    "Get returns a Key not found error",
-					backend: NewMemoryBackend(),
+					backend: NewMemoryBackend(config.Memory{}),
 					setup: func(b *MemoryBackend) {
 						b.Put(context.Background(), "someKey", "someValue", 0)
 					},
@@ -96,3 +103,317 @@ func TestMemoryBackend(t *testing.T) {
 		}
 	}
 }
+
+func TestMemoryBackendExpiration(t *testing.T) {
+	now := time.Now()
+	backend := NewMemoryBackend(config.Memory{})
+	backend.now = func() time.Time { return now }
+
+	assert.NoError(t, backend.Put(context.Background(), "expiringKey", "someValue", 10))
+	assert.NoError(t, backend.Put(context.Background(), "noTTLKey", "someValue", 0))
+
+	value, err := backend.Get(context.Background(), "expiringKey")
+	assert.NoError(t, err, "Entry should be served before its TTL elapses")
+	assert.Equal(t, "someValue", value)
+
+	now = now.Add(10 * time.Second)
+
+	_, err = backend.Get(context.Background(), "expiringKey")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Entry should not be served once its TTL elapsed")
+
+	value, err = backend.Get(context.Background(), "noTTLKey")
+	assert.NoError(t, err, "Entry stored without TTL should not expire")
+	assert.Equal(t, "someValue", value)
+
+	assert.NoError(t, backend.Put(context.Background(), "expiringKey", "anotherValue", 10), "Expired key should be writable again")
+}
+
+func TestMemoryBackendEviction(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		cfg             config.Memory
+		expectedPresent []string
+		expectedEvicted []string
+	}{
+		{
+			desc:            "No limits, nothing gets evicted",
+			cfg:             config.Memory{Shards: 1},
+			expectedPresent: []string{"k1", "k2", "k3", "k4"},
+		},
+		{
+			desc:            "Item limit, least recently used entries get evicted",
+			cfg:             config.Memory{MaxItems: 3, Shards: 1},
+			expectedPresent: []string{"k1", "k3", "k4"},
+			expectedEvicted: []string{"k2"},
+		},
+		{
+			desc:            "Byte limit, least recently used entries get evicted",
+			cfg:             config.Memory{MaxBytes: 21, Shards: 1},
+			expectedPresent: []string{"k1", "k3", "k4"},
+			expectedEvicted: []string{"k2"},
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := NewMemoryBackend(tc.cfg)
+
+		// Each entry accounts for 7 bytes: a 2 byte key plus a 5 byte value
+		assert.NoError(t, backend.Put(context.Background(), "k1", "value", 0), tc.desc)
+		assert.NoError(t, backend.Put(context.Background(), "k2", "value", 0), tc.desc)
+		assert.NoError(t, backend.Put(context.Background(), "k3", "value", 0), tc.desc)
+
+		// Reading k1 makes k2 the least recently used entry
+		backend.Get(context.Background(), "k1")
+		assert.NoError(t, backend.Put(context.Background(), "k4", "value", 0), tc.desc)
+
+		for _, key := range tc.expectedPresent {
+			_, err := backend.Get(context.Background(), key)
+			assert.NoError(t, err, "%s - %s should be present", tc.desc, key)
+		}
+		for _, key := range tc.expectedEvicted {
+			_, err := backend.Get(context.Background(), key)
+			assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "%s - %s should have been evicted", tc.desc, key)
+		}
+	}
+}
+
+func TestMemoryBackendValueLargerThanMaxBytes(t *testing.T) {
+	backend := NewMemoryBackend(config.Memory{MaxBytes: 5, Shards: 1})
+
+	err := backend.Put(context.Background(), "someKey", "someValue", 0)
+
+	pbcErr, isPBCErr := err.(utils.PBCError)
+	assert.True(t, isPBCErr, "Expected a PBCError")
+	assert.Equal(t, utils.BAD_PAYLOAD_SIZE, pbcErr.Type)
+	assert.Equal(t, 0, backend.shards[0].lru.Len(), "Nothing should have been stored")
+}
+
+func TestMemoryBackendSweep(t *testing.T) {
+	now := time.Now()
+	backend := NewMemoryBackend(config.Memory{Shards: 1})
+	backend.now = func() time.Time { return now }
+
+	backend.Put(context.Background(), "shortTTL", "someValue", 5)
+	backend.Put(context.Background(), "longTTL", "someValue", 50)
+	backend.Put(context.Background(), "noTTL", "someValue", 0)
+
+	now = now.Add(10 * time.Second)
+
+	assert.Equal(t, 1, backend.sweep())
+	assert.Equal(t, 2, backend.shards[0].lru.Len())
+	assert.Equal(t, int64(len("longTTL")+len("noTTL")+2*len("someValue")), backend.usage.bytes.Load())
+	_, found := backend.shards[0].db["shortTTL"]
+	assert.False(t, found, "Expired entry should have been swept")
+}
+
+func TestMemoryBackendShards(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		cfg            config.Memory
+		expectedShards int
+	}{
+		{
+			desc:           "Shards not set, use default",
+			cfg:            config.Memory{},
+			expectedShards: utils.MEMORY_DEFAULT_SHARDS,
+		},
+		{
+			desc:           "Shards set",
+			cfg:            config.Memory{Shards: 4},
+			expectedShards: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := NewMemoryBackend(tc.cfg)
+
+		assert.Len(t, backend.shards, tc.expectedShards, tc.desc)
+	}
+}
+
+func TestMemoryBackendLimitsSpanAllShards(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		cfg           config.Memory
+		valueSize     int
+		puts          int
+		expectedItems int64
+	}{
+		{
+			desc:          "Item limit lower than the shard count",
+			cfg:           config.Memory{MaxItems: 10},
+			valueSize:     10,
+			puts:          100,
+			expectedItems: 10,
+		},
+		{
+			desc:          "Entries larger than an even share of max_bytes still get stored",
+			cfg:           config.Memory{MaxBytes: 1024 * 1024},
+			valueSize:     40 * 1024,
+			puts:          1,
+			expectedItems: 1,
+		},
+		{
+			desc:          "Byte limit gets honored across shards",
+			cfg:           config.Memory{MaxBytes: 1024 * 1024},
+			valueSize:     40 * 1024,
+			puts:          100,
+			expectedItems: 25,
+		},
+	}
+
+	for _, tc := range testCases {
+		backend := NewMemoryBackend(tc.cfg)
+		value := strings.Repeat("v", tc.valueSize)
+
+		for i := 0; i < tc.puts; i++ {
+			assert.NoError(t, backend.Put(context.Background(), fmt.Sprintf("key%03d", i), value, 0), tc.desc)
+		}
+
+		assert.Equal(t, tc.expectedItems, backend.usage.items.Load(), tc.desc)
+		stored := 0
+		for _, shard := range backend.shards {
+			stored += shard.lru.Len()
+		}
+		assert.Equal(t, int(tc.expectedItems), stored, tc.desc)
+		if tc.cfg.MaxBytes > 0 {
+			assert.LessOrEqual(t, backend.usage.bytes.Load(), int64(tc.cfg.MaxBytes), tc.desc)
+		}
+	}
+}
+
+func TestMemoryBackendClose(t *testing.T) {
+	backend := NewMemoryBackend(config.Memory{SweepIntervalSeconds: 1})
+
+	backend.Close()
+	backend.Close()
+
+	select {
+	case <-backend.stop:
+	default:
+		assert.Fail(t, "Close should have stopped the sweeper")
+	}
+}
+
+func TestMemoryBackendConcurrentAccess(t *testing.T) {
+	backend := NewMemoryBackend(config.Memory{Shards: 8})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := strconv.Itoa(i)
+			assert.NoError(t, backend.Put(context.Background(), key, "value"+key, 0))
+
+			value, err := backend.Get(context.Background(), key)
+			assert.NoError(t, err)
+			assert.Equal(t, "value"+key, value)
+		}(i)
+	}
+	wg.Wait()
+
+	usedShards := 0
+	for _, shard := range backend.shards {
+		if shard.lru.Len() > 0 {
+			usedShards++
+		}
+	}
+	assert.Greater(t, usedShards, 1, "Keys should be spread across shards")
+}
+
+// singleLockMemoryBackend replicates the MemoryBackend implementation prior to sharding, where
+// a single mutex guards the whole map. It is only used as a benchmarking baseline.
+type singleLockMemoryBackend struct {
+	db map[string]string
+	mu sync.Mutex
+}
+
+func (b *singleLockMemoryBackend) Get(ctx context.Context, key string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	v, ok := b.db[key]
+	if !ok {
+		return "", utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+	return v, nil
+}
+
+func (b *singleLockMemoryBackend) Delete(ctx context.Context, key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.db[key]; !ok {
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+	delete(b.db, key)
+	return nil
+}
+
+func (b *singleLockMemoryBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.db[key]; ok {
+		return utils.NewPBCError(utils.RECORD_EXISTS)
+	}
+	b.db[key] = value
+	return nil
+}
+
+// benchmarkParallelGetPut runs a read-heavy workload, nine Gets for every Put, from
+// GOMAXPROCS goroutines
+func benchmarkParallelGetPut(b *testing.B, backend Backend) {
+	const numKeys = 10000
+	keys := make([]string, numKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%036d", i)
+		backend.Put(context.Background(), keys[i], "xml<vast></vast>", 0)
+	}
+
+	var counter uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(atomic.AddUint64(&counter, 1) * 7919)
+		for pb.Next() {
+			i++
+			if i%10 == 0 {
+				backend.Put(context.Background(), fmt.Sprintf("%036d", numKeys+i), "xml<vast></vast>", 0)
+			} else {
+				backend.Get(context.Background(), keys[i%numKeys])
+			}
+		}
+	})
+}
+
+func BenchmarkSingleLockMemoryBackendParallel(b *testing.B) {
+	benchmarkParallelGetPut(b, &singleLockMemoryBackend{db: make(map[string]string)})
+}
+
+func BenchmarkMemoryBackendOneShardParallel(b *testing.B) {
+	benchmarkParallelGetPut(b, NewMemoryBackend(config.Memory{Shards: 1}))
+}
+
+func BenchmarkMemoryBackendShardedParallel(b *testing.B) {
+	benchmarkParallelGetPut(b, NewMemoryBackend(config.Memory{Shards: utils.MEMORY_DEFAULT_SHARDS}))
+}
+
+func TestMemoryBackendDelete(t *testing.T) {
+	now := time.Now()
+	backend := NewMemoryBackend(config.Memory{})
+	backend.now = func() time.Time { return now }
+
+	backend.Put(context.Background(), "someKey", "someValue", 0)
+	backend.Put(context.Background(), "expiringKey", "someValue", 5)
+
+	assert.NoError(t, backend.Delete(context.Background(), "someKey"))
+	_, err := backend.Get(context.Background(), "someKey")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Deleted entry should no longer be served")
+	assert.NoError(t, backend.Put(context.Background(), "someKey", "anotherValue", 0), "Deleted key should be writable again")
+
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), backend.Delete(context.Background(), "unknownKey"))
+
+	now = now.Add(5 * time.Second)
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), backend.Delete(context.Background(), "expiringKey"), "Expired entries count as not found")
+}
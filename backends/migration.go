@@ -0,0 +1,162 @@
+package backends
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+)
+
+// MigrationBackend moves Prebid Cache from one storage service to another without losing the
+// values stored while the move takes place. Reads go to the primary backend and fall back to
+// the secondary one for the keys the primary doesn't have. Depending on the write mode, values
+// the primary backend stored are also written to the secondary one, which keeps the backend
+// being migrated away from complete until the primary is switched over.
+//
+// The primary backend is the source of truth: its outcome is the one returned, while
+// secondary backend errors are only recorded in the metrics.
+type MigrationBackend struct {
+	primary   Backend
+	secondary Backend
+	writeMode config.MigrationWriteMode
+	metrics   *metrics.Metrics
+}
+
+// NewMigrationBackend creates a MigrationBackend that writes according to cfg.WriteMode.
+// primary and secondary must have been built from the backend sections cfg.Primary.Backend
+// and cfg.Secondary.Backend
+func NewMigrationBackend(cfg config.Migration, primary Backend, secondary Backend, metrics *metrics.Metrics) *MigrationBackend {
+	return &MigrationBackend{
+		primary:   primary,
+		secondary: secondary,
+		writeMode: cfg.WriteMode,
+		metrics:   metrics,
+	}
+}
+
+// Get reads key from the primary backend and, if it's not found there, from the secondary one
+func (b *MigrationBackend) Get(ctx context.Context, key string) (string, error) {
+	value, err := b.primary.Get(ctx, key)
+	if err == nil {
+		b.metrics.RecordMigrationHit(config.MigrationPrimary)
+		return value, nil
+	}
+	b.recordError(config.MigrationPrimary, err)
+	if !isKeyNotFound(err) {
+		return "", err
+	}
+
+	value, err = b.secondary.Get(ctx, key)
+	if err == nil {
+		b.metrics.RecordMigrationHit(config.MigrationSecondary)
+		return value, nil
+	}
+	b.recordError(config.MigrationSecondary, err)
+	return "", err
+}
+
+// Put writes value to the primary backend and, in "both" write mode, to the secondary one if
+// the primary stored it
+func (b *MigrationBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	if err := b.primary.Put(ctx, key, value, ttlSeconds); err != nil {
+		b.recordError(config.MigrationPrimary, err)
+		return err
+	}
+
+	if b.writeMode == config.MigrationWriteBoth {
+		b.recordError(config.MigrationSecondary, b.secondary.Put(ctx, key, value, ttlSeconds))
+	}
+	return nil
+}
+
+// Delete removes key from both backends regardless of the write mode, so that a value deleted
+// from the primary backend isn't read back from the secondary one. Returns what the secondary
+// backend reports when the primary one didn't have the key
+func (b *MigrationBackend) Delete(ctx context.Context, key string) error {
+	primaryErr := b.primary.Delete(ctx, key)
+	b.recordError(config.MigrationPrimary, primaryErr)
+
+	secondaryErr := b.secondary.Delete(ctx, key)
+	b.recordError(config.MigrationSecondary, secondaryErr)
+
+	if isKeyNotFound(primaryErr) {
+		return secondaryErr
+	}
+	return primaryErr
+}
+
+// GetBatch reads every key from the primary backend and requests the ones it doesn't have from
+// the secondary backend together
+func (b *MigrationBackend) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	values, errs := GetBatch(ctx, b.primary, keys)
+
+	var fallbackKeys []string
+	var fallbackIndexes []int
+	for i, err := range errs {
+		if err == nil {
+			b.metrics.RecordMigrationHit(config.MigrationPrimary)
+			continue
+		}
+		b.recordError(config.MigrationPrimary, err)
+		if isKeyNotFound(err) {
+			fallbackKeys = append(fallbackKeys, keys[i])
+			fallbackIndexes = append(fallbackIndexes, i)
+		}
+	}
+	if len(fallbackKeys) == 0 {
+		return values, errs
+	}
+
+	fallbackValues, fallbackErrs := GetBatch(ctx, b.secondary, fallbackKeys)
+	for j, i := range fallbackIndexes {
+		values[i], errs[i] = fallbackValues[j], fallbackErrs[j]
+		if errs[i] == nil {
+			b.metrics.RecordMigrationHit(config.MigrationSecondary)
+		} else {
+			b.recordError(config.MigrationSecondary, errs[i])
+		}
+	}
+	return values, errs
+}
+
+// PutBatch writes every item to the primary backend and, in "both" write mode, the items it
+// stored to the secondary backend
+func (b *MigrationBackend) PutBatch(ctx context.Context, items []BatchPutItem) []error {
+	errs := PutBatch(ctx, b.primary, items)
+
+	var stored []BatchPutItem
+	for i, err := range errs {
+		if err == nil {
+			stored = append(stored, items[i])
+		} else {
+			b.recordError(config.MigrationPrimary, err)
+		}
+	}
+
+	if b.writeMode == config.MigrationWriteBoth && len(stored) > 0 {
+		for _, err := range PutBatch(ctx, b.secondary, stored) {
+			b.recordError(config.MigrationSecondary, err)
+		}
+	}
+	return errs
+}
+
+// HealthCheck checks both backends, since values are read from both of them
+func (b *MigrationBackend) HealthCheck(ctx context.Context) error {
+	if err := HealthCheck(ctx, b.primary); err != nil {
+		return fmt.Errorf("%s: %w", config.MigrationPrimary, err)
+	}
+	if err := HealthCheck(ctx, b.secondary); err != nil {
+		return fmt.Errorf("%s: %w", config.MigrationSecondary, err)
+	}
+	return nil
+}
+
+// recordError counts err against side unless it's an expected outcome, such as a key that
+// wasn't found or that already existed
+func (b *MigrationBackend) recordError(side config.MigrationSideName, err error) {
+	if err != nil && !isKeyNotFound(err) && !isRecordExists(err) {
+		b.metrics.RecordMigrationError(side)
+	}
+}
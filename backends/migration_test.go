@@ -0,0 +1,210 @@
+package backends
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMigrationBackend(writeMode config.MigrationWriteMode, primary Backend, secondary Backend) (*MigrationBackend, *metricstest.MockMetrics) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	migration := NewMigrationBackend(config.Migration{WriteMode: writeMode}, primary, secondary, &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	})
+	return migration, &mockMetrics
+}
+
+func TestMigrationGet(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		inPrimary       func() Backend
+		inSecondary     func() Backend
+		expectedValue   string
+		expectedErr     error
+		expectedMetrics []string
+	}{
+		{
+			desc: "Value found in the primary backend",
+			inPrimary: func() Backend {
+				backend, _ := NewMemoryBackendWithValues(map[string]string{"key": "primary value"})
+				return backend
+			},
+			inSecondary: func() Backend {
+				backend, _ := NewMemoryBackendWithValues(map[string]string{"key": "secondary value"})
+				return backend
+			},
+			expectedValue:   "primary value",
+			expectedMetrics: []string{"RecordMigrationHit"},
+		},
+		{
+			desc:      "Value only found in the secondary backend",
+			inPrimary: func() Backend { return NewMemoryBackend(config.Memory{}) },
+			inSecondary: func() Backend {
+				backend, _ := NewMemoryBackendWithValues(map[string]string{"key": "secondary value"})
+				return backend
+			},
+			expectedValue:   "secondary value",
+			expectedMetrics: []string{"RecordMigrationHit"},
+		},
+		{
+			desc:        "Value found in neither backend",
+			inPrimary:   func() Backend { return NewMemoryBackend(config.Memory{}) },
+			inSecondary: func() Backend { return NewMemoryBackend(config.Memory{}) },
+			expectedErr: utils.NewPBCError(utils.KEY_NOT_FOUND),
+		},
+		{
+			desc:      "Primary backend errors other than KEY_NOT_FOUND don't fall back to the secondary one",
+			inPrimary: func() Backend { return NewErrorResponseMemoryBackend() },
+			inSecondary: func() Backend {
+				backend, _ := NewMemoryBackendWithValues(map[string]string{"key": "secondary value"})
+				return backend
+			},
+			expectedErr:     errors.New("Bakend error"),
+			expectedMetrics: []string{"RecordMigrationError"},
+		},
+		{
+			desc:            "Secondary backend error",
+			inPrimary:       func() Backend { return NewMemoryBackend(config.Memory{}) },
+			inSecondary:     func() Backend { return NewErrorResponseMemoryBackend() },
+			expectedErr:     errors.New("Bakend error"),
+			expectedMetrics: []string{"RecordMigrationError"},
+		},
+	}
+
+	for _, test := range testCases {
+		migration, mockMetrics := newTestMigrationBackend(config.MigrationWriteBoth, test.inPrimary(), test.inSecondary())
+
+		value, err := migration.Get(context.Background(), "key")
+
+		assert.Equal(t, test.expectedValue, value, test.desc)
+		assert.Equal(t, test.expectedErr, err, test.desc)
+		metricstest.AssertMetrics(t, test.expectedMetrics, *mockMetrics)
+	}
+}
+
+func TestMigrationPut(t *testing.T) {
+	testCases := []struct {
+		desc                   string
+		inWriteMode            config.MigrationWriteMode
+		inPrimaryData          map[string]string
+		expectedErr            error
+		expectedPrimaryValue   string
+		expectedSecondaryValue string
+	}{
+		{
+			desc:                   "Both backends get written",
+			inWriteMode:            config.MigrationWriteBoth,
+			expectedPrimaryValue:   "new value",
+			expectedSecondaryValue: "new value",
+		},
+		{
+			desc:                 "Only the primary backend gets written",
+			inWriteMode:          config.MigrationWritePrimaryOnly,
+			expectedPrimaryValue: "new value",
+		},
+		{
+			desc:                 "Values the primary backend rejects aren't written to the secondary one",
+			inWriteMode:          config.MigrationWriteBoth,
+			inPrimaryData:        map[string]string{"key": "primary value"},
+			expectedErr:          utils.NewPBCError(utils.RECORD_EXISTS),
+			expectedPrimaryValue: "primary value",
+		},
+	}
+
+	for _, test := range testCases {
+		primary, _ := NewMemoryBackendWithValues(test.inPrimaryData)
+		secondary := NewMemoryBackend(config.Memory{})
+		migration, _ := newTestMigrationBackend(test.inWriteMode, primary, secondary)
+
+		err := migration.Put(context.Background(), "key", "new value", 0)
+
+		assert.Equal(t, test.expectedErr, err, test.desc)
+		primaryValue, _ := primary.Get(context.Background(), "key")
+		assert.Equal(t, test.expectedPrimaryValue, primaryValue, test.desc)
+		secondaryValue, _ := secondary.Get(context.Background(), "key")
+		assert.Equal(t, test.expectedSecondaryValue, secondaryValue, test.desc)
+	}
+}
+
+func TestMigrationPutSecondaryError(t *testing.T) {
+	primary := NewMemoryBackend(config.Memory{})
+	migration, mockMetrics := newTestMigrationBackend(config.MigrationWriteBoth, primary, NewErrorResponseMemoryBackend())
+
+	assert.NoError(t, migration.Put(context.Background(), "key", "value", 0), "Secondary backend errors aren't returned")
+	metricstest.AssertMetrics(t, []string{"RecordMigrationError"}, *mockMetrics)
+}
+
+func TestMigrationDelete(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		inPrimaryData   map[string]string
+		inSecondaryData map[string]string
+		expectedErr     error
+	}{
+		{
+			desc:            "Key stored in both backends",
+			inPrimaryData:   map[string]string{"key": "value"},
+			inSecondaryData: map[string]string{"key": "value"},
+		},
+		{
+			desc:          "Key only stored in the primary backend",
+			inPrimaryData: map[string]string{"key": "value"},
+		},
+		{
+			desc:            "Key only stored in the secondary backend",
+			inSecondaryData: map[string]string{"key": "value"},
+		},
+		{
+			desc:        "Key stored in neither backend",
+			expectedErr: utils.NewPBCError(utils.KEY_NOT_FOUND),
+		},
+	}
+
+	for _, test := range testCases {
+		primary, _ := NewMemoryBackendWithValues(test.inPrimaryData)
+		secondary, _ := NewMemoryBackendWithValues(test.inSecondaryData)
+		migration, _ := newTestMigrationBackend(config.MigrationWritePrimaryOnly, primary, secondary)
+
+		assert.Equal(t, test.expectedErr, migration.Delete(context.Background(), "key"), test.desc)
+
+		_, err := migration.Get(context.Background(), "key")
+		assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "%s: value can't be read back", test.desc)
+	}
+}
+
+func TestMigrationGetBatch(t *testing.T) {
+	primary, _ := NewMemoryBackendWithValues(map[string]string{"primary": "primary value"})
+	secondary, _ := NewMemoryBackendWithValues(map[string]string{"primary": "stale value", "secondary": "secondary value"})
+	migration, mockMetrics := newTestMigrationBackend(config.MigrationWriteBoth, primary, secondary)
+
+	values, errs := migration.GetBatch(context.Background(), []string{"primary", "secondary", "missing"})
+
+	assert.Equal(t, []string{"primary value", "secondary value", ""}, values)
+	assert.Equal(t, []error{nil, nil, utils.NewPBCError(utils.KEY_NOT_FOUND)}, errs)
+	metricstest.AssertMetrics(t, []string{"RecordMigrationHit"}, *mockMetrics)
+}
+
+func TestMigrationPutBatch(t *testing.T) {
+	primary, _ := NewMemoryBackendWithValues(map[string]string{"existing": "primary value"})
+	secondary := NewMemoryBackend(config.Memory{})
+	migration, _ := newTestMigrationBackend(config.MigrationWriteBoth, primary, secondary)
+
+	errs := migration.PutBatch(context.Background(), []BatchPutItem{
+		{Key: "new", Value: "new value"},
+		{Key: "existing", Value: "new value"},
+	})
+
+	assert.Equal(t, []error{nil, utils.NewPBCError(utils.RECORD_EXISTS)}, errs)
+	secondaryValue, _ := secondary.Get(context.Background(), "new")
+	assert.Equal(t, "new value", secondaryValue, "Stored item gets written to the secondary backend")
+	_, err := secondary.Get(context.Background(), "existing")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Rejected item isn't written to the secondary backend")
+}
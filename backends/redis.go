@@ -18,12 +18,16 @@ import (
 type RedisDB interface {
 	Get(ctx context.Context, key string) (string, error)
 	Put(ctx context.Context, key string, value string, ttlSeconds int) (bool, error)
+	Del(ctx context.Context, key string) (int64, error)
+	PutBatch(ctx context.Context, items []BatchPutItem) ([]bool, []error)
+	GetBatch(ctx context.Context, keys []string) ([]string, []error)
 }
 
-// RedisDBClient is a wrapper for the Redis client that implements
-// the RedisDB interface
+// RedisDBClient is a wrapper for the Redis client that implements the RedisDB interface. The
+// underlying client talks to a single server, a Redis Cluster or a Sentinel monitored master
+// depending on config.backend.redis.mode
 type RedisDBClient struct {
-	client *redis.Client
+	client redis.UniversalClient
 }
 
 // Get returns the value associated with the provided `key` parameter
@@ -38,6 +42,52 @@ func (db RedisDBClient) Put(ctx context.Context, key, value string, ttlSeconds i
 	return db.client.SetNX(ctx, key, value, time.Duration(ttlSeconds)*time.Second).Result()
 }
 
+// Del removes `key` from the redis storage and returns the number of keys that were removed
+func (db RedisDBClient) Del(ctx context.Context, key string) (int64, error) {
+	return db.client.Del(ctx, key).Result()
+}
+
+// PutBatch pipelines one SetNX command per item so that all of them get sent to the redis
+// storage in a single round trip. MSETNX is not used because it doesn't support expiration
+// times and would refuse to store any item if a single one of the keys already existed
+func (db RedisDBClient) PutBatch(ctx context.Context, items []BatchPutItem) ([]bool, []error) {
+	cmds := make([]*redis.BoolCmd, len(items))
+	// Pipelined's own error is the first error found among the commands, which are
+	// individually inspected below
+	db.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, item := range items {
+			cmds[i] = pipe.SetNX(ctx, item.Key, item.Value, time.Duration(item.TTLSeconds)*time.Second)
+		}
+		return nil
+	})
+
+	results := make([]bool, len(items))
+	errs := make([]error, len(items))
+	for i, cmd := range cmds {
+		results[i], errs[i] = cmd.Result()
+	}
+	return results, errs
+}
+
+// GetBatch pipelines one Get command per key so that all of them get sent to the redis storage
+// in a single round trip
+func (db RedisDBClient) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	cmds := make([]*redis.StringCmd, len(keys))
+	db.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+	for i, cmd := range cmds {
+		values[i], errs[i] = cmd.Result()
+	}
+	return values, errs
+}
+
 // RedisBackend when initialized will instantiate and configure the Redis client. It implements
 // the Backend interface.
 type RedisBackend struct {
@@ -47,35 +97,28 @@ type RedisBackend struct {
 
 // NewRedisBackend initializes the redis client and pings to make sure connection was successful
 func NewRedisBackend(cfg config.Redis, ctx context.Context) *RedisBackend {
-	constr := cfg.Host + ":" + strconv.Itoa(cfg.Port)
-
-	options := &redis.Options{
-		Addr:     constr,
-		Password: cfg.Password,
-		DB:       cfg.Db,
-	}
-
-	if cfg.TLS.Enabled {
-		options = &redis.Options{
-			Addr:     constr,
-			Password: cfg.Password,
-			DB:       cfg.Db,
-			TLSConfig: &tls.Config{
-				InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
-			},
-		}
+	client, err := newRedisClient(cfg)
+	if err != nil {
+		logger.Fatal("Error creating Redis backend: %v", err)
+		panic("RedisBackend failure. This shouldn't happen.")
 	}
+	redisClient := RedisDBClient{client: client}
 
-	redisClient := RedisDBClient{client: redis.NewClient(options)}
-
-	_, err := redisClient.client.Ping(ctx).Result()
+	_, err = redisClient.client.Ping(ctx).Result()
 
 	if err != nil {
 		logger.Fatal("Error creating Redis backend: %v", err)
 		panic("RedisBackend failure. This shouldn't happen.")
 	}
 
-	logger.Info("Connected to Redis at %s:%d", cfg.Host, cfg.Port)
+	switch cfg.Mode {
+	case config.RedisCluster:
+		logger.Info("Connected to Redis Cluster at %v", cfg.Addrs)
+	case config.RedisSentinel:
+		logger.Info("Connected to Redis master %s through the sentinels at %v", cfg.MasterName, cfg.Addrs)
+	default:
+		logger.Info("Connected to Redis at %s:%d", cfg.Host, cfg.Port)
+	}
 
 	return &RedisBackend{
 		cfg:    cfg,
@@ -83,6 +126,56 @@ func NewRedisBackend(cfg config.Redis, ctx context.Context) *RedisBackend {
 	}
 }
 
+// newRedisClient builds the go-redis client that corresponds to cfg.Mode. go-redis'
+// NewUniversalClient is not used because it infers the mode from the number of addresses,
+// which can't tell a single node cluster seed apart from a standalone server. An error is
+// returned if TLS is enabled and the files config.backend.redis.tls points to can't be loaded
+func newRedisClient(cfg config.Redis) (redis.UniversalClient, error) {
+	var tlsConfig *tls.Config
+	if cfg.TLS.Enabled {
+		var err error
+		if tlsConfig, err = cfg.TLS.NewTLSConfig(); err != nil {
+			return nil, err
+		}
+	}
+	routeByLatency := cfg.ReadFromReplicas == config.RedisReplicaReadsLatency
+	routeRandomly := cfg.ReadFromReplicas == config.RedisReplicaReadsRandom
+
+	switch cfg.Mode {
+	case config.RedisCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:          cfg.Addrs,
+			Password:       cfg.Password,
+			RouteByLatency: routeByLatency,
+			RouteRandomly:  routeRandomly,
+			TLSConfig:      tlsConfig,
+		}), nil
+	case config.RedisSentinel:
+		options := &redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.Addrs,
+			SentinelPassword: cfg.SentinelPassword,
+			Password:         cfg.Password,
+			DB:               cfg.Db,
+			RouteByLatency:   routeByLatency,
+			RouteRandomly:    routeRandomly,
+			TLSConfig:        tlsConfig,
+		}
+		// Only the cluster flavor of the failover client knows how to route reads to replicas
+		if routeByLatency || routeRandomly {
+			return redis.NewFailoverClusterClient(options), nil
+		}
+		return redis.NewFailoverClient(options), nil
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:      cfg.Host + ":" + strconv.Itoa(cfg.Port),
+			Password:  cfg.Password,
+			DB:        cfg.Db,
+			TLSConfig: tlsConfig,
+		}), nil
+	}
+}
+
 // Get calls the Redis client to return the value associated with the provided `key`
 // parameter and interprets its response. A `Nil` error reply of the Redis client means
 // the `key` does not exist.
@@ -110,3 +203,45 @@ func (b *RedisBackend) Put(ctx context.Context, key string, value string, ttlSec
 	}
 	return nil
 }
+
+// Delete removes the value stored under `key` from the Redis storage server. If the Redis
+// client reports that no key was removed, a KEY_NOT_FOUND error is returned
+func (b *RedisBackend) Delete(ctx context.Context, key string) error {
+	deleted, err := b.client.Del(ctx, key)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+	return nil
+}
+
+// PutBatch writes every item to the Redis storage server in a single round trip. Each item's
+// error is interpreted the same way Put does
+func (b *RedisBackend) PutBatch(ctx context.Context, items []BatchPutItem) []error {
+	results, errs := b.client.PutBatch(ctx, items)
+	for i := range items {
+		if errs[i] != nil && errs[i] != redis.Nil {
+			continue
+		}
+		if results[i] {
+			errs[i] = nil
+		} else {
+			errs[i] = utils.NewPBCError(utils.RECORD_EXISTS)
+		}
+	}
+	return errs
+}
+
+// GetBatch retrieves the values stored under keys from the Redis storage server in a single
+// round trip. Keys that don't exist come with a KEY_NOT_FOUND error
+func (b *RedisBackend) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	values, errs := b.client.GetBatch(ctx, keys)
+	for i := range errs {
+		if errs[i] == redis.Nil {
+			errs[i] = utils.NewPBCError(utils.KEY_NOT_FOUND)
+		}
+	}
+	return values, errs
+}
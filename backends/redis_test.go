@@ -2,10 +2,18 @@ package backends
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alicebob/miniredis/v2/server"
 	"github.com/go-redis/redis/v8"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/utils"
 	"github.com/stretchr/testify/assert"
 )
@@ -193,3 +201,294 @@ func TestRedisClientPut(t *testing.T) {
 		}
 	}
 }
+
+func TestRedisClientDelete(t *testing.T) {
+	redisBackend := &RedisBackend{}
+
+	testCases := []struct {
+		desc        string
+		redisClient FakeRedisClient
+		expectedErr error
+	}{
+		{
+			desc:        "RedisBackend.Delete() throws a server error",
+			redisClient: FakeRedisClient{ServerError: errors.New("some delete error")},
+			expectedErr: errors.New("some delete error"),
+		},
+		{
+			desc:        "RedisBackend.Delete() removed no keys",
+			redisClient: FakeRedisClient{StoredData: map[string]string{}},
+			expectedErr: utils.NewPBCError(utils.KEY_NOT_FOUND),
+		},
+		{
+			desc:        "RedisBackend.Delete() removed the key",
+			redisClient: FakeRedisClient{StoredData: map[string]string{"defaultKey": "aValue"}},
+			expectedErr: nil,
+		},
+	}
+
+	for _, tt := range testCases {
+		redisBackend.client = tt.redisClient
+
+		// Run test
+		actualErr := redisBackend.Delete(context.Background(), "defaultKey")
+
+		// Assertions
+		assert.Equal(t, tt.expectedErr, actualErr, tt.desc)
+		_, found := tt.redisClient.StoredData["defaultKey"]
+		assert.False(t, found, tt.desc)
+	}
+}
+
+func TestRedisClientPutBatch(t *testing.T) {
+	redisBackend := &RedisBackend{}
+
+	testCases := []struct {
+		desc           string
+		redisClient    FakeRedisClient
+		expectedErrors []error
+		expectedStored map[string]string
+	}{
+		{
+			desc:        "Redis client side error. Expect the error for every item",
+			redisClient: FakeRedisClient{StoredData: map[string]string{}, ServerError: errors.New("A Redis client side error")},
+			expectedErrors: []error{
+				errors.New("A Redis client side error"),
+				errors.New("A Redis client side error"),
+			},
+			expectedStored: map[string]string{},
+		},
+		{
+			desc:        "One of the keys already exists. Expect a RECORD_EXISTS error for that item only",
+			redisClient: FakeRedisClient{StoredData: map[string]string{"key2": "original value"}},
+			expectedErrors: []error{
+				nil,
+				utils.NewPBCError(utils.RECORD_EXISTS),
+			},
+			expectedStored: map[string]string{"key1": "value1", "key2": "original value"},
+		},
+	}
+
+	for _, tt := range testCases {
+		redisBackend.client = tt.redisClient
+
+		// Run test
+		errs := redisBackend.PutBatch(context.Background(), []BatchPutItem{
+			{Key: "key1", Value: "value1", TTLSeconds: 10},
+			{Key: "key2", Value: "value2", TTLSeconds: 10},
+		})
+
+		// Assertions
+		assert.Equal(t, tt.expectedErrors, errs, tt.desc)
+		assert.Equal(t, tt.expectedStored, tt.redisClient.StoredData, tt.desc)
+	}
+}
+
+func TestRedisClientGetBatch(t *testing.T) {
+	redisBackend := &RedisBackend{}
+
+	testCases := []struct {
+		desc           string
+		redisClient    FakeRedisClient
+		expectedValues []string
+		expectedErrors []error
+	}{
+		{
+			desc:           "Redis client side error. Expect the error for every key",
+			redisClient:    FakeRedisClient{ServerError: errors.New("A Redis client side error")},
+			expectedValues: []string{"", ""},
+			expectedErrors: []error{
+				errors.New("A Redis client side error"),
+				errors.New("A Redis client side error"),
+			},
+		},
+		{
+			desc:           "One of the keys doesn't exist. Expect redis.Nil to be interpreted as a KEY_NOT_FOUND error",
+			redisClient:    FakeRedisClient{StoredData: map[string]string{"key1": "value1"}},
+			expectedValues: []string{"value1", ""},
+			expectedErrors: []error{nil, utils.NewPBCError(utils.KEY_NOT_FOUND)},
+		},
+	}
+
+	for _, tt := range testCases {
+		redisBackend.client = tt.redisClient
+
+		// Run test
+		values, errs := redisBackend.GetBatch(context.Background(), []string{"key1", "key2"})
+
+		// Assertions
+		assert.Equal(t, tt.expectedValues, values, tt.desc)
+		assert.Equal(t, tt.expectedErrors, errs, tt.desc)
+	}
+}
+
+func TestNewRedisBackendModes(t *testing.T) {
+	// In-process Redis server that also answers the CLUSTER SLOTS command, claiming every slot
+	master := miniredis.RunT(t)
+	// Cluster clients that read from replicas send READONLY to every node they connect to
+	master.Server().Register("READONLY", func(c *server.Peer, cmd string, args []string) {
+		c.WriteOK()
+	})
+	sentinel := newFakeRedisSentinel(t, "mymaster", master)
+
+	testCases := []struct {
+		desc               string
+		inCfg              config.Redis
+		expectedClientType redis.UniversalClient
+	}{
+		{
+			desc: "Standalone mode",
+			inCfg: config.Redis{
+				Mode: config.RedisStandalone,
+				Host: master.Host(),
+				Port: master.Server().Addr().Port,
+			},
+			expectedClientType: &redis.Client{},
+		},
+		{
+			desc: "Cluster mode",
+			inCfg: config.Redis{
+				Mode:             config.RedisCluster,
+				Addrs:            []string{master.Addr()},
+				ReadFromReplicas: config.RedisReplicaReadsNever,
+			},
+			expectedClientType: &redis.ClusterClient{},
+		},
+		{
+			desc: "Cluster mode reading from random replicas",
+			inCfg: config.Redis{
+				Mode:             config.RedisCluster,
+				Addrs:            []string{master.Addr()},
+				ReadFromReplicas: config.RedisReplicaReadsRandom,
+			},
+			expectedClientType: &redis.ClusterClient{},
+		},
+		{
+			desc: "Sentinel mode",
+			inCfg: config.Redis{
+				Mode:             config.RedisSentinel,
+				Addrs:            []string{sentinel.Addr()},
+				MasterName:       "mymaster",
+				ReadFromReplicas: config.RedisReplicaReadsNever,
+			},
+			expectedClientType: &redis.Client{},
+		},
+		{
+			desc: "Sentinel mode reading from the closest replica",
+			inCfg: config.Redis{
+				Mode:             config.RedisSentinel,
+				Addrs:            []string{sentinel.Addr()},
+				MasterName:       "mymaster",
+				ReadFromReplicas: config.RedisReplicaReadsLatency,
+			},
+			expectedClientType: &redis.ClusterClient{},
+		},
+	}
+
+	for _, tc := range testCases {
+		master.FlushAll()
+		ctx := context.Background()
+
+		backend := NewRedisBackend(tc.inCfg, ctx)
+		assert.IsType(t, tc.expectedClientType, backend.client.(RedisDBClient).client, tc.desc)
+
+		assert.NoError(t, backend.Put(ctx, "someKey", "someValue", 10), tc.desc)
+		assert.Equal(t, utils.NewPBCError(utils.RECORD_EXISTS), backend.Put(ctx, "someKey", "otherValue", 10), tc.desc)
+		value, err := backend.Get(ctx, "someKey")
+		assert.NoError(t, err, tc.desc)
+		assert.Equal(t, "someValue", value, tc.desc)
+		assert.True(t, master.Exists("someKey"), "%s: value wasn't stored in the master", tc.desc)
+
+		errs := backend.PutBatch(ctx, []BatchPutItem{{Key: "batchKey", Value: "batchValue", TTLSeconds: 10}, {Key: "someKey", Value: "otherValue", TTLSeconds: 10}})
+		assert.Equal(t, []error{nil, utils.NewPBCError(utils.RECORD_EXISTS)}, errs, tc.desc)
+		values, errs := backend.GetBatch(ctx, []string{"batchKey", "someKey"})
+		assert.Equal(t, []string{"batchValue", "someValue"}, values, tc.desc)
+		assert.Equal(t, []error{nil, nil}, errs, tc.desc)
+
+		assert.NoError(t, backend.Delete(ctx, "someKey"), tc.desc)
+		assert.False(t, master.Exists("someKey"), "%s: value wasn't removed from the master", tc.desc)
+
+		assert.NoError(t, backend.client.(RedisDBClient).client.Close(), tc.desc)
+	}
+}
+
+// newFakeRedisSentinel starts an in-process server that answers the SENTINEL subcommands the
+// go-redis failover clients need in order to discover that master is monitored as masterName
+// and has no replicas
+func newFakeRedisSentinel(t *testing.T, masterName string, master *miniredis.Miniredis) *miniredis.Miniredis {
+	sentinel := miniredis.RunT(t)
+	sentinel.Server().Register("SENTINEL", func(c *server.Peer, cmd string, args []string) {
+		if len(args) < 2 || args[1] != masterName {
+			c.WriteError("ERR No such master with that name")
+			return
+		}
+		switch strings.ToLower(args[0]) {
+		case "get-master-addr-by-name":
+			c.WriteStrings([]string{master.Host(), strconv.Itoa(master.Server().Addr().Port)})
+		case "sentinels", "slaves", "replicas":
+			c.WriteLen(0)
+		default:
+			c.WriteError("ERR Unknown sentinel subcommand '" + args[0] + "'")
+		}
+	})
+	return sentinel
+}
+
+func TestNewRedisBackendMutualTLS(t *testing.T) {
+	// In-process Redis server that only accepts clients presenting a certificate signed by the test CA
+	caPEM, err := os.ReadFile("../config/configtest/tls/ca.pem")
+	if !assert.NoError(t, err) {
+		return
+	}
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(caPEM)
+	serverCert, err := tls.LoadX509KeyPair("../config/configtest/tls/server.pem", "../config/configtest/tls/server-key.pem")
+	if !assert.NoError(t, err) {
+		return
+	}
+	redisServer, err := miniredis.RunTLS(&tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer redisServer.Close()
+
+	cfg := config.Redis{
+		Mode: config.RedisStandalone,
+		Host: redisServer.Host(),
+		Port: redisServer.Server().Addr().Port,
+		TLS: config.RedisTLS{
+			Enabled:    true,
+			CAFile:     "../config/configtest/tls/ca.pem",
+			CertFile:   "../config/configtest/tls/client.pem",
+			KeyFile:    "../config/configtest/tls/client-key.pem",
+			ServerName: "localhost",
+			MinVersion: "1.2",
+		},
+	}
+
+	backend := NewRedisBackend(cfg, context.Background())
+	defer backend.client.(RedisDBClient).client.Close()
+
+	assert.NoError(t, backend.Put(context.Background(), "someKey", "someValue", 10))
+	value, err := backend.Get(context.Background(), "someKey")
+	assert.NoError(t, err)
+	assert.Equal(t, "someValue", value)
+}
+
+func TestNewRedisClientInvalidTLS(t *testing.T) {
+	cfg := config.Redis{
+		Mode: config.RedisStandalone,
+		TLS: config.RedisTLS{
+			Enabled:  true,
+			CertFile: "../config/configtest/tls/client.pem",
+		},
+	}
+
+	client, err := newRedisClient(cfg)
+	assert.Nil(t, client)
+	assert.EqualError(t, err, "invalid config.backend.redis.tls: cert_file and key_file must be provided together")
+}
@@ -0,0 +1,203 @@
+package backends
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+)
+
+// ShardedBackend spreads keys across several independent backends, or shards, so that the
+// keys a single storage cluster holds can be split over several of them. Every key is routed
+// to one shard by a consistent hash ring, which keeps adding, removing or reweighting a shard
+// from moving keys other than the ones that shard gains or loses.
+type ShardedBackend struct {
+	shards  []Backend
+	names   []string
+	ring    hashRing
+	metrics *metrics.Metrics
+}
+
+// NewShardedBackend places the shards cfg.Shards describes on a hash ring. shards must hold the
+// backends built from the cfg.Shards backend sections, in the same order
+func NewShardedBackend(cfg config.Sharded, shards []Backend, metrics *metrics.Metrics) *ShardedBackend {
+	names := make([]string, len(cfg.Shards))
+	weights := make([]int, len(cfg.Shards))
+	for i, shard := range cfg.Shards {
+		names[i] = shard.Name
+		weights[i] = shard.Weight
+	}
+
+	return &ShardedBackend{
+		shards:  shards,
+		names:   names,
+		ring:    newHashRing(names, weights, cfg.VirtualNodes),
+		metrics: metrics,
+	}
+}
+
+func (b *ShardedBackend) Get(ctx context.Context, key string) (string, error) {
+	shard := b.ring.shardOf(key)
+	b.metrics.RecordShardRequest(b.names[shard])
+
+	value, err := b.shards[shard].Get(ctx, key)
+	b.recordError(shard, err)
+	return value, err
+}
+
+func (b *ShardedBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	shard := b.ring.shardOf(key)
+	b.metrics.RecordShardRequest(b.names[shard])
+
+	err := b.shards[shard].Put(ctx, key, value, ttlSeconds)
+	b.recordError(shard, err)
+	return err
+}
+
+func (b *ShardedBackend) Delete(ctx context.Context, key string) error {
+	shard := b.ring.shardOf(key)
+	b.metrics.RecordShardRequest(b.names[shard])
+
+	err := b.shards[shard].Delete(ctx, key)
+	b.recordError(shard, err)
+	return err
+}
+
+// GetBatch groups keys by shard and requests every group from its shard in parallel
+func (b *ShardedBackend) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+
+	groups := b.groupByShard(len(keys), func(i int) string { return keys[i] })
+
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(len(groups))
+	for shard, indexes := range groups {
+		go func(shard int, indexes []int) {
+			defer waitGroup.Done()
+
+			shardKeys := make([]string, len(indexes))
+			for j, i := range indexes {
+				shardKeys[j] = keys[i]
+			}
+			shardValues, shardErrs := GetBatch(ctx, b.shards[shard], shardKeys)
+			for j, i := range indexes {
+				values[i], errs[i] = shardValues[j], shardErrs[j]
+				b.recordError(shard, errs[i])
+			}
+		}(shard, indexes)
+	}
+	waitGroup.Wait()
+
+	return values, errs
+}
+
+// PutBatch groups items by shard and writes every group to its shard in parallel
+func (b *ShardedBackend) PutBatch(ctx context.Context, items []BatchPutItem) []error {
+	errs := make([]error, len(items))
+
+	groups := b.groupByShard(len(items), func(i int) string { return items[i].Key })
+
+	var waitGroup sync.WaitGroup
+	waitGroup.Add(len(groups))
+	for shard, indexes := range groups {
+		go func(shard int, indexes []int) {
+			defer waitGroup.Done()
+
+			shardItems := make([]BatchPutItem, len(indexes))
+			for j, i := range indexes {
+				shardItems[j] = items[i]
+			}
+			shardErrs := PutBatch(ctx, b.shards[shard], shardItems)
+			for j, i := range indexes {
+				errs[i] = shardErrs[j]
+				b.recordError(shard, errs[i])
+			}
+		}(shard, indexes)
+	}
+	waitGroup.Wait()
+
+	return errs
+}
+
+// HealthCheck checks every shard, since each of them holds a share of the keys
+func (b *ShardedBackend) HealthCheck(ctx context.Context) error {
+	for i, shard := range b.shards {
+		if err := HealthCheck(ctx, shard); err != nil {
+			return fmt.Errorf("shard %s: %w", b.names[i], err)
+		}
+	}
+	return nil
+}
+
+// groupByShard returns the indexes of the n keys keyAt returns grouped by the shard that owns
+// them, and counts a request against every shard once per key
+func (b *ShardedBackend) groupByShard(n int, keyAt func(i int) string) map[int][]int {
+	groups := make(map[int][]int)
+	for i := 0; i < n; i++ {
+		shard := b.ring.shardOf(keyAt(i))
+		b.metrics.RecordShardRequest(b.names[shard])
+		groups[shard] = append(groups[shard], i)
+	}
+	return groups
+}
+
+// recordError counts err against shard unless it's an expected outcome, such as a key that
+// wasn't found or that already existed
+func (b *ShardedBackend) recordError(shard int, err error) {
+	if err != nil && !isKeyNotFound(err) && !isRecordExists(err) {
+		b.metrics.RecordShardError(b.names[shard])
+	}
+}
+
+// hashRing maps keys to shards. Every shard places weight * virtualNodes points on the ring, at
+// positions that only depend on its name, and owns the keys that hash between the point that
+// precedes each of them and the point itself. A shard joining the ring therefore only takes
+// keys away from the shards that precede its points, and a shard leaving it only hands its own
+// keys over.
+type hashRing struct {
+	points []ringPoint
+}
+
+type ringPoint struct {
+	hash  uint64
+	shard int
+}
+
+// newHashRing places the shards named names on a ring. weights holds the weight of every shard,
+// in the same order
+func newHashRing(names []string, weights []int, virtualNodes int) hashRing {
+	var points []ringPoint
+	for shard, name := range names {
+		for i := 0; i < weights[shard]*virtualNodes; i++ {
+			points = append(points, ringPoint{hash: ringHash(fmt.Sprintf("%s#%d", name, i)), shard: shard})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].hash < points[j].hash })
+
+	return hashRing{points: points}
+}
+
+// shardOf returns the index of the shard that owns key: the one whose point follows the hash
+// of key, wrapping around past the last point
+func (r hashRing) shardOf(key string) int {
+	hash := ringHash(key)
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.points[i].shard
+}
+
+// ringHash positions s on the ring. MD5 isn't used for its security properties but because it's
+// stable across releases and spreads similar inputs, like the names of consecutive virtual
+// nodes, evenly
+func ringHash(s string) uint64 {
+	sum := md5.Sum([]byte(s))
+	return binary.BigEndian.Uint64(sum[:8])
+}
@@ -0,0 +1,187 @@
+package backends
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestShardedBackend returns a sharded backend over shards, named "shard0", "shard1" and so
+// on and all of them of weight 1, along with the mock metrics it records to
+func newTestShardedBackend(shards ...Backend) (*ShardedBackend, *metricstest.MockMetrics) {
+	cfg := config.Sharded{VirtualNodes: 160}
+	for i := range shards {
+		cfg.Shards = append(cfg.Shards, config.Shard{Name: fmt.Sprintf("shard%d", i), Weight: 1})
+	}
+
+	mockMetrics := metricstest.CreateMockMetrics()
+	sharded := NewShardedBackend(cfg, shards, &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	})
+	return sharded, &mockMetrics
+}
+
+func TestHashRingDistribution(t *testing.T) {
+	const keys = 30000
+
+	testCases := []struct {
+		desc            string
+		inWeights       []int
+		expectedPercent []float64
+	}{
+		{
+			desc:            "Shards of the same weight",
+			inWeights:       []int{1, 1, 1},
+			expectedPercent: []float64{33.3, 33.3, 33.3},
+		},
+		{
+			desc:            "Shards of different weights",
+			inWeights:       []int{1, 3},
+			expectedPercent: []float64{25, 75},
+		},
+	}
+
+	for _, test := range testCases {
+		names := make([]string, len(test.inWeights))
+		for i := range names {
+			names[i] = fmt.Sprintf("shard%d", i)
+		}
+		ring := newHashRing(names, test.inWeights, 160)
+
+		counts := make([]int, len(names))
+		for i := 0; i < keys; i++ {
+			counts[ring.shardOf(fmt.Sprintf("key-%d", i))]++
+		}
+
+		for shard, expected := range test.expectedPercent {
+			assert.InDelta(t, expected, float64(counts[shard])*100/keys, 5, "%s: share of %s", test.desc, names[shard])
+		}
+	}
+}
+
+func TestHashRingAddShard(t *testing.T) {
+	const keys = 30000
+
+	before := newHashRing([]string{"a", "b", "c"}, []int{1, 1, 1}, 160)
+	after := newHashRing([]string{"a", "b", "c", "d"}, []int{1, 1, 1, 1}, 160)
+
+	moved := 0
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		if shardBefore, shardAfter := before.shardOf(key), after.shardOf(key); shardBefore != shardAfter {
+			assert.Equal(t, 3, shardAfter, "Key %s moved between existing shards", key)
+			moved++
+		}
+	}
+	assert.InDelta(t, 25, float64(moved)*100/keys, 5, "Share of the keys the new shard took over")
+}
+
+func TestShardedRouting(t *testing.T) {
+	shards := []*MemoryBackend{NewMemoryBackend(config.Memory{}), NewMemoryBackend(config.Memory{}), NewMemoryBackend(config.Memory{})}
+	sharded, mockMetrics := newTestShardedBackend(shards[0], shards[1], shards[2])
+
+	for i := 0; i < 100; i++ {
+		key := fmt.Sprintf("key-%d", i)
+		assert.NoError(t, sharded.Put(context.Background(), key, "value", 0), "Put %s", key)
+
+		owner := sharded.ring.shardOf(key)
+		for shard, backend := range shards {
+			_, err := backend.Get(context.Background(), key)
+			assert.Equal(t, shard == owner, err == nil, "Key %s stored in shard%d", key, shard)
+		}
+
+		value, err := sharded.Get(context.Background(), key)
+		assert.NoError(t, err, "Get %s", key)
+		assert.Equal(t, "value", value, "Get %s", key)
+
+		assert.NoError(t, sharded.Delete(context.Background(), key), "Delete %s", key)
+		_, err = shards[owner].Get(context.Background(), key)
+		assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Key %s deleted from its shard", key)
+	}
+	metricstest.AssertMetrics(t, []string{"RecordShardRequest"}, *mockMetrics)
+}
+
+func TestShardedErrors(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		inShard         func() Backend
+		expectedErr     error
+		expectedMetrics []string
+	}{
+		{
+			desc:            "Shard errors get recorded",
+			inShard:         func() Backend { return NewErrorResponseMemoryBackend() },
+			expectedErr:     errors.New("Bakend error"),
+			expectedMetrics: []string{"RecordShardRequest", "RecordShardError"},
+		},
+		{
+			desc:            "Missing keys aren't shard errors",
+			inShard:         func() Backend { return NewMemoryBackend(config.Memory{}) },
+			expectedErr:     utils.NewPBCError(utils.KEY_NOT_FOUND),
+			expectedMetrics: []string{"RecordShardRequest"},
+		},
+	}
+
+	for _, test := range testCases {
+		sharded, mockMetrics := newTestShardedBackend(test.inShard())
+
+		_, err := sharded.Get(context.Background(), "key")
+
+		assert.Equal(t, test.expectedErr, err, test.desc)
+		metricstest.AssertMetrics(t, test.expectedMetrics, *mockMetrics)
+	}
+}
+
+func TestShardedGetBatch(t *testing.T) {
+	sharded, _ := newTestShardedBackend(NewMemoryBackend(config.Memory{}), NewMemoryBackend(config.Memory{}))
+
+	keys := make([]string, 20)
+	expectedValues := make([]string, len(keys))
+	for i := range keys {
+		keys[i] = fmt.Sprintf("key-%d", i)
+		if i%2 == 0 {
+			expectedValues[i] = fmt.Sprintf("value-%d", i)
+			sharded.Put(context.Background(), keys[i], expectedValues[i], 0)
+		}
+	}
+
+	values, errs := sharded.GetBatch(context.Background(), keys)
+
+	assert.Equal(t, expectedValues, values)
+	for i, err := range errs {
+		if i%2 == 0 {
+			assert.NoError(t, err, "Stored key %s", keys[i])
+		} else {
+			assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Missing key %s", keys[i])
+		}
+	}
+}
+
+func TestShardedPutBatch(t *testing.T) {
+	shards := []*MemoryBackend{NewMemoryBackend(config.Memory{}), NewMemoryBackend(config.Memory{})}
+	sharded, _ := newTestShardedBackend(shards[0], shards[1])
+	sharded.Put(context.Background(), "existing", "old value", 0)
+
+	items := []BatchPutItem{{Key: "existing", Value: "new value"}}
+	for i := 0; i < 20; i++ {
+		items = append(items, BatchPutItem{Key: fmt.Sprintf("key-%d", i), Value: "value"})
+	}
+
+	errs := sharded.PutBatch(context.Background(), items)
+
+	assert.Equal(t, utils.NewPBCError(utils.RECORD_EXISTS), errs[0], "Existing key")
+	for i, item := range items[1:] {
+		assert.NoError(t, errs[i+1], "Key %s", item.Key)
+		_, err := shards[sharded.ring.shardOf(item.Key)].Get(context.Background(), item.Key)
+		assert.NoError(t, err, "Key %s stored in its shard", item.Key)
+	}
+}
@@ -0,0 +1,259 @@
+package backends
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"git.pubmatic.com/PubMatic/go-common/logger"
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// SQLBackend stores values in a table of a PostgreSQL or MySQL database. Every row holds a
+// key, its value and the Unix time in seconds the value expires at, which is NULL for values
+// stored without a TTL. Expired rows are never served and get replaced by values stored under
+// the same key, while a background sweeper deletes the ones nobody writes to again.
+type SQLBackend struct {
+	db      *sql.DB
+	dialect sqlDialect
+	now     func() time.Time
+}
+
+// sqlMaxKeyLength is the size of the cache_key column. Longer keys get rejected before
+// reaching the database
+const sqlMaxKeyLength = 255
+
+// sqlDialect holds the statements that differ between the databases the SQL backend supports
+type sqlDialect struct {
+	get    string
+	put    string
+	delete string
+	sweep  string
+	schema []string
+	// putArgs returns the arguments of the put statement, which inserts a row unless the key
+	// holds a value that hasn't expired at now
+	putArgs func(key string, value string, expiresAt sql.NullInt64, now int64) []interface{}
+}
+
+func newSQLDialect(driver config.SQLDriver, table string) sqlDialect {
+	if driver == config.SQLMySQL {
+		return sqlDialect{
+			get: fmt.Sprintf("SELECT cache_value FROM %s WHERE cache_key = ? AND (expires_at IS NULL OR expires_at > ?)", table),
+			// Assignments are evaluated in order, so both conditions see the expires_at value of
+			// the existing row
+			put: fmt.Sprintf("INSERT INTO %s (cache_key, cache_value, expires_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE "+
+				"cache_value = IF(expires_at IS NOT NULL AND expires_at <= ?, VALUES(cache_value), cache_value), "+
+				"expires_at = IF(expires_at IS NOT NULL AND expires_at <= ?, VALUES(expires_at), expires_at)", table),
+			delete: fmt.Sprintf("DELETE FROM %s WHERE cache_key = ? AND (expires_at IS NULL OR expires_at > ?)", table),
+			sweep:  fmt.Sprintf("DELETE FROM %s WHERE expires_at <= ?", table),
+			schema: []string{
+				fmt.Sprintf("CREATE TABLE IF NOT EXISTS %[1]s (cache_key VARCHAR(%[2]d) NOT NULL PRIMARY KEY, cache_value MEDIUMTEXT NOT NULL, expires_at BIGINT NULL, INDEX %[1]s_expires_at (expires_at))", table, sqlMaxKeyLength),
+			},
+			putArgs: func(key string, value string, expiresAt sql.NullInt64, now int64) []interface{} {
+				return []interface{}{key, value, expiresAt, now, now}
+			},
+		}
+	}
+
+	return sqlDialect{
+		get: fmt.Sprintf("SELECT cache_value FROM %s WHERE cache_key = $1 AND (expires_at IS NULL OR expires_at > $2)", table),
+		put: fmt.Sprintf("INSERT INTO %[1]s (cache_key, cache_value, expires_at) VALUES ($1, $2, $3) ON CONFLICT (cache_key) DO UPDATE "+
+			"SET cache_value = EXCLUDED.cache_value, expires_at = EXCLUDED.expires_at "+
+			"WHERE %[1]s.expires_at IS NOT NULL AND %[1]s.expires_at <= $4", table),
+		delete: fmt.Sprintf("DELETE FROM %s WHERE cache_key = $1 AND (expires_at IS NULL OR expires_at > $2)", table),
+		sweep:  fmt.Sprintf("DELETE FROM %s WHERE expires_at <= $1", table),
+		schema: []string{
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (cache_key VARCHAR(%d) PRIMARY KEY, cache_value TEXT NOT NULL, expires_at BIGINT)", table, sqlMaxKeyLength),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %[1]s_expires_at ON %[1]s (expires_at)", table),
+		},
+		putArgs: func(key string, value string, expiresAt sql.NullInt64, now int64) []interface{} {
+			return []interface{}{key, value, expiresAt, now}
+		},
+	}
+}
+
+// NewSQLBackend connects to the database cfg.DSN points to and starts the sweeper if
+// cfg.SweepIntervalSeconds is positive
+func NewSQLBackend(cfg config.SQL, ctx context.Context) *SQLBackend {
+	db, err := openSQLDatabase(cfg)
+	if err != nil {
+		logger.Fatal("Error creating SQL backend: %v", err)
+		panic("SQLBackend failure. This shouldn't happen.")
+	}
+	if err := db.PingContext(ctx); err != nil {
+		logger.Fatal("Error creating SQL backend: %v", err)
+		panic("SQLBackend failure. This shouldn't happen.")
+	}
+	logger.Info("Connected to %s, storing values in table %s", cfg.Driver, cfg.Table)
+
+	backend := &SQLBackend{
+		db:      db,
+		dialect: newSQLDialect(cfg.Driver, cfg.Table),
+		now:     time.Now,
+	}
+	if cfg.SweepIntervalSeconds > 0 {
+		go backend.runSweeper(time.Duration(cfg.SweepIntervalSeconds) * time.Second)
+	}
+	return backend
+}
+
+// openSQLDatabase creates the connection pool cfg describes. MySQL connections are made to
+// report the rows an update changed rather than the ones it matched, which Put relies on to
+// tell inserted values apart from existing ones
+func openSQLDatabase(cfg config.SQL) (*sql.DB, error) {
+	dsn := cfg.DSN
+	if cfg.Driver == config.SQLMySQL {
+		mysqlCfg, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		mysqlCfg.ClientFoundRows = false
+		dsn = mysqlCfg.FormatDSN()
+	}
+
+	db, err := sql.Open(string(cfg.Driver), dsn)
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetimeSeconds) * time.Second)
+	db.SetConnMaxIdleTime(time.Duration(cfg.ConnMaxIdleTimeSeconds) * time.Second)
+	return db, nil
+}
+
+// CreateSQLSchema creates the table the SQL backend stores data in, along with the index the
+// sweeper uses. Existing tables and indexes are left untouched
+func CreateSQLSchema(cfg config.SQL) error {
+	db, err := openSQLDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for _, statement := range newSQLDialect(cfg.Driver, cfg.Table).schema {
+		logger.Info("Running: %s", statement)
+		if _, err := db.Exec(statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Get returns the value stored under key unless it expired
+func (b *SQLBackend) Get(ctx context.Context, key string) (string, error) {
+	if err := validateSQLKey(key); err != nil {
+		return "", err
+	}
+
+	var value string
+	err := b.db.QueryRowContext(ctx, b.dialect.get, key, b.now().Unix()).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+	if err != nil {
+		return "", classifySQLError(err, utils.GET_DEADLINE_EXCEEDED)
+	}
+	return value, nil
+}
+
+// Put inserts a row holding value, or replaces the row of key if its value expired. A
+// RecordExistsError is returned if key holds a value that hasn't expired
+func (b *SQLBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	if err := validateSQLKey(key); err != nil {
+		return err
+	}
+
+	now := b.now().Unix()
+	var expiresAt sql.NullInt64
+	if ttlSeconds > 0 {
+		expiresAt = sql.NullInt64{Int64: now + int64(ttlSeconds), Valid: true}
+	}
+
+	result, err := b.db.ExecContext(ctx, b.dialect.put, b.dialect.putArgs(key, value, expiresAt, now)...)
+	if err != nil {
+		return classifySQLError(err, utils.PUT_DEADLINE_EXCEEDED)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return utils.NewPBCError(utils.RECORD_EXISTS)
+	}
+	return nil
+}
+
+// Delete removes the row of key. Returns a KEY_NOT_FOUND error if there's none or if its value
+// expired, in which case the row is left to the sweeper
+func (b *SQLBackend) Delete(ctx context.Context, key string) error {
+	if err := validateSQLKey(key); err != nil {
+		return err
+	}
+
+	result, err := b.db.ExecContext(ctx, b.dialect.delete, key, b.now().Unix())
+	if err != nil {
+		return classifySQLError(err, utils.PUT_DEADLINE_EXCEEDED)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return utils.NewPBCError(utils.KEY_NOT_FOUND)
+	}
+	return nil
+}
+
+// HealthCheck pings the database
+func (b *SQLBackend) HealthCheck(ctx context.Context) error {
+	return b.db.PingContext(ctx)
+}
+
+// sweep deletes every expired row and returns how many were deleted
+func (b *SQLBackend) sweep(ctx context.Context) (int64, error) {
+	result, err := b.db.ExecContext(ctx, b.dialect.sweep, b.now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// runSweeper periodically deletes expired rows so the table doesn't keep growing with values
+// that are never requested again. A sweep is given up if it takes longer than interval
+func (b *SQLBackend) runSweeper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		if deleted, err := b.sweep(ctx); err != nil {
+			logger.Error("SQL backend sweeper failed: %v", err)
+		} else if deleted > 0 {
+			logger.Debug("SQL backend sweeper deleted %d expired rows", deleted)
+		}
+		cancel()
+	}
+}
+
+// validateSQLKey returns a KEY_LENGTH error if key is longer than the cache_key column allows
+func validateSQLKey(key string) error {
+	if len(key) > sqlMaxKeyLength {
+		return utils.NewPBCError(utils.KEY_LENGTH, fmt.Sprintf("Key length %d exceeded SQL backend max key length %d", len(key), sqlMaxKeyLength))
+	}
+	return nil
+}
+
+// classifySQLError returns a Prebid Cache error of type timeoutErrType if err comes from the
+// request context deadline
+func classifySQLError(err error, timeoutErrType int) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return utils.NewPBCError(timeoutErrType)
+	}
+	return err
+}
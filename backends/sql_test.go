@@ -0,0 +1,254 @@
+package backends
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+const sqlTestNow = 1700000000
+
+// newTestSQLBackend returns a SQL backend whose clock is stopped at sqlTestNow, along with the
+// mock of the database it queries
+func newTestSQLBackend(t *testing.T, driver config.SQLDriver) (*SQLBackend, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if !assert.NoError(t, err, "Create database mock") {
+		t.FailNow()
+	}
+	return &SQLBackend{
+		db:      db,
+		dialect: newSQLDialect(driver, "prebid_cache"),
+		now:     func() time.Time { return time.Unix(sqlTestNow, 0) },
+	}, mock
+}
+
+func TestSQLGet(t *testing.T) {
+	const query = "SELECT cache_value FROM prebid_cache WHERE cache_key = $1 AND (expires_at IS NULL OR expires_at > $2)"
+
+	testCases := []struct {
+		desc          string
+		inRows        *sqlmock.Rows
+		inErr         error
+		expectedValue string
+		expectedErr   error
+	}{
+		{
+			desc:          "Value found",
+			inRows:        sqlmock.NewRows([]string{"cache_value"}).AddRow("value"),
+			expectedValue: "value",
+		},
+		{
+			desc:        "Value not found or expired",
+			inRows:      sqlmock.NewRows([]string{"cache_value"}),
+			expectedErr: utils.NewPBCError(utils.KEY_NOT_FOUND),
+		},
+		{
+			desc:        "Request deadline exceeded",
+			inErr:       context.DeadlineExceeded,
+			expectedErr: utils.NewPBCError(utils.GET_DEADLINE_EXCEEDED),
+		},
+		{
+			desc:        "Database error",
+			inErr:       errors.New("connection refused"),
+			expectedErr: errors.New("connection refused"),
+		},
+	}
+
+	for _, test := range testCases {
+		backend, mock := newTestSQLBackend(t, config.SQLPostgres)
+		expectation := mock.ExpectQuery(query).WithArgs("key", sqlTestNow)
+		if test.inErr != nil {
+			expectation.WillReturnError(test.inErr)
+		} else {
+			expectation.WillReturnRows(test.inRows)
+		}
+
+		value, err := backend.Get(context.Background(), "key")
+
+		assert.Equal(t, test.expectedValue, value, test.desc)
+		assert.Equal(t, test.expectedErr, err, test.desc)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.desc)
+	}
+}
+
+func TestSQLPut(t *testing.T) {
+	const postgresPut = "INSERT INTO prebid_cache (cache_key, cache_value, expires_at) VALUES ($1, $2, $3) ON CONFLICT (cache_key) DO UPDATE " +
+		"SET cache_value = EXCLUDED.cache_value, expires_at = EXCLUDED.expires_at " +
+		"WHERE prebid_cache.expires_at IS NOT NULL AND prebid_cache.expires_at <= $4"
+	const mysqlPut = "INSERT INTO prebid_cache (cache_key, cache_value, expires_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE " +
+		"cache_value = IF(expires_at IS NOT NULL AND expires_at <= ?, VALUES(cache_value), cache_value), " +
+		"expires_at = IF(expires_at IS NOT NULL AND expires_at <= ?, VALUES(expires_at), expires_at)"
+
+	testCases := []struct {
+		desc         string
+		inDriver     config.SQLDriver
+		inTTLSeconds int
+		inResult     sql.Result
+		inErr        error
+		expectedArgs []driver.Value
+		expectedErr  error
+	}{
+		{
+			desc:         "Postgres row inserted or expired row replaced",
+			inDriver:     config.SQLPostgres,
+			inTTLSeconds: 60,
+			inResult:     sqlmock.NewResult(0, 1),
+			expectedArgs: []driver.Value{"key", "value", sqlTestNow + 60, sqlTestNow},
+		},
+		{
+			desc:         "Postgres row holding a value that hasn't expired",
+			inDriver:     config.SQLPostgres,
+			inTTLSeconds: 60,
+			inResult:     sqlmock.NewResult(0, 0),
+			expectedArgs: []driver.Value{"key", "value", sqlTestNow + 60, sqlTestNow},
+			expectedErr:  utils.NewPBCError(utils.RECORD_EXISTS),
+		},
+		{
+			desc:         "Value stored without a TTL never expires",
+			inDriver:     config.SQLPostgres,
+			inResult:     sqlmock.NewResult(0, 1),
+			expectedArgs: []driver.Value{"key", "value", nil, sqlTestNow},
+		},
+		{
+			desc:         "MySQL expired row replaced",
+			inDriver:     config.SQLMySQL,
+			inTTLSeconds: 60,
+			inResult:     sqlmock.NewResult(0, 2),
+			expectedArgs: []driver.Value{"key", "value", sqlTestNow + 60, sqlTestNow, sqlTestNow},
+		},
+		{
+			desc:         "MySQL row holding a value that hasn't expired",
+			inDriver:     config.SQLMySQL,
+			inTTLSeconds: 60,
+			inResult:     sqlmock.NewResult(0, 0),
+			expectedArgs: []driver.Value{"key", "value", sqlTestNow + 60, sqlTestNow, sqlTestNow},
+			expectedErr:  utils.NewPBCError(utils.RECORD_EXISTS),
+		},
+		{
+			desc:         "Request deadline exceeded",
+			inDriver:     config.SQLPostgres,
+			inTTLSeconds: 60,
+			inErr:        context.DeadlineExceeded,
+			expectedArgs: []driver.Value{"key", "value", sqlTestNow + 60, sqlTestNow},
+			expectedErr:  utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+		},
+	}
+
+	for _, test := range testCases {
+		backend, mock := newTestSQLBackend(t, test.inDriver)
+		query := postgresPut
+		if test.inDriver == config.SQLMySQL {
+			query = mysqlPut
+		}
+		expectation := mock.ExpectExec(query).WithArgs(test.expectedArgs...)
+		if test.inErr != nil {
+			expectation.WillReturnError(test.inErr)
+		} else {
+			expectation.WillReturnResult(test.inResult)
+		}
+
+		err := backend.Put(context.Background(), "key", "value", test.inTTLSeconds)
+
+		assert.Equal(t, test.expectedErr, err, test.desc)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.desc)
+	}
+}
+
+func TestSQLDelete(t *testing.T) {
+	const statement = "DELETE FROM prebid_cache WHERE cache_key = ? AND (expires_at IS NULL OR expires_at > ?)"
+
+	testCases := []struct {
+		desc        string
+		inResult    sql.Result
+		inErr       error
+		expectedErr error
+	}{
+		{
+			desc:     "Row deleted",
+			inResult: sqlmock.NewResult(0, 1),
+		},
+		{
+			desc:        "No row or expired value",
+			inResult:    sqlmock.NewResult(0, 0),
+			expectedErr: utils.NewPBCError(utils.KEY_NOT_FOUND),
+		},
+		{
+			desc:        "Request deadline exceeded",
+			inErr:       context.DeadlineExceeded,
+			expectedErr: utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+		},
+	}
+
+	for _, test := range testCases {
+		backend, mock := newTestSQLBackend(t, config.SQLMySQL)
+		expectation := mock.ExpectExec(statement).WithArgs("key", sqlTestNow)
+		if test.inErr != nil {
+			expectation.WillReturnError(test.inErr)
+		} else {
+			expectation.WillReturnResult(test.inResult)
+		}
+
+		assert.Equal(t, test.expectedErr, backend.Delete(context.Background(), "key"), test.desc)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.desc)
+	}
+}
+
+func TestSQLKeyLength(t *testing.T) {
+	backend, mock := newTestSQLBackend(t, config.SQLPostgres)
+	longKey := strings.Repeat("k", sqlMaxKeyLength+1)
+	expectedErr := utils.NewPBCError(utils.KEY_LENGTH, "Key length 256 exceeded SQL backend max key length 255")
+
+	_, err := backend.Get(context.Background(), longKey)
+	assert.Equal(t, expectedErr, err, "Get")
+	assert.Equal(t, expectedErr, backend.Put(context.Background(), longKey, "value", 60), "Put")
+	assert.Equal(t, expectedErr, backend.Delete(context.Background(), longKey), "Delete")
+	assert.NoError(t, mock.ExpectationsWereMet(), "Keys that are too long don't reach the database")
+}
+
+func TestSQLSweep(t *testing.T) {
+	backend, mock := newTestSQLBackend(t, config.SQLPostgres)
+	mock.ExpectExec("DELETE FROM prebid_cache WHERE expires_at <= $1").WithArgs(sqlTestNow).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	deleted, err := backend.sweep(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLSchemaStatements(t *testing.T) {
+	testCases := []struct {
+		desc               string
+		inDriver           config.SQLDriver
+		expectedStatements []string
+	}{
+		{
+			desc:     "PostgreSQL",
+			inDriver: config.SQLPostgres,
+			expectedStatements: []string{
+				"CREATE TABLE IF NOT EXISTS cache_values (cache_key VARCHAR(255) PRIMARY KEY, cache_value TEXT NOT NULL, expires_at BIGINT)",
+				"CREATE INDEX IF NOT EXISTS cache_values_expires_at ON cache_values (expires_at)",
+			},
+		},
+		{
+			desc:     "MySQL",
+			inDriver: config.SQLMySQL,
+			expectedStatements: []string{
+				"CREATE TABLE IF NOT EXISTS cache_values (cache_key VARCHAR(255) NOT NULL PRIMARY KEY, cache_value MEDIUMTEXT NOT NULL, expires_at BIGINT NULL, INDEX cache_values_expires_at (expires_at))",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		assert.Equal(t, test.expectedStatements, newSQLDialect(test.inDriver, "cache_values").schema, test.desc)
+	}
+}
@@ -3,18 +3,28 @@ package backends
 import (
 	"context"
 	"errors"
+	"sync"
 
 	as "github.com/aerospike/aerospike-client-go/v7"
 	as_types "github.com/aerospike/aerospike-client-go/v7/types"
+	"github.com/go-redis/redis/v8"
 	"github.com/google/gomemcache/memcache"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/utils"
 )
 
 // ------------------------------------------
 // Aerospike client mocks
 // ------------------------------------------
+
+// Set and bin the Aerospike client mocks store records under
+const (
+	setName  = utils.AEROSPIKE_DEFAULT_SET_NAME
+	binValue = utils.AEROSPIKE_DEFAULT_BIN_NAME
+)
+
 func NewMockAerospikeBackend(mockClient AerospikeDB) *AerospikeBackend {
-	return &AerospikeBackend{client: mockClient}
+	return &AerospikeBackend{client: mockClient, binName: binValue}
 }
 
 type ErrorProneAerospikeClient struct {
@@ -28,9 +38,11 @@ func (c *ErrorProneAerospikeClient) NewUUIDKey(namespace string, key string) (*a
 	return nil, nil
 }
 
-func (c *ErrorProneAerospikeClient) Get(key *as.Key) (*as.Record, error) {
+func (c *ErrorProneAerospikeClient) Get(policy *as.BasePolicy, key *as.Key) (*as.Record, error) {
 	if c.ServerError == "TEST_GET_ERROR" {
 		return nil, &as.AerospikeError{ResultCode: as_types.KEY_NOT_FOUND_ERROR}
+	} else if c.ServerError == "TEST_TIMEOUT_ERROR" {
+		return nil, as.ErrTimeout
 	} else if c.ServerError == "TEST_NO_BUCKET_ERROR" {
 		return &as.Record{Bins: as.BinMap{"AnyKey": "any_value"}}, nil
 	} else if c.ServerError == "TEST_NON_STRING_VALUE_ERROR" {
@@ -42,16 +54,50 @@ func (c *ErrorProneAerospikeClient) Get(key *as.Key) (*as.Record, error) {
 func (c *ErrorProneAerospikeClient) Put(policy *as.WritePolicy, key *as.Key, binMap as.BinMap) error {
 	if c.ServerError == "TEST_PUT_ERROR" {
 		return &as.AerospikeError{ResultCode: as_types.KEY_EXISTS_ERROR}
+	} else if c.ServerError == "TEST_TIMEOUT_ERROR" {
+		return &as.AerospikeError{ResultCode: as_types.TIMEOUT}
 	}
 	return nil
 }
 
+func (c *ErrorProneAerospikeClient) Delete(policy *as.WritePolicy, key *as.Key) (bool, error) {
+	if c.ServerError == "TEST_DELETE_ERROR" {
+		return false, &as.AerospikeError{ResultCode: as_types.SERVER_NOT_AVAILABLE}
+	} else if c.ServerError == "TEST_TIMEOUT_ERROR" {
+		return false, &as.AerospikeError{ResultCode: as_types.TIMEOUT}
+	}
+	return false, nil
+}
+
+func (c *ErrorProneAerospikeClient) BatchPut(policy *as.BatchPolicy, policies []*as.BatchWritePolicy, keys []*as.Key, binMaps []as.BinMap) []error {
+	errs := make([]error, len(keys))
+	if c.ServerError == "TEST_PUT_ERROR" {
+		for i := range errs {
+			errs[i] = &as.AerospikeError{ResultCode: as_types.KEY_EXISTS_ERROR}
+		}
+	} else if c.ServerError == "TEST_TIMEOUT_ERROR" {
+		for i := range errs {
+			errs[i] = as.ErrTimeout
+		}
+	}
+	return errs
+}
+
+func (c *ErrorProneAerospikeClient) BatchGet(policy *as.BatchPolicy, keys []*as.Key) ([]*as.Record, error) {
+	if c.ServerError == "TEST_GET_ERROR" {
+		return nil, &as.AerospikeError{ResultCode: as_types.SERVER_NOT_AVAILABLE}
+	} else if c.ServerError == "TEST_TIMEOUT_ERROR" {
+		return nil, as.ErrTimeout
+	}
+	return make([]*as.Record, len(keys)), nil
+}
+
 // Aerospike client that does not throw errors
 type GoodAerospikeClient struct {
 	StoredData map[string]string
 }
 
-func (c *GoodAerospikeClient) Get(aeKey *as.Key) (*as.Record, error) {
+func (c *GoodAerospikeClient) Get(policy *as.BasePolicy, aeKey *as.Key) (*as.Record, error) {
 	if aeKey != nil && aeKey.Value() != nil {
 		key := aeKey.Value().String()
 
@@ -79,6 +125,41 @@ func (c *GoodAerospikeClient) Put(policy *as.WritePolicy, aeKey *as.Key, binMap
 	return &as.AerospikeError{ResultCode: as_types.KEY_MISMATCH}
 }
 
+func (c *GoodAerospikeClient) Delete(policy *as.WritePolicy, aeKey *as.Key) (bool, error) {
+	if aeKey != nil && aeKey.Value() != nil {
+		key := aeKey.Value().String()
+		_, found := c.StoredData[key]
+		delete(c.StoredData, key)
+		return found, nil
+	}
+	return false, &as.AerospikeError{ResultCode: as_types.KEY_MISMATCH}
+}
+
+func (c *GoodAerospikeClient) BatchPut(policy *as.BatchPolicy, policies []*as.BatchWritePolicy, keys []*as.Key, binMaps []as.BinMap) []error {
+	errs := make([]error, len(keys))
+	for i, aeKey := range keys {
+		key := aeKey.Value().String()
+		if _, found := c.StoredData[key]; found && policies[i].RecordExistsAction == as.CREATE_ONLY {
+			errs[i] = &as.AerospikeError{ResultCode: as_types.KEY_EXISTS_ERROR}
+			continue
+		}
+		if str, asserted := binMaps[i][binValue].(string); asserted {
+			c.StoredData[key] = str
+		}
+	}
+	return errs
+}
+
+func (c *GoodAerospikeClient) BatchGet(policy *as.BatchPolicy, keys []*as.Key) ([]*as.Record, error) {
+	records := make([]*as.Record, len(keys))
+	for i, aeKey := range keys {
+		if value, found := c.StoredData[aeKey.Value().String()]; found {
+			records[i] = &as.Record{Bins: as.BinMap{binValue: value}}
+		}
+	}
+	return records, nil
+}
+
 func (c *GoodAerospikeClient) NewUUIDKey(namespace string, key string) (*as.Key, error) {
 	return as.NewKey(namespace, setName, key)
 }
@@ -110,9 +191,27 @@ func (ec *ErrorProneCassandraClient) Put(ctx context.Context, key string, value
 	return ec.Applied, ec.ServerError
 }
 
+func (ec *ErrorProneCassandraClient) Delete(ctx context.Context, key string) (bool, error) {
+	return ec.Applied, ec.ServerError
+}
+
+func (ec *ErrorProneCassandraClient) GetBatch(ctx context.Context, keys []string) (map[string]string, error) {
+	return nil, ec.ServerError
+}
+
+func (ec *ErrorProneCassandraClient) PutBatch(ctx context.Context, items []BatchPutItem) error {
+	return ec.ServerError
+}
+
 // Cassandra client client that does not throw errors
 type GoodCassandraClient struct {
 	StoredData map[string]string
+	// Deletes are reported as applied even for missing keys in "plain" mode, like
+	// CassandraDBClient does
+	WriteMode config.CassandraWriteMode
+	// Number of PutBatch calls received
+	Batches int
+	mu      sync.Mutex
 }
 
 func (gc *GoodCassandraClient) Init() error {
@@ -120,6 +219,9 @@ func (gc *GoodCassandraClient) Init() error {
 }
 
 func (gc *GoodCassandraClient) Get(ctx context.Context, key string) (string, error) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
 	if value, found := gc.StoredData[key]; found {
 		return value, nil
 	}
@@ -127,12 +229,48 @@ func (gc *GoodCassandraClient) Get(ctx context.Context, key string) (string, err
 }
 
 func (gc *GoodCassandraClient) Put(ctx context.Context, key string, value string, ttlSeconds int) (bool, error) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
 	if _, found := gc.StoredData[key]; !found {
 		gc.StoredData[key] = value
 	}
 	return true, nil
 }
 
+func (gc *GoodCassandraClient) Delete(ctx context.Context, key string) (bool, error) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	_, found := gc.StoredData[key]
+	delete(gc.StoredData, key)
+	return found || gc.WriteMode == config.CassandraWritePlain, nil
+}
+
+func (gc *GoodCassandraClient) GetBatch(ctx context.Context, keys []string) (map[string]string, error) {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	values := make(map[string]string, len(keys))
+	for _, key := range keys {
+		if value, found := gc.StoredData[key]; found {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+func (gc *GoodCassandraClient) PutBatch(ctx context.Context, items []BatchPutItem) error {
+	gc.mu.Lock()
+	defer gc.mu.Unlock()
+
+	gc.Batches++
+	for _, item := range items {
+		gc.StoredData[item.Key] = item.Value
+	}
+	return nil
+}
+
 // ------------------------------------------
 // Memcache client mocks
 // ------------------------------------------
@@ -154,6 +292,14 @@ func (ec *ErrorProneMemcache) Put(key string, value string, ttlSeconds int) erro
 	return ec.ServerError
 }
 
+func (ec *ErrorProneMemcache) Delete(key string) error {
+	return ec.ServerError
+}
+
+func (ec *ErrorProneMemcache) GetMulti(keys []string) (map[string]*memcache.Item, error) {
+	return nil, ec.ServerError
+}
+
 // Memcache client that does not throw errors
 type GoodMemcache struct {
 	StoredData map[string]string
@@ -163,7 +309,7 @@ func (gm *GoodMemcache) Get(key string) (*memcache.Item, error) {
 	if value, found := gm.StoredData[key]; found {
 		return &memcache.Item{Key: key, Value: []byte(value)}, nil
 	}
-	return nil, utils.NewPBCError(utils.KEY_NOT_FOUND)
+	return nil, memcache.ErrCacheMiss
 }
 
 func (gm *GoodMemcache) Put(key string, value string, ttlSeconds int) error {
@@ -173,6 +319,50 @@ func (gm *GoodMemcache) Put(key string, value string, ttlSeconds int) error {
 	return nil
 }
 
+func (gm *GoodMemcache) Delete(key string) error {
+	if _, found := gm.StoredData[key]; !found {
+		return memcache.ErrCacheMiss
+	}
+	delete(gm.StoredData, key)
+	return nil
+}
+
+func (gm *GoodMemcache) GetMulti(keys []string) (map[string]*memcache.Item, error) {
+	items := make(map[string]*memcache.Item, len(keys))
+	for _, key := range keys {
+		if value, found := gm.StoredData[key]; found {
+			items[key] = &memcache.Item{Key: key, Value: []byte(value)}
+		}
+	}
+	return items, nil
+}
+
+// Memcache client that doesn't answer until Release gets closed, as a server that
+// stopped responding would
+type UnresponsiveMemcache struct {
+	Release chan struct{}
+}
+
+func (um *UnresponsiveMemcache) Get(key string) (*memcache.Item, error) {
+	<-um.Release
+	return nil, memcache.ErrCacheMiss
+}
+
+func (um *UnresponsiveMemcache) Put(key string, value string, ttlSeconds int) error {
+	<-um.Release
+	return nil
+}
+
+func (um *UnresponsiveMemcache) Delete(key string) error {
+	<-um.Release
+	return nil
+}
+
+func (um *UnresponsiveMemcache) GetMulti(keys []string) (map[string]*memcache.Item, error) {
+	<-um.Release
+	return map[string]*memcache.Item{}, nil
+}
+
 // ------------------------------------------
 // Redis client mocks
 // ------------------------------------------
@@ -206,6 +396,48 @@ func (r FakeRedisClient) Put(ctx context.Context, key string, value string, ttlS
 	return r.Success, r.ServerError
 }
 
+func (r FakeRedisClient) Del(ctx context.Context, key string) (int64, error) {
+	if r.ServerError != nil {
+		return 0, r.ServerError
+	}
+	if _, found := r.StoredData[key]; !found {
+		return 0, nil
+	}
+	delete(r.StoredData, key)
+	return 1, nil
+}
+
+func (r FakeRedisClient) PutBatch(ctx context.Context, items []BatchPutItem) ([]bool, []error) {
+	results := make([]bool, len(items))
+	errs := make([]error, len(items))
+	for i, item := range items {
+		if r.ServerError != nil {
+			errs[i] = r.ServerError
+			continue
+		}
+		if _, found := r.StoredData[item.Key]; !found {
+			r.StoredData[item.Key] = item.Value
+			results[i] = true
+		}
+	}
+	return results, errs
+}
+
+func (r FakeRedisClient) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+	for i, key := range keys {
+		if r.ServerError != nil {
+			errs[i] = r.ServerError
+		} else if value, found := r.StoredData[key]; found {
+			values[i] = value
+		} else {
+			errs[i] = redis.Nil
+		}
+	}
+	return values, errs
+}
+
 // ------------------------------------------
 // Memory client mocks
 // ------------------------------------------
@@ -223,9 +455,13 @@ func (ec *ErrorProneMemoryClient) Put(ctx context.Context, key string, value str
 	return errors.New("Bakend error")
 }
 
+func (ec *ErrorProneMemoryClient) Delete(ctx context.Context, key string) error {
+	return errors.New("Bakend error")
+}
+
 // Good memory client does not throw errors
 func NewMemoryBackendWithValues(customData map[string]string) (*MemoryBackend, error) {
-	backend := NewMemoryBackend()
+	backend := NewMemoryBackend(config.Memory{})
 
 	if len(customData) > 0 {
 		for k, v := range customData {
@@ -0,0 +1,151 @@
+package backends
+
+import (
+	"context"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// TieredBackend keeps a local memory copy (L1) of the values recently written to or read from
+// a remote backend (L2), so that reads following a write on the same instance are served
+// without a network round trip. L2 remains the source of truth: writes go through to it first
+// and values only get copied locally once it accepted them.
+//
+// Local copies are served for at most config.backend.tiered.l1.ttl_seconds, which bounds how
+// long a value deleted from L2, or from the L1 of another instance, can still be read here.
+type TieredBackend struct {
+	l1      *MemoryBackend
+	l2      Backend
+	l1TTL   int
+	metrics *metrics.Metrics
+}
+
+// NewTieredBackend creates the local memory tier described by cfg.L1 in front of l2, which
+// must have been built from the backend section cfg.L2.Backend
+func NewTieredBackend(cfg config.Tiered, l2 Backend, metrics *metrics.Metrics) *TieredBackend {
+	return &TieredBackend{
+		l1: NewMemoryBackend(config.Memory{
+			MaxItems:             cfg.L1.MaxItems,
+			MaxBytes:             cfg.L1.MaxBytes,
+			SweepIntervalSeconds: utils.MEMORY_SWEEP_INTERVAL_SECONDS,
+		}),
+		l2:      l2,
+		l1TTL:   cfg.L1.TTLSeconds,
+		metrics: metrics,
+	}
+}
+
+// Get serves key from the local copy if there is one. Otherwise it reads from L2 and copies
+// the value locally
+func (b *TieredBackend) Get(ctx context.Context, key string) (string, error) {
+	if value, err := b.l1.Get(ctx, key); err == nil {
+		b.metrics.RecordTieredHit(config.CacheTierL1)
+		return value, nil
+	}
+
+	value, err := b.l2.Get(ctx, key)
+	if err != nil {
+		if isKeyNotFound(err) {
+			b.metrics.RecordTieredMiss()
+		}
+		return "", err
+	}
+
+	b.metrics.RecordTieredHit(config.CacheTierL2)
+	b.storeLocally(key, value, 0)
+	return value, nil
+}
+
+// Put writes value to L2 and, if it got stored, keeps a local copy of it
+func (b *TieredBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
+	if err := b.l2.Put(ctx, key, value, ttlSeconds); err != nil {
+		return err
+	}
+
+	b.storeLocally(key, value, ttlSeconds)
+	return nil
+}
+
+// Delete removes key from both tiers. The outcome is the one L2 reports
+func (b *TieredBackend) Delete(ctx context.Context, key string) error {
+	b.l1.Delete(ctx, key)
+	return b.l2.Delete(ctx, key)
+}
+
+// GetBatch serves the keys that have a local copy and requests the rest from L2 together
+func (b *TieredBackend) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	values := make([]string, len(keys))
+	errs := make([]error, len(keys))
+
+	var remoteKeys []string
+	var remoteIndexes []int
+	for i, key := range keys {
+		if value, err := b.l1.Get(ctx, key); err == nil {
+			b.metrics.RecordTieredHit(config.CacheTierL1)
+			values[i] = value
+		} else {
+			remoteKeys = append(remoteKeys, key)
+			remoteIndexes = append(remoteIndexes, i)
+		}
+	}
+	if len(remoteKeys) == 0 {
+		return values, errs
+	}
+
+	remoteValues, remoteErrs := GetBatch(ctx, b.l2, remoteKeys)
+	for j, i := range remoteIndexes {
+		values[i], errs[i] = remoteValues[j], remoteErrs[j]
+		if errs[i] == nil {
+			b.metrics.RecordTieredHit(config.CacheTierL2)
+			b.storeLocally(keys[i], values[i], 0)
+		} else if isKeyNotFound(errs[i]) {
+			b.metrics.RecordTieredMiss()
+		}
+	}
+	return values, errs
+}
+
+// PutBatch writes every item to L2 and keeps a local copy of the ones that got stored
+func (b *TieredBackend) PutBatch(ctx context.Context, items []BatchPutItem) []error {
+	errs := PutBatch(ctx, b.l2, items)
+	for i, item := range items {
+		if errs[i] == nil {
+			b.storeLocally(item.Key, item.Value, item.TTLSeconds)
+		}
+	}
+	return errs
+}
+
+// HealthCheck checks L2, since the local copy is always available
+func (b *TieredBackend) HealthCheck(ctx context.Context) error {
+	return HealthCheck(ctx, b.l2)
+}
+
+// storeLocally copies value to L1 for config.backend.tiered.l1.ttl_seconds, or for ttlSeconds
+// if the value expires sooner than that. Since L2 just accepted or served the value, a local
+// copy left behind by an earlier value under the same key gets replaced. Failing to store the
+// copy, because it's larger than the local byte limit, only means reads will go to L2
+func (b *TieredBackend) storeLocally(key string, value string, ttlSeconds int) {
+	ttl := b.l1TTL
+	if ttlSeconds > 0 && ttlSeconds < ttl {
+		ttl = ttlSeconds
+	}
+
+	ctx := context.Background()
+	if err := b.l1.Put(ctx, key, value, ttl); isRecordExists(err) {
+		b.l1.Delete(ctx, key)
+		b.l1.Put(ctx, key, value, ttl)
+	}
+}
+
+func isKeyNotFound(err error) bool {
+	pbcErr, isPBCErr := err.(utils.PBCError)
+	return isPBCErr && pbcErr.Type == utils.KEY_NOT_FOUND
+}
+
+func isRecordExists(err error) bool {
+	pbcErr, isPBCErr := err.(utils.PBCError)
+	return isPBCErr && pbcErr.Type == utils.RECORD_EXISTS
+}
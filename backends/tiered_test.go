@@ -0,0 +1,233 @@
+package backends
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestTieredBackend returns a tiered backend with a 60 second L1 TTL in front of an
+// unlimited memory backend, along with the latter and the mock metrics the former records to
+func newTestTieredBackend() (*TieredBackend, *MemoryBackend, *metricstest.MockMetrics) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	l2 := NewMemoryBackend(config.Memory{})
+	tiered := NewTieredBackend(config.Tiered{L1: config.TieredL1{TTLSeconds: 60}}, l2, &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	})
+	return tiered, l2, &mockMetrics
+}
+
+func TestTieredGet(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		inL1Data        map[string]string
+		inL2Data        map[string]string
+		expectedValue   string
+		expectedErr     error
+		expectedMetrics []string
+		expectedL1Value string
+	}{
+		{
+			desc:            "Value held locally isn't requested from L2",
+			inL1Data:        map[string]string{"key": "local value"},
+			inL2Data:        map[string]string{"key": "remote value"},
+			expectedValue:   "local value",
+			expectedMetrics: []string{"RecordTieredHit"},
+			expectedL1Value: "local value",
+		},
+		{
+			desc:            "Value only found in L2 gets copied locally",
+			inL2Data:        map[string]string{"key": "remote value"},
+			expectedValue:   "remote value",
+			expectedMetrics: []string{"RecordTieredHit"},
+			expectedL1Value: "remote value",
+		},
+		{
+			desc:            "Value found in neither tier",
+			expectedErr:     utils.NewPBCError(utils.KEY_NOT_FOUND),
+			expectedMetrics: []string{"RecordTieredMiss"},
+		},
+	}
+
+	for _, test := range testCases {
+		tiered, l2, mockMetrics := newTestTieredBackend()
+		for k, v := range test.inL1Data {
+			tiered.l1.Put(context.Background(), k, v, 0)
+		}
+		for k, v := range test.inL2Data {
+			l2.Put(context.Background(), k, v, 0)
+		}
+
+		value, err := tiered.Get(context.Background(), "key")
+
+		assert.Equal(t, test.expectedValue, value, test.desc)
+		assert.Equal(t, test.expectedErr, err, test.desc)
+		metricstest.AssertMetrics(t, test.expectedMetrics, *mockMetrics)
+
+		l1Value, _ := tiered.l1.Get(context.Background(), "key")
+		assert.Equal(t, test.expectedL1Value, l1Value, test.desc)
+	}
+}
+
+func TestTieredPut(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		inL1Data        map[string]string
+		inL2Data        map[string]string
+		expectedErr     error
+		expectedL1Value string
+		expectedL2Value string
+	}{
+		{
+			desc:            "Value gets written to both tiers",
+			expectedL1Value: "new value",
+			expectedL2Value: "new value",
+		},
+		{
+			desc:            "Value rejected by L2 isn't copied locally",
+			inL2Data:        map[string]string{"key": "remote value"},
+			expectedErr:     utils.NewPBCError(utils.RECORD_EXISTS),
+			expectedL2Value: "remote value",
+		},
+		{
+			desc:            "Value accepted by L2 replaces a stale local copy",
+			inL1Data:        map[string]string{"key": "stale value"},
+			expectedL1Value: "new value",
+			expectedL2Value: "new value",
+		},
+	}
+
+	for _, test := range testCases {
+		tiered, l2, _ := newTestTieredBackend()
+		for k, v := range test.inL1Data {
+			tiered.l1.Put(context.Background(), k, v, 0)
+		}
+		for k, v := range test.inL2Data {
+			l2.Put(context.Background(), k, v, 0)
+		}
+
+		err := tiered.Put(context.Background(), "key", "new value", 0)
+
+		assert.Equal(t, test.expectedErr, err, test.desc)
+		l1Value, _ := tiered.l1.Get(context.Background(), "key")
+		assert.Equal(t, test.expectedL1Value, l1Value, test.desc)
+		l2Value, _ := l2.Get(context.Background(), "key")
+		assert.Equal(t, test.expectedL2Value, l2Value, test.desc)
+	}
+}
+
+func TestTieredLocalTTL(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		inTTLSeconds  int
+		inElapsed     time.Duration
+		expectedFound bool
+	}{
+		{
+			desc:          "Local copy is served within the L1 TTL",
+			inTTLSeconds:  3600,
+			inElapsed:     59 * time.Second,
+			expectedFound: true,
+		},
+		{
+			desc:         "Local copy expires after the L1 TTL even if the value lives longer",
+			inTTLSeconds: 3600,
+			inElapsed:    60 * time.Second,
+		},
+		{
+			desc:          "Values stored without a TTL are copied for the L1 TTL",
+			inTTLSeconds:  0,
+			inElapsed:     59 * time.Second,
+			expectedFound: true,
+		},
+		{
+			desc:         "Local copy expires along with values shorter lived than the L1 TTL",
+			inTTLSeconds: 10,
+			inElapsed:    10 * time.Second,
+		},
+	}
+
+	for _, test := range testCases {
+		tiered, _, _ := newTestTieredBackend()
+		start := time.Now()
+		tiered.l1.now = func() time.Time { return start }
+
+		assert.NoError(t, tiered.Put(context.Background(), "key", "value", test.inTTLSeconds), test.desc)
+
+		tiered.l1.now = func() time.Time { return start.Add(test.inElapsed) }
+		_, err := tiered.l1.Get(context.Background(), "key")
+		assert.Equal(t, test.expectedFound, err == nil, test.desc)
+	}
+}
+
+func TestTieredDelete(t *testing.T) {
+	tiered, l2, _ := newTestTieredBackend()
+	assert.NoError(t, tiered.Put(context.Background(), "key", "value", 0))
+
+	assert.NoError(t, tiered.Delete(context.Background(), "key"))
+
+	_, err := tiered.l1.Get(context.Background(), "key")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Local copy")
+	_, err = l2.Get(context.Background(), "key")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "L2 value")
+
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), tiered.Delete(context.Background(), "key"), "Deleting a missing key reports what L2 does")
+}
+
+func TestTieredGetBatch(t *testing.T) {
+	tiered, l2, mockMetrics := newTestTieredBackend()
+	tiered.l1.Put(context.Background(), "local", "local value", 0)
+	l2.Put(context.Background(), "remote", "remote value", 0)
+
+	values, errs := tiered.GetBatch(context.Background(), []string{"local", "remote", "missing"})
+
+	assert.Equal(t, []string{"local value", "remote value", ""}, values)
+	assert.Equal(t, []error{nil, nil, utils.NewPBCError(utils.KEY_NOT_FOUND)}, errs)
+	metricstest.AssertMetrics(t, []string{"RecordTieredHit", "RecordTieredMiss"}, *mockMetrics)
+
+	l1Value, err := tiered.l1.Get(context.Background(), "remote")
+	assert.NoError(t, err, "Remote value gets copied locally")
+	assert.Equal(t, "remote value", l1Value, "Remote value gets copied locally")
+}
+
+func TestTieredPutBatch(t *testing.T) {
+	tiered, l2, _ := newTestTieredBackend()
+	l2.Put(context.Background(), "existing", "remote value", 0)
+
+	errs := tiered.PutBatch(context.Background(), []BatchPutItem{
+		{Key: "new", Value: "new value"},
+		{Key: "existing", Value: "new value"},
+	})
+
+	assert.Equal(t, []error{nil, utils.NewPBCError(utils.RECORD_EXISTS)}, errs)
+	l1Value, _ := tiered.l1.Get(context.Background(), "new")
+	assert.Equal(t, "new value", l1Value, "Stored item gets copied locally")
+	_, err := tiered.l1.Get(context.Background(), "existing")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Rejected item isn't copied locally")
+}
+
+func TestTieredL2Errors(t *testing.T) {
+	mockMetrics := metricstest.CreateMockMetrics()
+	tiered := NewTieredBackend(config.Tiered{L1: config.TieredL1{TTLSeconds: 60}}, NewErrorResponseMemoryBackend(), &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	})
+
+	_, err := tiered.Get(context.Background(), "key")
+	assert.Equal(t, errors.New("Bakend error"), err, "Get")
+	assert.Equal(t, errors.New("Bakend error"), tiered.Put(context.Background(), "key", "value", 0), "Put")
+	metricstest.AssertMetrics(t, []string{}, mockMetrics)
+
+	_, err = tiered.l1.Get(context.Background(), "key")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err, "Values L2 failed to store aren't copied locally")
+}
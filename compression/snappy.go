@@ -29,6 +29,22 @@ func (s *snappyCompressor) Put(ctx context.Context, key string, value string, tt
 	return p
 }
 
+func (s *snappyCompressor) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+	startTime := time.Now()
+	compressed := make([]backends.BatchPutItem, len(items))
+	for i, item := range items {
+		compressed[i] = item
+		compressed[i].Value = string(snappy.Encode(nil, []byte(item.Value)))
+	}
+	errs := backends.PutBatch(ctx, s.delegate, compressed)
+	logger.Info("Time for snappy batch put: %v", time.Now().Sub(startTime))
+	return errs
+}
+
+func (s *snappyCompressor) Delete(ctx context.Context, key string) error {
+	return s.delegate.Delete(ctx, key)
+}
+
 func (s *snappyCompressor) Get(ctx context.Context, key string) (string, error) {
 	start := time.Now()
 	compressed, err := s.delegate.Get(ctx, key)
@@ -46,3 +62,29 @@ func (s *snappyCompressor) Get(ctx context.Context, key string) (string, error)
 
 	return string(decompressed), nil
 }
+
+// HealthCheck will simply make the delegate health check given that it stores no value
+func (s *snappyCompressor) HealthCheck(ctx context.Context) error {
+	return backends.HealthCheck(ctx, s.delegate)
+}
+
+func (s *snappyCompressor) GetBatch(ctx context.Context, keys []string) ([]string, []error) {
+	start := time.Now()
+	values, errs := backends.GetBatch(ctx, s.delegate, keys)
+	for i := range values {
+		if errs[i] != nil {
+			values[i] = ""
+			continue
+		}
+
+		decompressed, err := snappy.Decode(nil, []byte(values[i]))
+		if err != nil {
+			values[i], errs[i] = "", err
+			continue
+		}
+		values[i] = string(decompressed)
+	}
+	logger.Info("Time for snappy batch get: %v", time.Now().Sub(start))
+
+	return values, errs
+}
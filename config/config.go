@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"strings"
 	"time"
@@ -35,6 +36,9 @@ func NewConfig(filename string) Configuration {
 	if err := v.Unmarshal(&cfg); err != nil {
 		logger.Fatal("Failed to unmarshal config: %v", err)
 	}
+	if err := setNestedBackendDefaults(v, &cfg); err != nil {
+		logger.Fatal("Failed to unmarshal the nested sections of config.backend: %v", err)
+	}
 
 	cfg.Server.ServerName = utils.GetServerName()
 
@@ -70,17 +74,85 @@ func setConfigDefaults(v *viper.Viper) {
 	v.SetDefault("backend.aerospike.max_write_retries", 0)
 	v.SetDefault("backend.aerospike.connection_idle_timeout_seconds", 0)
 	v.SetDefault("backend.aerospike.connection_queue_size", 0)
+	v.SetDefault("backend.aerospike.set_name", utils.AEROSPIKE_DEFAULT_SET_NAME)
+	v.SetDefault("backend.aerospike.bin_name", utils.AEROSPIKE_DEFAULT_BIN_NAME)
+	v.SetDefault("backend.aerospike.read_replica", "sequence")
+	v.SetDefault("backend.aerospike.rack_id", 0)
+	v.SetDefault("backend.aerospike.read_mode_ap", "one")
+	v.SetDefault("backend.aerospike.send_key", false)
+	v.SetDefault("backend.aerospike.auth_mode", "internal")
+	v.SetDefault("backend.aerospike.tls.enabled", false)
+	v.SetDefault("backend.aerospike.tls.insecure_skip_verify", false)
+	v.SetDefault("backend.aerospike.tls.ca_file", "")
+	v.SetDefault("backend.aerospike.tls.cert_file", "")
+	v.SetDefault("backend.aerospike.tls.key_file", "")
+	v.SetDefault("backend.aerospike.tls.min_version", "1.2")
+	v.SetDefault("backend.aerospike.tls.name", "")
 	v.SetDefault("backend.cassandra.hosts", "")
 	v.SetDefault("backend.cassandra.keyspace", "")
 	v.SetDefault("backend.cassandra.default_ttl_seconds", utils.CASSANDRA_DEFAULT_TTL_SECONDS)
+	v.SetDefault("backend.cassandra.username", "")
+	v.SetDefault("backend.cassandra.password", "")
+	v.SetDefault("backend.cassandra.tls.enabled", false)
+	v.SetDefault("backend.cassandra.tls.insecure_skip_verify", false)
+	v.SetDefault("backend.cassandra.tls.ca_file", "")
+	v.SetDefault("backend.cassandra.tls.cert_file", "")
+	v.SetDefault("backend.cassandra.tls.key_file", "")
+	v.SetDefault("backend.cassandra.tls.min_version", "1.2")
+	v.SetDefault("backend.cassandra.read_consistency", "one")
+	v.SetDefault("backend.cassandra.write_consistency", "local_one")
+	v.SetDefault("backend.cassandra.local_dc", "")
+	v.SetDefault("backend.cassandra.connect_timeout_ms", utils.CASSANDRA_DEFAULT_TIMEOUT_MS)
+	v.SetDefault("backend.cassandra.timeout_ms", utils.CASSANDRA_DEFAULT_TIMEOUT_MS)
+	v.SetDefault("backend.cassandra.retry.max_retries", 0)
+	v.SetDefault("backend.cassandra.retry.min_backoff_ms", 100)
+	v.SetDefault("backend.cassandra.retry.max_backoff_ms", 1000)
+	v.SetDefault("backend.cassandra.write_mode", "lwt")
+	v.SetDefault("backend.cassandra.schema.replication_class", "SimpleStrategy")
+	v.SetDefault("backend.cassandra.schema.replication_factor", 1)
+	v.SetDefault("backend.cassandra.schema.default_ttl_seconds", 0)
+	v.SetDefault("backend.cassandra.schema.compaction_class", "SizeTieredCompactionStrategy")
 	v.SetDefault("backend.memcache.hosts", []string{})
+	v.SetDefault("backend.memcache.timeout_ms", utils.MEMCACHE_DEFAULT_TIMEOUT_MS)
+	v.SetDefault("backend.memcache.max_idle_conns", utils.MEMCACHE_DEFAULT_MAX_IDLE_CONNS)
+	v.SetDefault("backend.tiered.l1.max_items", utils.TIERED_L1_DEFAULT_MAX_ITEMS)
+	v.SetDefault("backend.tiered.l1.max_bytes", 0)
+	v.SetDefault("backend.tiered.l1.ttl_seconds", utils.TIERED_L1_DEFAULT_TTL_SECONDS)
+	v.SetDefault("backend.migration.write_mode", "both")
+	v.SetDefault("backend.sharded.shards", []interface{}{})
+	v.SetDefault("backend.sharded.virtual_nodes", utils.SHARDED_DEFAULT_VIRTUAL_NODES)
+	v.SetDefault("backend.memory.max_items", 0)
+	v.SetDefault("backend.memory.max_bytes", 0)
+	v.SetDefault("backend.memory.sweep_interval_seconds", utils.MEMORY_SWEEP_INTERVAL_SECONDS)
+	v.SetDefault("backend.memory.shards", utils.MEMORY_DEFAULT_SHARDS)
+	v.SetDefault("backend.file.directory", "")
+	v.SetDefault("backend.file.segment_size_bytes", utils.FILE_DEFAULT_SEGMENT_SIZE_BYTES)
+	v.SetDefault("backend.file.compaction_interval_seconds", utils.FILE_DEFAULT_COMPACTION_INTERVAL_SECONDS)
+	v.SetDefault("backend.sql.driver", "")
+	v.SetDefault("backend.sql.dsn", "")
+	v.SetDefault("backend.sql.table", utils.SQL_DEFAULT_TABLE)
+	v.SetDefault("backend.sql.max_open_conns", utils.SQL_DEFAULT_MAX_OPEN_CONNS)
+	v.SetDefault("backend.sql.max_idle_conns", utils.SQL_DEFAULT_MAX_IDLE_CONNS)
+	v.SetDefault("backend.sql.conn_max_lifetime_seconds", 0)
+	v.SetDefault("backend.sql.conn_max_idle_time_seconds", 0)
+	v.SetDefault("backend.sql.sweep_interval_seconds", utils.SQL_DEFAULT_SWEEP_INTERVAL_SECONDS)
+	v.SetDefault("backend.redis.mode", "standalone")
 	v.SetDefault("backend.redis.host", "")
 	v.SetDefault("backend.redis.port", 0)
+	v.SetDefault("backend.redis.addrs", []string{})
+	v.SetDefault("backend.redis.master_name", "")
+	v.SetDefault("backend.redis.sentinel_password", "")
+	v.SetDefault("backend.redis.read_from_replicas", "never")
 	v.SetDefault("backend.redis.password", "")
 	v.SetDefault("backend.redis.db", 0)
 	v.SetDefault("backend.redis.expiration", utils.REDIS_DEFAULT_EXPIRATION_MINUTES)
 	v.SetDefault("backend.redis.tls.enabled", false)
 	v.SetDefault("backend.redis.tls.insecure_skip_verify", false)
+	v.SetDefault("backend.redis.tls.ca_file", "")
+	v.SetDefault("backend.redis.tls.cert_file", "")
+	v.SetDefault("backend.redis.tls.key_file", "")
+	v.SetDefault("backend.redis.tls.server_name", "")
+	v.SetDefault("backend.redis.tls.min_version", "1.2")
 	v.SetDefault("compression.type", "snappy")
 	v.SetDefault("metrics.influx.enabled", false)
 	v.SetDefault("metrics.influx.host", "")
@@ -96,6 +168,34 @@ func setConfigDefaults(v *viper.Viper) {
 	v.SetDefault("metrics.prometheus.enabled", false)
 	v.SetDefault("rate_limiter.enabled", true)
 	v.SetDefault("rate_limiter.num_requests", utils.RATE_LIMITER_NUM_REQUESTS)
+	v.SetDefault("circuit_breaker.enabled", false)
+	v.SetDefault("circuit_breaker.failure_rate_percent", utils.CIRCUIT_BREAKER_FAILURE_RATE_PERCENT)
+	v.SetDefault("circuit_breaker.min_requests", utils.CIRCUIT_BREAKER_MIN_REQUESTS)
+	v.SetDefault("circuit_breaker.window_seconds", utils.CIRCUIT_BREAKER_WINDOW_SECONDS)
+	v.SetDefault("circuit_breaker.open_seconds", utils.CIRCUIT_BREAKER_OPEN_SECONDS)
+	v.SetDefault("circuit_breaker.half_open_requests", utils.CIRCUIT_BREAKER_HALF_OPEN_REQUESTS)
+	v.SetDefault("retry.enabled", false)
+	v.SetDefault("retry.max_read_retries", utils.RETRY_DEFAULT_MAX_READ_RETRIES)
+	v.SetDefault("retry.max_write_retries", 0)
+	v.SetDefault("retry.min_backoff_ms", utils.RETRY_DEFAULT_MIN_BACKOFF_MS)
+	v.SetDefault("retry.max_backoff_ms", utils.RETRY_DEFAULT_MAX_BACKOFF_MS)
+	v.SetDefault("retry.budget_percent", utils.RETRY_DEFAULT_BUDGET_PERCENT)
+	v.SetDefault("retry.hedge_percentile", 0)
+	v.SetDefault("timeouts.backend_read_ms", utils.TIMEOUT_DEFAULT_BACKEND_MS)
+	v.SetDefault("timeouts.backend_write_ms", utils.TIMEOUT_DEFAULT_BACKEND_MS)
+	v.SetDefault("timeouts.backend_connect_ms", utils.TIMEOUT_DEFAULT_BACKEND_MS)
+	v.SetDefault("timeouts.server_read_seconds", utils.TIMEOUT_DEFAULT_SERVER_SECONDS)
+	v.SetDefault("timeouts.server_write_seconds", utils.TIMEOUT_DEFAULT_SERVER_SECONDS)
+	v.SetDefault("timeouts.shutdown_seconds", utils.TIMEOUT_DEFAULT_SHUTDOWN_SECONDS)
+	v.SetDefault("request_coalescing.enabled", false)
+	v.SetDefault("negative_cache.enabled", false)
+	v.SetDefault("negative_cache.ttl_seconds", utils.NEGATIVE_CACHE_DEFAULT_TTL_SECONDS)
+	v.SetDefault("negative_cache.max_items", utils.NEGATIVE_CACHE_DEFAULT_MAX_ITEMS)
+	v.SetDefault("negative_cache.bloom_filter.enabled", false)
+	v.SetDefault("negative_cache.bloom_filter.expected_keys", utils.BLOOM_FILTER_DEFAULT_EXPECTED_KEYS)
+	v.SetDefault("health_check.enabled", false)
+	v.SetDefault("health_check.interval_seconds", utils.HEALTH_CHECK_DEFAULT_INTERVAL_SECONDS)
+	v.SetDefault("health_check.timeout_ms", utils.HEALTH_CHECK_DEFAULT_TIMEOUT_MS)
 	v.SetDefault("request_limits.allow_setting_keys", false)
 	v.SetDefault("request_limits.max_size_bytes", utils.REQUEST_MAX_SIZE_BYTES)
 	v.SetDefault("request_limits.max_num_values", utils.REQUEST_MAX_NUM_VALUES)
@@ -103,6 +203,58 @@ func setConfigDefaults(v *viper.Viper) {
 	v.SetDefault("routes.allow_public_write", true)
 }
 
+// setNestedBackendDefaults unmarshals the backend sections nested in composite backends again
+// on top of the defaults the config.backend sections get, which viper only applies to the
+// top level ones
+func setNestedBackendDefaults(v *viper.Viper, cfg *Configuration) error {
+	rawShards, _ := v.Get("backend.sharded.shards").([]interface{})
+	for i, rawShard := range rawShards {
+		shard, ok := rawShard.(map[string]interface{})
+		if !ok || i >= len(cfg.Backend.Sharded.Shards) {
+			continue
+		}
+
+		backend, err := unmarshalNestedBackend(shard["backend"])
+		if err != nil {
+			return err
+		}
+		cfg.Backend.Sharded.Shards[i].Backend = *backend
+	}
+
+	for key, nested := range map[string]**Backend{
+		"backend.tiered.l2.backend":           &cfg.Backend.Tiered.L2.Backend,
+		"backend.migration.primary.backend":   &cfg.Backend.Migration.Primary.Backend,
+		"backend.migration.secondary.backend": &cfg.Backend.Migration.Secondary.Backend,
+	} {
+		if !v.IsSet(key) {
+			continue
+		}
+
+		backend, err := unmarshalNestedBackend(v.Get(key))
+		if err != nil {
+			return err
+		}
+		*nested = backend
+	}
+	return nil
+}
+
+// unmarshalNestedBackend unmarshals rawBackend, a backend section nested in a composite
+// backend, on top of the defaults the config.backend sections get
+func unmarshalNestedBackend(rawBackend interface{}) (*Backend, error) {
+	nestedViper := viper.New()
+	setConfigDefaults(nestedViper)
+	if err := nestedViper.MergeConfigMap(map[string]interface{}{"backend": rawBackend}); err != nil {
+		return nil, err
+	}
+
+	var nestedCfg Configuration
+	if err := nestedViper.Unmarshal(&nestedCfg); err != nil {
+		return nil, err
+	}
+	return &nestedCfg.Backend, nil
+}
+
 func setConfigFilePath(v *viper.Viper, filename string) {
 	v.SetConfigName(filename)              // name of config file (without extension)
 	v.AddConfigPath("/etc/prebid-cache/")  // path to look for the config file in
@@ -123,6 +275,12 @@ type Configuration struct {
 	Log            Log               `mapstructure:"log"`
 	RateLimiting   RateLimiting      `mapstructure:"rate_limiter"`
 	RequestLimits  RequestLimits     `mapstructure:"request_limits"`
+	Timeouts       Timeouts          `mapstructure:"timeouts"`
+	CircuitBreaker CircuitBreaker    `mapstructure:"circuit_breaker"`
+	Retry          Retry             `mapstructure:"retry"`
+	Coalescing     RequestCoalescing `mapstructure:"request_coalescing"`
+	NegativeCache  NegativeCache     `mapstructure:"negative_cache"`
+	HealthCheck    HealthCheck       `mapstructure:"health_check"`
 	StatusResponse string            `mapstructure:"status_response"`
 	Backend        Backend           `mapstructure:"backend"`
 	Compression    Compression       `mapstructure:"compression"`
@@ -145,6 +303,25 @@ func (cfg *Configuration) ValidateAndLog() {
 	if err := cfg.Backend.validateAndLog(); err != nil {
 		logger.Fatal("%s", err.Error())
 	}
+	if err := cfg.validateCassandraWriteMode(); err != nil {
+		logger.Fatal("%s", err.Error())
+	}
+	if err := cfg.Timeouts.validateAndLog(); err != nil {
+		logger.Fatal("%s", err.Error())
+	}
+	if err := cfg.CircuitBreaker.validateAndLog(); err != nil {
+		logger.Fatal("%s", err.Error())
+	}
+	if err := cfg.Retry.validateAndLog(); err != nil {
+		logger.Fatal("%s", err.Error())
+	}
+	cfg.Coalescing.validateAndLog()
+	if err := cfg.NegativeCache.validateAndLog(); err != nil {
+		logger.Fatal("%s", err.Error())
+	}
+	if err := cfg.HealthCheck.validateAndLog(); err != nil {
+		logger.Fatal("%s", err.Error())
+	}
 
 	cfg.Compression.validateAndLog()
 	cfg.Metrics.validateAndLog()
@@ -154,6 +331,18 @@ func (cfg *Configuration) ValidateAndLog() {
 	validateAndLogStats(cfg.Stats)
 }
 
+// validateCassandraWriteMode makes sure the Cassandra backend only skips lightweight transactions
+// when every key is a server-generated UUID. Plain inserts would otherwise let a custom key
+// silently overwrite a value stored by someone else
+func (cfg *Configuration) validateCassandraWriteMode() error {
+	for _, backend := range cfg.Backend.StorageBackends() {
+		if backend.Type == BackendCassandra && backend.Cassandra.WriteMode == CassandraWritePlain && cfg.RequestLimits.AllowSettingKeys {
+			return fmt.Errorf("invalid config.backend.cassandra.write_mode: %s. Writes must use lightweight transactions when config.request_limits.allow_setting_keys is true.", backend.Cassandra.WriteMode)
+		}
+	}
+	return nil
+}
+
 type Log struct {
 	Level LogLevel `mapstructure:"level"`
 }
@@ -213,6 +402,284 @@ func (cfg *RequestLimits) validateAndLog() {
 	}
 }
 
+// Timeouts bound the time requests can take. Backend timeouts apply to the backend calls the
+// /cache endpoints make, which are also canceled if the client goes away
+type Timeouts struct {
+	// Gets of GET /cache
+	BackendReadMs int `mapstructure:"backend_read_ms"`
+	// Puts of POST /cache and deletes of DELETE /cache
+	BackendWriteMs int `mapstructure:"backend_write_ms"`
+	// Connections and checks backends make when the app starts
+	BackendConnectMs int `mapstructure:"backend_connect_ms"`
+	// Reading a whole request and writing its response on the main server
+	ServerReadSeconds  int `mapstructure:"server_read_seconds"`
+	ServerWriteSeconds int `mapstructure:"server_write_seconds"`
+	// Time servers get to complete the requests in flight when the app stops
+	ShutdownSeconds int `mapstructure:"shutdown_seconds"`
+}
+
+func (cfg *Timeouts) validateAndLog() error {
+	if cfg.BackendReadMs <= 0 {
+		return fmt.Errorf("invalid config.timeouts.backend_read_ms: %d. Value must be positive.", cfg.BackendReadMs)
+	}
+	logger.Info("config.timeouts.backend_read_ms: %d", cfg.BackendReadMs)
+
+	if cfg.BackendWriteMs <= 0 {
+		return fmt.Errorf("invalid config.timeouts.backend_write_ms: %d. Value must be positive.", cfg.BackendWriteMs)
+	}
+	logger.Info("config.timeouts.backend_write_ms: %d", cfg.BackendWriteMs)
+
+	if cfg.BackendConnectMs <= 0 {
+		return fmt.Errorf("invalid config.timeouts.backend_connect_ms: %d. Value must be positive.", cfg.BackendConnectMs)
+	}
+	logger.Info("config.timeouts.backend_connect_ms: %d", cfg.BackendConnectMs)
+
+	if cfg.ServerReadSeconds <= 0 {
+		return fmt.Errorf("invalid config.timeouts.server_read_seconds: %d. Value must be positive.", cfg.ServerReadSeconds)
+	}
+	logger.Info("config.timeouts.server_read_seconds: %d", cfg.ServerReadSeconds)
+
+	// Responses can't be written after the server write timeout passed, so the backend calls
+	// have to be done by then
+	if cfg.ServerWriteSeconds*1000 <= max(cfg.BackendReadMs, cfg.BackendWriteMs) {
+		return fmt.Errorf("invalid config.timeouts.server_write_seconds: %d. Value must be longer than config.timeouts.backend_read_ms and config.timeouts.backend_write_ms.", cfg.ServerWriteSeconds)
+	}
+	logger.Info("config.timeouts.server_write_seconds: %d", cfg.ServerWriteSeconds)
+
+	if cfg.ShutdownSeconds <= 0 {
+		return fmt.Errorf("invalid config.timeouts.shutdown_seconds: %d. Value must be positive.", cfg.ShutdownSeconds)
+	}
+	logger.Info("config.timeouts.shutdown_seconds: %d", cfg.ShutdownSeconds)
+	return nil
+}
+
+// BackendReadTimeout returns the time gets of GET /cache can take
+func (cfg *Timeouts) BackendReadTimeout() time.Duration {
+	return time.Duration(cfg.BackendReadMs) * time.Millisecond
+}
+
+// BackendWriteTimeout returns the time puts of POST /cache and deletes of DELETE /cache can take
+func (cfg *Timeouts) BackendWriteTimeout() time.Duration {
+	return time.Duration(cfg.BackendWriteMs) * time.Millisecond
+}
+
+// CircuitBreaker stops sending requests to a backend that keeps failing or timing out so they
+// fail fast instead. Gets, puts and deletes have circuits of their own
+type CircuitBreaker struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Percentage of the requests of a window that must fail or time out for the circuit to open
+	FailureRatePercent int `mapstructure:"failure_rate_percent"`
+	// Number of requests a window must have before its failure rate is taken into account
+	MinRequests int `mapstructure:"min_requests"`
+	// Length of the windows failure rates are computed over
+	WindowSeconds int `mapstructure:"window_seconds"`
+	// How long an open circuit fails requests before letting probes through
+	OpenSeconds int `mapstructure:"open_seconds"`
+	// Number of probes a half-open circuit lets through. The circuit closes once all of them
+	// succeed and opens again as soon as one fails
+	HalfOpenRequests int `mapstructure:"half_open_requests"`
+}
+
+func (cfg *CircuitBreaker) validateAndLog() error {
+	logger.Info("config.circuit_breaker.enabled: %t", cfg.Enabled)
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.FailureRatePercent < 1 || cfg.FailureRatePercent > 100 {
+		return fmt.Errorf("invalid config.circuit_breaker.failure_rate_percent: %d. Value must be between 1 and 100.", cfg.FailureRatePercent)
+	}
+	logger.Info("config.circuit_breaker.failure_rate_percent: %d", cfg.FailureRatePercent)
+
+	if cfg.MinRequests <= 0 {
+		return fmt.Errorf("invalid config.circuit_breaker.min_requests: %d. Value must be positive.", cfg.MinRequests)
+	}
+	logger.Info("config.circuit_breaker.min_requests: %d", cfg.MinRequests)
+
+	if cfg.WindowSeconds <= 0 {
+		return fmt.Errorf("invalid config.circuit_breaker.window_seconds: %d. Value must be positive.", cfg.WindowSeconds)
+	}
+	logger.Info("config.circuit_breaker.window_seconds: %d", cfg.WindowSeconds)
+
+	if cfg.OpenSeconds <= 0 {
+		return fmt.Errorf("invalid config.circuit_breaker.open_seconds: %d. Value must be positive.", cfg.OpenSeconds)
+	}
+	logger.Info("config.circuit_breaker.open_seconds: %d", cfg.OpenSeconds)
+
+	if cfg.HalfOpenRequests <= 0 {
+		return fmt.Errorf("invalid config.circuit_breaker.half_open_requests: %d. Value must be positive.", cfg.HalfOpenRequests)
+	}
+	logger.Info("config.circuit_breaker.half_open_requests: %d", cfg.HalfOpenRequests)
+	return nil
+}
+
+// CircuitState names the states of the circuits of the circuit breaker
+type CircuitState string
+
+const (
+	// Requests go through and their failures are counted
+	CircuitClosed CircuitState = "closed"
+	// Requests fail without reaching the backend
+	CircuitOpen CircuitState = "open"
+	// A few probe requests go through to find out whether the backend recovered
+	CircuitHalfOpen CircuitState = "half_open"
+)
+
+// Retry retries backend requests that fail with transient errors, such as network errors or
+// timeouts of the backend client, and can hedge gets that take longer than usual
+type Retry struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Number of times a get is retried
+	MaxReadRetries int `mapstructure:"max_read_retries"`
+	// Number of times a put or a delete is retried. A write that timed out may have gone through,
+	// in which case its retry fails with a "record exists" or "key not found" error
+	MaxWriteRetries int `mapstructure:"max_write_retries"`
+	// Retries wait for a random time of up to min_backoff_ms for the first retry, doubling for
+	// every retry after it up to max_backoff_ms
+	MinBackoffMs int `mapstructure:"min_backoff_ms"`
+	MaxBackoffMs int `mapstructure:"max_backoff_ms"`
+	// Retries and hedged gets, taken together, can't exceed this percentage of the requests
+	BudgetPercent int `mapstructure:"budget_percent"`
+	// A get that takes longer than this percentile of the latencies of recent gets is sent again,
+	// and the first answer wins. A value of 0 disables hedging.
+	HedgePercentile int `mapstructure:"hedge_percentile"`
+}
+
+func (cfg *Retry) validateAndLog() error {
+	logger.Info("config.retry.enabled: %t", cfg.Enabled)
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.MaxReadRetries < 0 {
+		return fmt.Errorf("invalid config.retry.max_read_retries: %d. Value cannot be negative.", cfg.MaxReadRetries)
+	}
+	logger.Info("config.retry.max_read_retries: %d", cfg.MaxReadRetries)
+
+	if cfg.MaxWriteRetries < 0 {
+		return fmt.Errorf("invalid config.retry.max_write_retries: %d. Value cannot be negative.", cfg.MaxWriteRetries)
+	}
+	logger.Info("config.retry.max_write_retries: %d", cfg.MaxWriteRetries)
+
+	if cfg.MinBackoffMs < 0 {
+		return fmt.Errorf("invalid config.retry.min_backoff_ms: %d. Value cannot be negative.", cfg.MinBackoffMs)
+	}
+	logger.Info("config.retry.min_backoff_ms: %d", cfg.MinBackoffMs)
+
+	if cfg.MaxBackoffMs < cfg.MinBackoffMs {
+		return fmt.Errorf("invalid config.retry.max_backoff_ms: %d. Value cannot be less than config.retry.min_backoff_ms.", cfg.MaxBackoffMs)
+	}
+	logger.Info("config.retry.max_backoff_ms: %d", cfg.MaxBackoffMs)
+
+	if cfg.BudgetPercent < 1 || cfg.BudgetPercent > 100 {
+		return fmt.Errorf("invalid config.retry.budget_percent: %d. Value must be between 1 and 100.", cfg.BudgetPercent)
+	}
+	logger.Info("config.retry.budget_percent: %d", cfg.BudgetPercent)
+
+	if cfg.HedgePercentile < 0 || cfg.HedgePercentile > 99 {
+		return fmt.Errorf("invalid config.retry.hedge_percentile: %d. Value must be between 0 and 99.", cfg.HedgePercentile)
+	}
+	if cfg.HedgePercentile == 0 {
+		logger.Info("config.retry.hedge_percentile is 0. Gets won't be hedged.")
+	} else {
+		logger.Info("config.retry.hedge_percentile: %d", cfg.HedgePercentile)
+	}
+	return nil
+}
+
+// RequestCoalescing makes concurrent gets of the same key share a single backend get
+type RequestCoalescing struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+func (cfg *RequestCoalescing) validateAndLog() {
+	logger.Info("config.request_coalescing.enabled: %t", cfg.Enabled)
+}
+
+// NegativeCache answers gets of keys that were recently found missing, or that were never
+// written through this instance, without reaching the backend
+type NegativeCache struct {
+	Enabled bool `mapstructure:"enabled"`
+	// How long a key found missing is answered as missing without asking the backend again
+	TTLSeconds int `mapstructure:"ttl_seconds"`
+	// Maximum number of missing keys remembered. Least recently used ones get evicted once reached
+	MaxItems    int         `mapstructure:"max_items"`
+	BloomFilter BloomFilter `mapstructure:"bloom_filter"`
+}
+
+// BloomFilter keeps track of the keys written through this instance so that gets of any other
+// key are answered as missing. Only suitable when every key read from an instance was written
+// through that same instance.
+type BloomFilter struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Number of keys written over the longest TTL values are stored with. The filter is sized
+	// for 1% of false positives at that number of keys
+	ExpectedKeys int `mapstructure:"expected_keys"`
+}
+
+func (cfg *NegativeCache) validateAndLog() error {
+	logger.Info("config.negative_cache.enabled: %t", cfg.Enabled)
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.TTLSeconds <= 0 {
+		return fmt.Errorf("invalid config.negative_cache.ttl_seconds: %d. Value must be positive.", cfg.TTLSeconds)
+	}
+	logger.Info("config.negative_cache.ttl_seconds: %d", cfg.TTLSeconds)
+
+	if cfg.MaxItems <= 0 {
+		return fmt.Errorf("invalid config.negative_cache.max_items: %d. Value must be positive.", cfg.MaxItems)
+	}
+	logger.Info("config.negative_cache.max_items: %d", cfg.MaxItems)
+
+	logger.Info("config.negative_cache.bloom_filter.enabled: %t", cfg.BloomFilter.Enabled)
+	if !cfg.BloomFilter.Enabled {
+		return nil
+	}
+	if cfg.BloomFilter.ExpectedKeys <= 0 {
+		return fmt.Errorf("invalid config.negative_cache.bloom_filter.expected_keys: %d. Value must be positive.", cfg.BloomFilter.ExpectedKeys)
+	}
+	logger.Info("config.negative_cache.bloom_filter.expected_keys: %d", cfg.BloomFilter.ExpectedKeys)
+	return nil
+}
+
+// NegativeCacheSource names what told the negative cache a key was missing
+type NegativeCacheSource string
+
+const (
+	// The backend reported the key missing less than config.negative_cache.ttl_seconds ago
+	NegativeCacheEntry NegativeCacheSource = "entry"
+	// The key wasn't written through this instance
+	NegativeCacheBloomFilter NegativeCacheSource = "bloom_filter"
+)
+
+// HealthCheck checks whether the backend can serve requests in the background, so that the
+// /status endpoint reports the app as unavailable while it can't
+type HealthCheck struct {
+	Enabled         bool `mapstructure:"enabled"`
+	IntervalSeconds int  `mapstructure:"interval_seconds"`
+	TimeoutMs       int  `mapstructure:"timeout_ms"`
+}
+
+func (cfg *HealthCheck) validateAndLog() error {
+	logger.Info("config.health_check.enabled: %t", cfg.Enabled)
+	if !cfg.Enabled {
+		return nil
+	}
+
+	if cfg.IntervalSeconds <= 0 {
+		return fmt.Errorf("invalid config.health_check.interval_seconds: %d. Value must be positive.", cfg.IntervalSeconds)
+	}
+	logger.Info("config.health_check.interval_seconds: %d", cfg.IntervalSeconds)
+
+	if cfg.TimeoutMs <= 0 {
+		return fmt.Errorf("invalid config.health_check.timeout_ms: %d. Value must be positive.", cfg.TimeoutMs)
+	}
+	logger.Info("config.health_check.timeout_ms: %d", cfg.TimeoutMs)
+	return nil
+}
+
 type Compression struct {
 	Type CompressionType `mapstructure:"type"`
 }
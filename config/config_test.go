@@ -1,7 +1,9 @@
 package config
 
 import (
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,6 +25,150 @@ func TestDefaults(t *testing.T) {
 	assert.Equal(t, expectedConfig, cfg, "Expected Configuration instance does not match.")
 }
 
+func TestShardDefaults(t *testing.T) {
+	v := viper.New()
+	setConfigDefaults(v)
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+backend:
+  type: "sharded"
+  sharded:
+    shards:
+      - name: "redis-a"
+        weight: 2
+        backend:
+          type: "redis"
+          redis:
+            host: "redis-a.internal"
+      - name: "memcache-b"
+        backend:
+          type: "memcache"
+          memcache:
+            hosts: ["memcache-b.internal:11211"]
+`))
+	assert.NoError(t, err, "Failed to read config")
+
+	cfg := Configuration{}
+	assert.NoError(t, v.Unmarshal(&cfg), "Failed to unmarshal config")
+	assert.NoError(t, setNestedBackendDefaults(v, &cfg), "Failed to unmarshal shards")
+
+	defaults := getExpectedDefaultConfig().Backend
+	shards := cfg.Backend.Sharded.Shards
+	if assert.Len(t, shards, 2) {
+		assert.Equal(t, "redis-a", shards[0].Name)
+		assert.Equal(t, 2, shards[0].Weight)
+		assert.Equal(t, BackendRedis, shards[0].Backend.Type)
+		assert.Equal(t, "redis-a.internal", shards[0].Backend.Redis.Host)
+		assert.Equal(t, defaults.Redis.Mode, shards[0].Backend.Redis.Mode, "Unset redis field gets its default")
+
+		assert.Equal(t, "memcache-b", shards[1].Name)
+		assert.Equal(t, BackendMemcache, shards[1].Backend.Type)
+		assert.Equal(t, []string{"memcache-b.internal:11211"}, shards[1].Backend.Memcache.Hosts)
+		assert.Equal(t, defaults.Memcache.TimeoutMillis, shards[1].Backend.Memcache.TimeoutMillis, "Unset memcache field gets its default")
+	}
+}
+
+func TestTieredL2Defaults(t *testing.T) {
+	v := viper.New()
+	setConfigDefaults(v)
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+backend:
+  type: "tiered"
+  tiered:
+    l2:
+      backend:
+        type: "memcache"
+        memcache:
+          hosts: ["memcache.internal:11211"]
+`))
+	assert.NoError(t, err, "Failed to read config")
+
+	cfg := Configuration{}
+	assert.NoError(t, v.Unmarshal(&cfg), "Failed to unmarshal config")
+	assert.NoError(t, setNestedBackendDefaults(v, &cfg), "Failed to unmarshal l2")
+
+	defaults := getExpectedDefaultConfig().Backend
+	l2 := cfg.Backend.Tiered.L2.Backend
+	if assert.NotNil(t, l2) {
+		assert.Equal(t, BackendMemcache, l2.Type)
+		assert.Equal(t, []string{"memcache.internal:11211"}, l2.Memcache.Hosts)
+		assert.Equal(t, defaults.Memcache.TimeoutMillis, l2.Memcache.TimeoutMillis, "Unset memcache field gets its default")
+	}
+	assert.Equal(t, defaults.Tiered.L1, cfg.Backend.Tiered.L1, "L1 gets its defaults")
+}
+
+func TestMigrationSideDefaults(t *testing.T) {
+	v := viper.New()
+	setConfigDefaults(v)
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+backend:
+  type: "migration"
+  migration:
+    primary:
+      backend:
+        type: "redis"
+        redis:
+          host: "redis.internal"
+    secondary:
+      backend:
+        type: "redis"
+        redis:
+          host: "redis-cluster.internal"
+          mode: "cluster"
+`))
+	assert.NoError(t, err, "Failed to read config")
+
+	cfg := Configuration{}
+	assert.NoError(t, v.Unmarshal(&cfg), "Failed to unmarshal config")
+	assert.NoError(t, setNestedBackendDefaults(v, &cfg), "Failed to unmarshal migration sides")
+
+	defaults := getExpectedDefaultConfig().Backend
+	primary := cfg.Backend.Migration.Primary.Backend
+	if assert.NotNil(t, primary) {
+		assert.Equal(t, BackendRedis, primary.Type)
+		assert.Equal(t, "redis.internal", primary.Redis.Host)
+		assert.Equal(t, defaults.Redis.Mode, primary.Redis.Mode, "Unset redis field gets its default")
+	}
+	secondary := cfg.Backend.Migration.Secondary.Backend
+	if assert.NotNil(t, secondary) {
+		assert.Equal(t, BackendRedis, secondary.Type)
+		assert.Equal(t, "redis-cluster.internal", secondary.Redis.Host)
+		assert.Equal(t, RedisCluster, secondary.Redis.Mode)
+		assert.Equal(t, defaults.Redis.ReadFromReplicas, secondary.Redis.ReadFromReplicas, "Unset redis field gets its default")
+	}
+	assert.Equal(t, MigrationWriteBoth, cfg.Backend.Migration.WriteMode)
+}
+
+func TestCassandraDatacenterNamesKeepTheirCase(t *testing.T) {
+	v := viper.New()
+	setConfigDefaults(v)
+	v.SetConfigType("yaml")
+	err := v.ReadConfig(strings.NewReader(`
+backend:
+  type: "cassandra"
+  cassandra:
+    schema:
+      replication_class: "NetworkTopologyStrategy"
+      datacenter_replication_factors:
+        - name: "US-East"
+          replication_factor: 3
+        - name: "eu-west"
+          replication_factor: 2
+`))
+	assert.NoError(t, err, "Failed to read config")
+
+	cfg := Configuration{}
+	assert.NoError(t, v.Unmarshal(&cfg), "Failed to unmarshal config")
+
+	expected := []CassandraDatacenterReplication{
+		{Name: "US-East", ReplicationFactor: 3},
+		{Name: "eu-west", ReplicationFactor: 2},
+	}
+	assert.Equal(t, expected, cfg.Backend.Cassandra.Schema.DatacenterReplicationFactors)
+}
+
 func TestEnvConfig(t *testing.T) {
 	defer setEnvVar(t, "PBC_METRICS_INFLUX_HOST", "env-var-defined-metrics-host")()
 
@@ -77,17 +223,77 @@ func getExpectedDefaultConfig() Configuration {
 		Backend: Backend{
 			Type: BackendMemory,
 			Memcache: Memcache{
-				Hosts: []string{},
+				Hosts:         []string{},
+				TimeoutMillis: utils.MEMCACHE_DEFAULT_TIMEOUT_MS,
+				MaxIdleConns:  utils.MEMCACHE_DEFAULT_MAX_IDLE_CONNS,
+			},
+			Memory: Memory{
+				SweepIntervalSeconds: utils.MEMORY_SWEEP_INTERVAL_SECONDS,
+				Shards:               utils.MEMORY_DEFAULT_SHARDS,
+			},
+			File: File{
+				SegmentSizeBytes:          utils.FILE_DEFAULT_SEGMENT_SIZE_BYTES,
+				CompactionIntervalSeconds: utils.FILE_DEFAULT_COMPACTION_INTERVAL_SECONDS,
+			},
+			SQL: SQL{
+				Table:                utils.SQL_DEFAULT_TABLE,
+				MaxOpenConns:         utils.SQL_DEFAULT_MAX_OPEN_CONNS,
+				MaxIdleConns:         utils.SQL_DEFAULT_MAX_IDLE_CONNS,
+				SweepIntervalSeconds: utils.SQL_DEFAULT_SWEEP_INTERVAL_SECONDS,
 			},
 			Aerospike: Aerospike{
 				Hosts:          []string{},
 				MaxReadRetries: 2,
+				SetName:        "uuid",
+				BinName:        "value",
+				ReadReplica:    AerospikeReplicaSequence,
+				ReadModeAP:     AerospikeReadModeAPOne,
+				AuthMode:       AerospikeAuthInternal,
+				TLS: AerospikeTLS{
+					MinVersion: "1.2",
+				},
 			},
 			Cassandra: Cassandra{
 				DefaultTTL: utils.CASSANDRA_DEFAULT_TTL_SECONDS,
+				TLS: CassandraTLS{
+					MinVersion: "1.2",
+				},
+				ReadConsistency:      "one",
+				WriteConsistency:     "local_one",
+				ConnectTimeoutMillis: utils.CASSANDRA_DEFAULT_TIMEOUT_MS,
+				TimeoutMillis:        utils.CASSANDRA_DEFAULT_TIMEOUT_MS,
+				Retry: CassandraRetry{
+					MinBackoffMillis: 100,
+					MaxBackoffMillis: 1000,
+				},
+				WriteMode: CassandraWriteLWT,
+				Schema: CassandraSchema{
+					ReplicationClass:  CassandraSimpleStrategy,
+					ReplicationFactor: 1,
+					CompactionClass:   "SizeTieredCompactionStrategy",
+				},
 			},
 			Redis: Redis{
 				ExpirationMinutes: utils.REDIS_DEFAULT_EXPIRATION_MINUTES,
+				TLS: RedisTLS{
+					MinVersion: "1.2",
+				},
+				Mode:             RedisStandalone,
+				Addrs:            []string{},
+				ReadFromReplicas: RedisReplicaReadsNever,
+			},
+			Tiered: Tiered{
+				L1: TieredL1{
+					MaxItems:   utils.TIERED_L1_DEFAULT_MAX_ITEMS,
+					TTLSeconds: utils.TIERED_L1_DEFAULT_TTL_SECONDS,
+				},
+			},
+			Migration: Migration{
+				WriteMode: MigrationWriteBoth,
+			},
+			Sharded: Sharded{
+				Shards:       []Shard{},
+				VirtualNodes: utils.SHARDED_DEFAULT_VIRTUAL_NODES,
 			},
 		},
 		Compression: Compression{
@@ -102,6 +308,38 @@ func getExpectedDefaultConfig() Configuration {
 			MaxNumValues:  10,
 			MaxTTLSeconds: 3600,
 		},
+		Timeouts: Timeouts{
+			BackendReadMs:      500,
+			BackendWriteMs:     500,
+			BackendConnectMs:   500,
+			ServerReadSeconds:  15,
+			ServerWriteSeconds: 15,
+			ShutdownSeconds:    10,
+		},
+		CircuitBreaker: CircuitBreaker{
+			FailureRatePercent: 50,
+			MinRequests:        20,
+			WindowSeconds:      10,
+			OpenSeconds:        5,
+			HalfOpenRequests:   5,
+		},
+		Retry: Retry{
+			MaxReadRetries: 2,
+			MinBackoffMs:   10,
+			MaxBackoffMs:   100,
+			BudgetPercent:  20,
+		},
+		NegativeCache: NegativeCache{
+			TTLSeconds: 5,
+			MaxItems:   100000,
+			BloomFilter: BloomFilter{
+				ExpectedKeys: 1000000,
+			},
+		},
+		HealthCheck: HealthCheck{
+			IntervalSeconds: 5,
+			TimeoutMs:       500,
+		},
 		Routes: Routes{
 			AllowPublicWrite: true,
 		},
@@ -127,6 +365,38 @@ func getExpectedFullConfigForTestFile() Configuration {
 			MaxTTLSeconds:    5000,
 			AllowSettingKeys: true,
 		},
+		Timeouts: Timeouts{
+			BackendReadMs:      500,
+			BackendWriteMs:     500,
+			BackendConnectMs:   500,
+			ServerReadSeconds:  15,
+			ServerWriteSeconds: 15,
+			ShutdownSeconds:    10,
+		},
+		CircuitBreaker: CircuitBreaker{
+			FailureRatePercent: 50,
+			MinRequests:        20,
+			WindowSeconds:      10,
+			OpenSeconds:        5,
+			HalfOpenRequests:   5,
+		},
+		Retry: Retry{
+			MaxReadRetries: 2,
+			MinBackoffMs:   10,
+			MaxBackoffMs:   100,
+			BudgetPercent:  20,
+		},
+		NegativeCache: NegativeCache{
+			TTLSeconds: 5,
+			MaxItems:   100000,
+			BloomFilter: BloomFilter{
+				ExpectedKeys: 1000000,
+			},
+		},
+		HealthCheck: HealthCheck{
+			IntervalSeconds: 5,
+			TimeoutMs:       500,
+		},
 		Backend: Backend{
 			Type: BackendMemory,
 			Aerospike: Aerospike{
@@ -139,14 +409,41 @@ func getExpectedFullConfigForTestFile() Configuration {
 				Password:            "bar",
 				MaxReadRetries:      2,
 				ConnIdleTimeoutSecs: 2,
+				SetName:             "uuid",
+				BinName:             "value",
+				ReadReplica:         AerospikeReplicaSequence,
+				ReadModeAP:          AerospikeReadModeAPOne,
+				AuthMode:            AerospikeAuthInternal,
+				TLS: AerospikeTLS{
+					MinVersion: "1.2",
+				},
 			},
 			Cassandra: Cassandra{
 				Hosts:      "127.0.0.1",
 				Keyspace:   "prebid",
 				DefaultTTL: 60,
+				TLS: CassandraTLS{
+					MinVersion: "1.2",
+				},
+				ReadConsistency:      "one",
+				WriteConsistency:     "local_one",
+				ConnectTimeoutMillis: utils.CASSANDRA_DEFAULT_TIMEOUT_MS,
+				TimeoutMillis:        utils.CASSANDRA_DEFAULT_TIMEOUT_MS,
+				Retry: CassandraRetry{
+					MinBackoffMillis: 100,
+					MaxBackoffMillis: 1000,
+				},
+				WriteMode: CassandraWriteLWT,
+				Schema: CassandraSchema{
+					ReplicationClass:  CassandraSimpleStrategy,
+					ReplicationFactor: 1,
+					CompactionClass:   "SizeTieredCompactionStrategy",
+				},
 			},
 			Memcache: Memcache{
-				Hosts: []string{"10.0.0.1:11211", "127.0.0.1"},
+				Hosts:         []string{"10.0.0.1:11211", "127.0.0.1"},
+				TimeoutMillis: 50,
+				MaxIdleConns:  10,
 			},
 			Redis: Redis{
 				Host:              "127.0.0.1",
@@ -157,8 +454,25 @@ func getExpectedFullConfigForTestFile() Configuration {
 				TLS: RedisTLS{
 					Enabled:            false,
 					InsecureSkipVerify: false,
+					MinVersion:         "1.2",
+				},
+				Mode:             RedisStandalone,
+				Addrs:            []string{},
+				ReadFromReplicas: RedisReplicaReadsNever,
+			},
+			Tiered: Tiered{
+				L1: TieredL1{
+					MaxItems:   utils.TIERED_L1_DEFAULT_MAX_ITEMS,
+					TTLSeconds: utils.TIERED_L1_DEFAULT_TTL_SECONDS,
 				},
 			},
+			Migration: Migration{
+				WriteMode: MigrationWriteBoth,
+			},
+			Sharded: Sharded{
+				Shards:       []Shard{},
+				VirtualNodes: utils.SHARDED_DEFAULT_VIRTUAL_NODES,
+			},
 		},
 		Compression: Compression{
 			Type: CompressionType("snappy"),
@@ -185,3 +499,326 @@ func getExpectedFullConfigForTestFile() Configuration {
 		},
 	}
 }
+
+func TestValidateCassandraWriteMode(t *testing.T) {
+	testCases := []struct {
+		desc             string
+		backendType      BackendType
+		writeMode        CassandraWriteMode
+		allowSettingKeys bool
+		expectedError    error
+	}{
+		{
+			desc:             "Lightweight transactions with custom keys",
+			backendType:      BackendCassandra,
+			writeMode:        CassandraWriteLWT,
+			allowSettingKeys: true,
+		},
+		{
+			desc:        "Plain inserts without custom keys",
+			backendType: BackendCassandra,
+			writeMode:   CassandraWritePlain,
+		},
+		{
+			desc:             "Plain inserts with custom keys",
+			backendType:      BackendCassandra,
+			writeMode:        CassandraWritePlain,
+			allowSettingKeys: true,
+			expectedError:    fmt.Errorf("invalid config.backend.cassandra.write_mode: plain. Writes must use lightweight transactions when config.request_limits.allow_setting_keys is true."),
+		},
+		{
+			desc:             "Cassandra write_mode is ignored by other backends",
+			backendType:      BackendRedis,
+			writeMode:        CassandraWritePlain,
+			allowSettingKeys: true,
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := Configuration{
+			Backend: Backend{
+				Type:      test.backendType,
+				Cassandra: Cassandra{WriteMode: test.writeMode},
+			},
+			RequestLimits: RequestLimits{AllowSettingKeys: test.allowSettingKeys},
+		}
+		assert.Equal(t, test.expectedError, cfg.validateCassandraWriteMode(), test.desc)
+	}
+}
+
+func TestCircuitBreakerValidateAndLog(t *testing.T) {
+	valid := CircuitBreaker{
+		Enabled:            true,
+		FailureRatePercent: 50,
+		MinRequests:        20,
+		WindowSeconds:      10,
+		OpenSeconds:        5,
+		HalfOpenRequests:   5,
+	}
+
+	testCases := []struct {
+		desc          string
+		inCfg         func(cfg *CircuitBreaker)
+		expectedError error
+	}{
+		{
+			desc:  "Valid thresholds",
+			inCfg: func(cfg *CircuitBreaker) {},
+		},
+		{
+			desc:  "Thresholds of a disabled circuit breaker aren't validated",
+			inCfg: func(cfg *CircuitBreaker) { *cfg = CircuitBreaker{} },
+		},
+		{
+			desc:          "Failure rate of 0",
+			inCfg:         func(cfg *CircuitBreaker) { cfg.FailureRatePercent = 0 },
+			expectedError: fmt.Errorf("invalid config.circuit_breaker.failure_rate_percent: 0. Value must be between 1 and 100."),
+		},
+		{
+			desc:          "Failure rate above 100",
+			inCfg:         func(cfg *CircuitBreaker) { cfg.FailureRatePercent = 101 },
+			expectedError: fmt.Errorf("invalid config.circuit_breaker.failure_rate_percent: 101. Value must be between 1 and 100."),
+		},
+		{
+			desc:          "Non-positive min_requests",
+			inCfg:         func(cfg *CircuitBreaker) { cfg.MinRequests = 0 },
+			expectedError: fmt.Errorf("invalid config.circuit_breaker.min_requests: 0. Value must be positive."),
+		},
+		{
+			desc:          "Non-positive window_seconds",
+			inCfg:         func(cfg *CircuitBreaker) { cfg.WindowSeconds = -1 },
+			expectedError: fmt.Errorf("invalid config.circuit_breaker.window_seconds: -1. Value must be positive."),
+		},
+		{
+			desc:          "Non-positive open_seconds",
+			inCfg:         func(cfg *CircuitBreaker) { cfg.OpenSeconds = 0 },
+			expectedError: fmt.Errorf("invalid config.circuit_breaker.open_seconds: 0. Value must be positive."),
+		},
+		{
+			desc:          "Non-positive half_open_requests",
+			inCfg:         func(cfg *CircuitBreaker) { cfg.HalfOpenRequests = 0 },
+			expectedError: fmt.Errorf("invalid config.circuit_breaker.half_open_requests: 0. Value must be positive."),
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := valid
+		test.inCfg(&cfg)
+		assert.Equal(t, test.expectedError, cfg.validateAndLog(), test.desc)
+	}
+}
+
+func TestTimeoutsValidateAndLog(t *testing.T) {
+	valid := Timeouts{
+		BackendReadMs:      500,
+		BackendWriteMs:     500,
+		BackendConnectMs:   500,
+		ServerReadSeconds:  15,
+		ServerWriteSeconds: 15,
+		ShutdownSeconds:    10,
+	}
+
+	testCases := []struct {
+		desc          string
+		inCfg         func(cfg *Timeouts)
+		expectedError error
+	}{
+		{
+			desc:  "Valid settings",
+			inCfg: func(cfg *Timeouts) {},
+		},
+		{
+			desc:          "Read timeout of 0",
+			inCfg:         func(cfg *Timeouts) { cfg.BackendReadMs = 0 },
+			expectedError: fmt.Errorf("invalid config.timeouts.backend_read_ms: 0. Value must be positive."),
+		},
+		{
+			desc:          "Negative write timeout",
+			inCfg:         func(cfg *Timeouts) { cfg.BackendWriteMs = -1 },
+			expectedError: fmt.Errorf("invalid config.timeouts.backend_write_ms: -1. Value must be positive."),
+		},
+		{
+			desc:          "Connect timeout of 0",
+			inCfg:         func(cfg *Timeouts) { cfg.BackendConnectMs = 0 },
+			expectedError: fmt.Errorf("invalid config.timeouts.backend_connect_ms: 0. Value must be positive."),
+		},
+		{
+			desc:          "Server read timeout of 0",
+			inCfg:         func(cfg *Timeouts) { cfg.ServerReadSeconds = 0 },
+			expectedError: fmt.Errorf("invalid config.timeouts.server_read_seconds: 0. Value must be positive."),
+		},
+		{
+			desc:          "Server write timeout shorter than a backend timeout",
+			inCfg:         func(cfg *Timeouts) { cfg.BackendWriteMs = 2000; cfg.ServerWriteSeconds = 2 },
+			expectedError: fmt.Errorf("invalid config.timeouts.server_write_seconds: 2. Value must be longer than config.timeouts.backend_read_ms and config.timeouts.backend_write_ms."),
+		},
+		{
+			desc:          "Shutdown timeout of 0",
+			inCfg:         func(cfg *Timeouts) { cfg.ShutdownSeconds = 0 },
+			expectedError: fmt.Errorf("invalid config.timeouts.shutdown_seconds: 0. Value must be positive."),
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := valid
+		test.inCfg(&cfg)
+		assert.Equal(t, test.expectedError, cfg.validateAndLog(), test.desc)
+	}
+}
+
+func TestRetryValidateAndLog(t *testing.T) {
+	valid := Retry{
+		Enabled:         true,
+		MaxReadRetries:  2,
+		MinBackoffMs:    10,
+		MaxBackoffMs:    100,
+		BudgetPercent:   20,
+		HedgePercentile: 95,
+	}
+
+	testCases := []struct {
+		desc          string
+		inCfg         func(cfg *Retry)
+		expectedError error
+	}{
+		{
+			desc:  "Valid settings",
+			inCfg: func(cfg *Retry) {},
+		},
+		{
+			desc:  "Hedging disabled",
+			inCfg: func(cfg *Retry) { cfg.HedgePercentile = 0 },
+		},
+		{
+			desc:  "Settings of a disabled decorator aren't validated",
+			inCfg: func(cfg *Retry) { *cfg = Retry{MaxReadRetries: -1} },
+		},
+		{
+			desc:          "Negative max_read_retries",
+			inCfg:         func(cfg *Retry) { cfg.MaxReadRetries = -1 },
+			expectedError: fmt.Errorf("invalid config.retry.max_read_retries: -1. Value cannot be negative."),
+		},
+		{
+			desc:          "Negative max_write_retries",
+			inCfg:         func(cfg *Retry) { cfg.MaxWriteRetries = -1 },
+			expectedError: fmt.Errorf("invalid config.retry.max_write_retries: -1. Value cannot be negative."),
+		},
+		{
+			desc:          "Negative min_backoff_ms",
+			inCfg:         func(cfg *Retry) { cfg.MinBackoffMs = -1 },
+			expectedError: fmt.Errorf("invalid config.retry.min_backoff_ms: -1. Value cannot be negative."),
+		},
+		{
+			desc:          "max_backoff_ms below min_backoff_ms",
+			inCfg:         func(cfg *Retry) { cfg.MaxBackoffMs = 5 },
+			expectedError: fmt.Errorf("invalid config.retry.max_backoff_ms: 5. Value cannot be less than config.retry.min_backoff_ms."),
+		},
+		{
+			desc:          "Budget of 0",
+			inCfg:         func(cfg *Retry) { cfg.BudgetPercent = 0 },
+			expectedError: fmt.Errorf("invalid config.retry.budget_percent: 0. Value must be between 1 and 100."),
+		},
+		{
+			desc:          "Hedge percentile of 100",
+			inCfg:         func(cfg *Retry) { cfg.HedgePercentile = 100 },
+			expectedError: fmt.Errorf("invalid config.retry.hedge_percentile: 100. Value must be between 0 and 99."),
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := valid
+		test.inCfg(&cfg)
+		assert.Equal(t, test.expectedError, cfg.validateAndLog(), test.desc)
+	}
+}
+
+func TestNegativeCacheValidateAndLog(t *testing.T) {
+	valid := NegativeCache{
+		Enabled:    true,
+		TTLSeconds: 5,
+		MaxItems:   100000,
+		BloomFilter: BloomFilter{
+			Enabled:      true,
+			ExpectedKeys: 1000000,
+		},
+	}
+
+	testCases := []struct {
+		desc          string
+		inCfg         func(cfg *NegativeCache)
+		expectedError error
+	}{
+		{
+			desc:  "Valid settings",
+			inCfg: func(cfg *NegativeCache) {},
+		},
+		{
+			desc:  "Settings of a disabled negative cache aren't validated",
+			inCfg: func(cfg *NegativeCache) { *cfg = NegativeCache{} },
+		},
+		{
+			desc:  "Settings of a disabled bloom filter aren't validated",
+			inCfg: func(cfg *NegativeCache) { cfg.BloomFilter = BloomFilter{} },
+		},
+		{
+			desc:          "TTL of 0",
+			inCfg:         func(cfg *NegativeCache) { cfg.TTLSeconds = 0 },
+			expectedError: fmt.Errorf("invalid config.negative_cache.ttl_seconds: 0. Value must be positive."),
+		},
+		{
+			desc:          "Negative max_items",
+			inCfg:         func(cfg *NegativeCache) { cfg.MaxItems = -1 },
+			expectedError: fmt.Errorf("invalid config.negative_cache.max_items: -1. Value must be positive."),
+		},
+		{
+			desc:          "Bloom filter without expected keys",
+			inCfg:         func(cfg *NegativeCache) { cfg.BloomFilter.ExpectedKeys = 0 },
+			expectedError: fmt.Errorf("invalid config.negative_cache.bloom_filter.expected_keys: 0. Value must be positive."),
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := valid
+		test.inCfg(&cfg)
+		assert.Equal(t, test.expectedError, cfg.validateAndLog(), test.desc)
+	}
+}
+
+func TestHealthCheckValidateAndLog(t *testing.T) {
+	valid := HealthCheck{
+		Enabled:         true,
+		IntervalSeconds: 5,
+		TimeoutMs:       500,
+	}
+
+	testCases := []struct {
+		desc          string
+		inCfg         func(cfg *HealthCheck)
+		expectedError error
+	}{
+		{
+			desc:  "Valid settings",
+			inCfg: func(cfg *HealthCheck) {},
+		},
+		{
+			desc:  "Settings of a disabled health check aren't validated",
+			inCfg: func(cfg *HealthCheck) { *cfg = HealthCheck{} },
+		},
+		{
+			desc:          "Interval of 0",
+			inCfg:         func(cfg *HealthCheck) { cfg.IntervalSeconds = 0 },
+			expectedError: fmt.Errorf("invalid config.health_check.interval_seconds: 0. Value must be positive."),
+		},
+		{
+			desc:          "Negative timeout",
+			inCfg:         func(cfg *HealthCheck) { cfg.TimeoutMs = -1 },
+			expectedError: fmt.Errorf("invalid config.health_check.timeout_ms: -1. Value must be positive."),
+		},
+	}
+
+	for _, test := range testCases {
+		cfg := valid
+		test.inCfg(&cfg)
+		assert.Equal(t, test.expectedError, cfg.validateAndLog(), test.desc)
+	}
+}
@@ -0,0 +1,109 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"git.pubmatic.com/PubMatic/go-common/logger"
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/utils"
+)
+
+// DeleteHandler serves "DELETE /cache" requests.
+type DeleteHandler struct {
+	backend         backends.Backend
+	metrics         *metrics.Metrics
+	allowCustomKeys bool
+	timeout         time.Duration
+}
+
+// NewDeleteHandler returns the handle function for the "/cache" endpoint when it receives a DELETE request.
+// Backend deletes are given up after timeout or as soon as the client goes away
+func NewDeleteHandler(storage backends.Backend, metrics *metrics.Metrics, allowCustomKeys bool, timeout time.Duration) func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	deleteHandler := &DeleteHandler{
+		// Assign storage client to delete endpoint
+		backend: storage,
+		// pass metrics engine
+		metrics: metrics,
+		// Pass configuration values
+		allowCustomKeys: allowCustomKeys,
+		timeout:         timeout,
+	}
+
+	// Return handle function
+	return deleteHandler.handle
+}
+
+func (e *DeleteHandler) handle(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	e.metrics.RecordDeleteTotal()
+	start := time.Now()
+	logger.Info("DELETE /cache called")
+
+	uuid := r.URL.Query().Get("uuid")
+	if uuid == "" {
+		e.handleException(w, uuid, utils.NewPBCError(utils.MISSING_KEY))
+		return
+	}
+	// Same quick length check GET /cache does in order to avoid a backend round trip
+	if len(uuid) != 36 && !e.allowCustomKeys {
+		e.handleException(w, uuid, utils.NewPBCError(utils.KEY_LENGTH))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), e.timeout)
+	defer cancel()
+
+	if err := e.backend.Delete(ctx, uuid); err != nil {
+		e.handleException(w, uuid, err)
+		return
+	}
+
+	// successfully removed value under uuid from the backend storage
+	w.WriteHeader(http.StatusNoContent)
+	logger.Info("Total time for delete: %v", time.Now().Sub(start))
+	e.metrics.RecordDeleteDuration(time.Since(start))
+}
+
+// handleException logs the error message, updates the error metrics based on error type and replies
+// back with the error message and an HTTP error code
+func (e *DeleteHandler) handleException(w http.ResponseWriter, uuid string, err error) {
+	// Prefix error message with "DELETE /cache " or "DELETE /cache uuid=..."
+	errMsgBuilder := strings.Builder{}
+	errMsgBuilder.WriteString("DELETE /cache")
+	if len(uuid) > 0 {
+		errMsgBuilder.WriteString(fmt.Sprintf(" uuid=%s", uuid))
+	}
+	errMsgBuilder.WriteString(fmt.Sprintf(": %s", err.Error()))
+	errMsg := errMsgBuilder.String()
+
+	// Determine the response status code based on error type
+	errCode := http.StatusInternalServerError
+	isKeyNotFound := false
+	if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
+		errCode = pbcErr.StatusCode
+		isKeyNotFound = pbcErr.Type == utils.KEY_NOT_FOUND
+	}
+
+	// Log error metrics based on error type
+	switch {
+	case errCode >= http.StatusInternalServerError: // 500
+		e.metrics.RecordDeleteError()
+	case errCode >= http.StatusBadRequest: // 400
+		e.metrics.RecordDeleteBadRequest()
+	}
+
+	// Determine log level
+	if isKeyNotFound {
+		logger.Debug(errMsg)
+	} else {
+		logger.Error(errMsg)
+	}
+
+	// Write error response
+	http.Error(w, errMsg, errCode)
+}
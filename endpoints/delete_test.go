@@ -0,0 +1,151 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
+	"github.com/prebid/prebid-cache/metrics"
+	"github.com/prebid/prebid-cache/metrics/metricstest"
+	"github.com/prebid/prebid-cache/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteHandler(t *testing.T) {
+	type testInput struct {
+		uuid      string
+		allowKeys bool
+		backend   backends.Backend
+	}
+	type testOutput struct {
+		responseCode    int
+		responseBody    string
+		expectedMetrics []string
+	}
+
+	memoryBackendWithValues := func() backends.Backend {
+		b := backends.NewMemoryBackend(config.Memory{})
+		b.Put(context.Background(), "non-36-char-key", "xml<tag>data</tag>", 0)
+		b.Put(context.Background(), "36-char-key-maps-to-actual-xml-value", "xml<tag>data</tag>", 0)
+		return b
+	}
+
+	testCases := []struct {
+		desc string
+		in   testInput
+		out  testOutput
+	}{
+		{
+			"Missing uuid query param, respond with a 400 status code",
+			testInput{uuid: "", backend: memoryBackendWithValues()},
+			testOutput{
+				responseCode:    http.StatusBadRequest,
+				responseBody:    "DELETE /cache: Missing required parameter uuid\n",
+				expectedMetrics: []string{"RecordDeleteTotal", "RecordDeleteBadRequest"},
+			},
+		},
+		{
+			"Custom keys are not allowed and uuid is not 36 characters long, respond with a 404 status code",
+			testInput{uuid: "non-36-char-key", backend: memoryBackendWithValues()},
+			testOutput{
+				responseCode:    http.StatusNotFound,
+				responseBody:    "DELETE /cache uuid=non-36-char-key: invalid uuid length\n",
+				expectedMetrics: []string{"RecordDeleteTotal", "RecordDeleteBadRequest"},
+			},
+		},
+		{
+			"Custom keys are allowed, uuid maps to a stored value. Respond with a 204 status code",
+			testInput{uuid: "non-36-char-key", allowKeys: true, backend: memoryBackendWithValues()},
+			testOutput{
+				responseCode:    http.StatusNoContent,
+				expectedMetrics: []string{"RecordDeleteTotal", "RecordDeleteDuration"},
+			},
+		},
+		{
+			"36 char uuid maps to a stored value. Respond with a 204 status code",
+			testInput{uuid: "36-char-key-maps-to-actual-xml-value", backend: memoryBackendWithValues()},
+			testOutput{
+				responseCode:    http.StatusNoContent,
+				expectedMetrics: []string{"RecordDeleteTotal", "RecordDeleteDuration"},
+			},
+		},
+		{
+			"36 char uuid doesn't map to any stored value. Respond with a 404 status code",
+			testInput{uuid: "fdd9405b-ef2b-46da-a55a-2f526d338e16", backend: memoryBackendWithValues()},
+			testOutput{
+				responseCode:    http.StatusNotFound,
+				responseBody:    "DELETE /cache uuid=fdd9405b-ef2b-46da-a55a-2f526d338e16: Key not found\n",
+				expectedMetrics: []string{"RecordDeleteTotal", "RecordDeleteBadRequest"},
+			},
+		},
+		{
+			"Backend storage fails. Respond with a 500 status code",
+			testInput{uuid: "fdd9405b-ef2b-46da-a55a-2f526d338e16", backend: &errorReturningBackend{}},
+			testOutput{
+				responseCode:    http.StatusInternalServerError,
+				responseBody:    "DELETE /cache uuid=fdd9405b-ef2b-46da-a55a-2f526d338e16: This is a mock backend that returns this error on Delete() operation\n",
+				expectedMetrics: []string{"RecordDeleteTotal", "RecordDeleteError"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		// Set up test object
+		router := httprouter.New()
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		}
+		router.DELETE("/cache", NewDeleteHandler(test.in.backend, m, test.in.allowKeys, testBackendTimeout))
+
+		// Run test
+		deleteResults := doMockDelete(t, router, test.in.uuid)
+
+		// Assert server response and status code
+		assert.Equal(t, test.out.responseCode, deleteResults.Code, test.desc)
+		assert.Equal(t, test.out.responseBody, deleteResults.Body.String(), test.desc)
+
+		// Assert recorded metrics
+		metricstest.AssertMetrics(t, test.out.expectedMetrics, mockMetrics)
+	}
+}
+
+func TestDeleteHandlerRemovesValue(t *testing.T) {
+	backend := backends.NewMemoryBackend(config.Memory{})
+	backend.Put(context.Background(), "36-char-key-maps-to-actual-xml-value", "xml<tag>data</tag>", 0)
+
+	router := httprouter.New()
+	mockMetrics := metricstest.CreateMockMetrics()
+	m := &metrics.Metrics{
+		MetricEngines: []metrics.CacheMetrics{
+			&mockMetrics,
+		},
+	}
+	router.DELETE("/cache", NewDeleteHandler(backend, m, false, testBackendTimeout))
+	router.GET("/cache", NewGetHandler(backend, m, 10, false, testBackendTimeout))
+
+	assert.Equal(t, http.StatusNoContent, doMockDelete(t, router, "36-char-key-maps-to-actual-xml-value").Code)
+	assert.Equal(t, http.StatusNotFound, doMockGet(t, router, "36-char-key-maps-to-actual-xml-value").Code)
+	assert.Equal(t, http.StatusNotFound, doMockDelete(t, router, "36-char-key-maps-to-actual-xml-value").Code)
+
+	_, err := backend.Get(context.Background(), "36-char-key-maps-to-actual-xml-value")
+	assert.Equal(t, utils.NewPBCError(utils.KEY_NOT_FOUND), err)
+}
+
+func doMockDelete(t *testing.T, router *httprouter.Router, id string) *httptest.ResponseRecorder {
+	requestRecorder := httptest.NewRecorder()
+
+	deleteReq, err := http.NewRequest("DELETE", "/cache"+"?uuid="+id, nil)
+	if err != nil {
+		t.Fatalf("Failed to create a DELETE request: %v", err)
+		return requestRecorder
+	}
+	router.ServeHTTP(requestRecorder, deleteReq)
+	return requestRecorder
+}
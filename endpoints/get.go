@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strings"
@@ -20,18 +21,23 @@ import (
 type GetHandler struct {
 	backend         backends.Backend
 	metrics         *metrics.Metrics
+	maxNumValues    int
 	allowCustomKeys bool
+	timeout         time.Duration
 }
 
-// NewGetHandler returns the handle function for the "/cache" endpoint when it receives a GET request
-func NewGetHandler(storage backends.Backend, metrics *metrics.Metrics, allowCustomKeys bool) func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+// NewGetHandler returns the handle function for the "/cache" endpoint when it receives a GET request.
+// Backend gets are given up after timeout or as soon as the client goes away
+func NewGetHandler(storage backends.Backend, metrics *metrics.Metrics, maxNumValues int, allowCustomKeys bool, timeout time.Duration) func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 	getHandler := &GetHandler{
 		// Assign storage client to get endpoint
 		backend: storage,
 		// pass metrics engine
 		metrics: metrics,
-		// Pass configuration value
+		// Pass configuration values
+		maxNumValues:    maxNumValues,
 		allowCustomKeys: allowCustomKeys,
+		timeout:         timeout,
 	}
 
 	// Return handle function
@@ -44,6 +50,12 @@ func (e *GetHandler) handle(w http.ResponseWriter, r *http.Request, ps httproute
 	logger.Info("Get /cache called")
 	stats.LogCacheRequestedGetStats()
 
+	// More than one uuid query parameter gets a JSON envelope response
+	if uuids := r.URL.Query()["uuid"]; len(uuids) > 1 {
+		e.handleMultiGet(w, r, uuids, start)
+		return
+	}
+
 	uuid, parseErr := parseUUID(r, e.allowCustomKeys)
 	if parseErr != nil {
 		// parseUUID either returns http.StatusBadRequest or http.StatusNotFound. Both should be
@@ -52,7 +64,7 @@ func (e *GetHandler) handle(w http.ResponseWriter, r *http.Request, ps httproute
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	ctx, cancel := context.WithTimeout(r.Context(), e.timeout)
 	defer cancel()
 
 	storedData, err := e.backend.Get(ctx, uuid)
@@ -75,36 +87,103 @@ func (e *GetHandler) handle(w http.ResponseWriter, r *http.Request, ps httproute
 	return
 }
 
+// handleMultiGet retrieves the values stored under every one of the uuids, in a single batch if the
+// backend supports it, and replies with a GetResponse JSON envelope that holds a status for each of
+// them. Errors found for individual uuids don't fail the whole request
+func (e *GetHandler) handleMultiGet(w http.ResponseWriter, r *http.Request, uuids []string, start time.Time) {
+	if len(uuids) > e.maxNumValues {
+		stats.LogCacheFailedGetStats(constant.KeyCountExceeded)
+		e.handleException(w, "", utils.NewPBCError(utils.GET_MAX_NUM_VALUES, fmt.Sprintf("More keys than allowed: %d", e.maxNumValues)))
+		return
+	}
+
+	getResponse := &GetResponse{Responses: make([]getResponseObject, len(uuids))}
+
+	// Validate every uuid before querying the backend. Only valid ones are requested
+	indexes := make([]int, 0, len(uuids))
+	keys := make([]string, 0, len(uuids))
+	for i, uuid := range uuids {
+		getResponse.Responses[i].UUID = uuid
+		if err := validateUUID(uuid, e.allowCustomKeys); err != nil {
+			getResponse.Responses[i].setError(err)
+			continue
+		}
+		indexes = append(indexes, i)
+		keys = append(keys, uuid)
+	}
+
+	if len(keys) > 0 {
+		ctx, cancel := context.WithTimeout(r.Context(), e.timeout)
+		defer cancel()
+
+		values, errs := backends.GetBatch(ctx, e.backend, keys)
+		for j, i := range indexes {
+			if errs[j] != nil {
+				stats.LogCacheMissStats()
+				logger.Info("Cache miss for uuid: %v", keys[j])
+				getResponse.Responses[i].setError(errs[j])
+				continue
+			}
+			getResponse.Responses[i].setValue(values[j])
+		}
+	}
+
+	bytes, err := json.Marshal(getResponse)
+	if err != nil {
+		e.handleException(w, "", utils.NewPBCError(utils.MARSHAL_RESPONSE))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(bytes)
+	logger.Info("Total time for multi-key get: %v", time.Now().Sub(start))
+	e.metrics.RecordGetDuration(time.Since(start))
+}
+
 // parseUUID extracts the uuid value from the query and validates its
 // lenght in case custom keys are not allowed.
 func parseUUID(r *http.Request, allowCustomKeys bool) (string, error) {
 	uuid := r.URL.Query().Get("uuid")
+	return uuid, validateUUID(uuid, allowCustomKeys)
+}
+
+// validateUUID makes sure uuid is not empty and checks its length in case
+// custom keys are not allowed.
+func validateUUID(uuid string, allowCustomKeys bool) error {
 	if uuid == "" {
 		stats.LogCacheFailedGetStats(constant.UUIDMissing)
-		return "", utils.NewPBCError(utils.MISSING_KEY)
+		return utils.NewPBCError(utils.MISSING_KEY)
 	}
 	// UUIDs are 36 characters long... so this quick check lets us filter out most invalid
 	// ones before even checking the backend.
 	if len(uuid) != 36 && (!allowCustomKeys) {
 		stats.LogCacheFailedGetStats(constant.InvalidUUID)
-		return uuid, utils.NewPBCError(utils.KEY_LENGTH)
+		return utils.NewPBCError(utils.KEY_LENGTH)
 	}
-	return uuid, nil
+	return nil
 }
 
 // writeGetResponse writes the "Content-Type" header and sends back the stored data as a response if
 // the sotred data is prefixed by either the "xml" or "json"
 func writeGetResponse(w http.ResponseWriter, storedData string) error {
+	contentType, body, err := parseStoredData(storedData)
+	if err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write([]byte(body))
+	return nil
+}
+
+// parseStoredData returns the content type of storedData according to its "xml" or "json" prefix,
+// followed by the data without said prefix
+func parseStoredData(storedData string) (string, string, error) {
 	if strings.HasPrefix(storedData, utils.XML_PREFIX) {
-		w.Header().Set("Content-Type", "application/xml")
-		w.Write([]byte(storedData)[len(utils.XML_PREFIX):])
+		return "application/xml", storedData[len(utils.XML_PREFIX):], nil
 	} else if strings.HasPrefix(storedData, utils.JSON_PREFIX) {
-		w.Header().Set("Content-Type", "application/json")
-		w.Write([]byte(storedData)[len(utils.JSON_PREFIX):])
-	} else {
-		return utils.NewPBCError(utils.UNKNOWN_STORED_DATA_TYPE)
+		return "application/json", storedData[len(utils.JSON_PREFIX):], nil
 	}
-	return nil
+	return "", "", utils.NewPBCError(utils.UNKNOWN_STORED_DATA_TYPE)
 }
 
 // handleException logs the error message, updates the error metrics based on error type and replies
@@ -147,3 +226,50 @@ func (e *GetHandler) handleException(w http.ResponseWriter, uuid string, err err
 		http.Error(w, errMsg, errCode)
 	}
 }
+
+// GetResponse will be marshaled to be written into the http response of a multi-key "GET /cache" request
+type GetResponse struct {
+	Responses []getResponseObject `json:"responses"`
+}
+
+// getResponseObject holds the outcome of retrieving a single uuid. JSON values are embedded as is while
+// XML values are embedded as a JSON string
+type getResponseObject struct {
+	UUID        string          `json:"uuid"`
+	Status      int             `json:"status"`
+	ContentType string          `json:"content_type,omitempty"`
+	Value       json.RawMessage `json:"value,omitempty"`
+	Error       string          `json:"error,omitempty"`
+}
+
+// setValue fills in the content type and value of storedData, or sets an error if its type can't be
+// determined
+func (resp *getResponseObject) setValue(storedData string) {
+	contentType, body, err := parseStoredData(storedData)
+	if err != nil {
+		resp.setError(err)
+		return
+	}
+
+	value := json.RawMessage(body)
+	if contentType == "application/xml" {
+		// Marshalling a string can't fail
+		value, _ = json.Marshal(body)
+	} else if !json.Valid(value) {
+		resp.setError(utils.NewPBCError(utils.UNKNOWN_STORED_DATA_TYPE))
+		return
+	}
+
+	resp.Status = http.StatusOK
+	resp.ContentType = contentType
+	resp.Value = value
+}
+
+// setError sets the status code that corresponds to err along with its message
+func (resp *getResponseObject) setError(err error) {
+	resp.Status = http.StatusInternalServerError
+	if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
+		resp.Status = pbcErr.StatusCode
+	}
+	resp.Error = err.Error()
+}
@@ -2,12 +2,15 @@ package endpoints
 
 import (
 	"bytes"
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/prebid/prebid-cache/backends"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/metrics/metricstest"
 	"github.com/prebid/prebid-cache/metrics/stats"
@@ -16,12 +19,15 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// testBackendTimeout is the time the handlers under test give backend calls
+const testBackendTimeout = 500 * time.Millisecond
+
 func init() {
 	stats.InitStat(&stats.StatsConfig{})
 }
 
 func TestGetInvalidUUIDs(t *testing.T) {
-	backend := backends.NewMemoryBackend()
+	backend := backends.NewMemoryBackend(config.Memory{})
 	router := httprouter.New()
 
 	mockMetrics := metricstest.CreateMockMetrics()
@@ -31,7 +37,7 @@ func TestGetInvalidUUIDs(t *testing.T) {
 		},
 	}
 
-	router.GET("/cache", NewGetHandler(backend, m, false))
+	router.GET("/cache", NewGetHandler(backend, m, 10, false, testBackendTimeout))
 
 	getResults := doMockGet(t, router, "fdd9405b-ef2b-46da-a55a-2f526d338e16")
 	if getResults.Code != http.StatusNotFound {
@@ -131,7 +137,7 @@ func TestGetHandler(t *testing.T) {
 				&mockMetrics,
 			},
 		}
-		router.GET("/cache", NewGetHandler(backend, m, test.in.allowKeys))
+		router.GET("/cache", NewGetHandler(backend, m, 10, test.in.allowKeys, testBackendTimeout))
 
 		// Run test
 		getResults := httptest.NewRecorder()
@@ -165,3 +171,175 @@ func TestGetHandler(t *testing.T) {
 		hook.Reset()
 	}
 }
+
+func TestMultiKeyGetHandler(t *testing.T) {
+	preExistentDataInBackend := map[string]string{
+		"36-char-key-maps-to-actual-xml-value": "xml<tag>xml data here</tag>",
+		"36-char-key-maps-to-actual-json-data": `json{"field":"value"}`,
+		"36-char-key-maps-to-non-xml-nor-json": `#@!*{"desc":"data got malformed and is not prefixed with 'xml' nor 'json' substring"}`,
+	}
+
+	type testInput struct {
+		query        string
+		maxNumValues int
+		allowKeys    bool
+	}
+	type testOutput struct {
+		responseCode    int
+		responseBody    string
+		expectedMetrics []string
+	}
+
+	testCases := []struct {
+		desc string
+		in   testInput
+		out  testOutput
+	}{
+		{
+			"Every uuid maps to a value. Respond with a 200 envelope holding all of them",
+			testInput{
+				query:        "uuid=36-char-key-maps-to-actual-xml-value&uuid=36-char-key-maps-to-actual-json-data",
+				maxNumValues: 10,
+			},
+			testOutput{
+				responseCode: http.StatusOK,
+				responseBody: `{"responses":[` +
+					`{"uuid":"36-char-key-maps-to-actual-xml-value","status":200,"content_type":"application/xml","value":"\u003ctag\u003exml data here\u003c/tag\u003e"},` +
+					`{"uuid":"36-char-key-maps-to-actual-json-data","status":200,"content_type":"application/json","value":{"field":"value"}}` +
+					`]}`,
+				expectedMetrics: []string{"RecordGetTotal", "RecordGetDuration"},
+			},
+		},
+		{
+			"Some uuids fail. Respond with a 200 envelope holding a status for each one of them",
+			testInput{
+				query:        "uuid=36-char-key-maps-to-actual-xml-value&uuid=fdd9405b-ef2b-46da-a55a-2f526d338e16&uuid=short&uuid=36-char-key-maps-to-non-xml-nor-json",
+				maxNumValues: 10,
+			},
+			testOutput{
+				responseCode: http.StatusOK,
+				responseBody: `{"responses":[` +
+					`{"uuid":"36-char-key-maps-to-actual-xml-value","status":200,"content_type":"application/xml","value":"\u003ctag\u003exml data here\u003c/tag\u003e"},` +
+					`{"uuid":"fdd9405b-ef2b-46da-a55a-2f526d338e16","status":404,"error":"Key not found"},` +
+					`{"uuid":"short","status":404,"error":"invalid uuid length"},` +
+					`{"uuid":"36-char-key-maps-to-non-xml-nor-json","status":500,"error":"Cache data was corrupted. Cannot determine type."}` +
+					`]}`,
+				expectedMetrics: []string{"RecordGetTotal", "RecordGetDuration"},
+			},
+		},
+		{
+			"Custom keys are allowed. Short uuids are looked up in the backend",
+			testInput{
+				query:        "uuid=short&uuid=",
+				maxNumValues: 10,
+				allowKeys:    true,
+			},
+			testOutput{
+				responseCode: http.StatusOK,
+				responseBody: `{"responses":[` +
+					`{"uuid":"short","status":404,"error":"Key not found"},` +
+					`{"uuid":"","status":400,"error":"Missing required parameter uuid"}` +
+					`]}`,
+				expectedMetrics: []string{"RecordGetTotal", "RecordGetDuration"},
+			},
+		},
+		{
+			"More uuids than allowed. Respond with a 400 status code",
+			testInput{
+				query:        "uuid=36-char-key-maps-to-actual-xml-value&uuid=36-char-key-maps-to-actual-json-data",
+				maxNumValues: 1,
+			},
+			testOutput{
+				responseCode:    http.StatusBadRequest,
+				responseBody:    "GET /cache: More keys than allowed: 1\n",
+				expectedMetrics: []string{"RecordGetTotal", "RecordGetBadRequest"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		// Set up test object
+		backend, err := backends.NewMemoryBackendWithValues(preExistentDataInBackend)
+		if !assert.NoError(t, err, "%s. Mock backend could not be created", test.desc) {
+			continue
+		}
+		router := httprouter.New()
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		}
+		router.GET("/cache", NewGetHandler(backend, m, test.in.maxNumValues, test.in.allowKeys, testBackendTimeout))
+
+		// Run test
+		getResults := httptest.NewRecorder()
+		getReq, err := http.NewRequest("GET", "/cache?"+test.in.query, nil)
+		if !assert.NoError(t, err, "Failed to create a GET request: %v", err) {
+			continue
+		}
+		router.ServeHTTP(getResults, getReq)
+
+		// Assert server response and status code
+		assert.Equal(t, test.out.responseCode, getResults.Code, test.desc)
+		assert.Equal(t, test.out.responseBody, getResults.Body.String(), test.desc)
+
+		// Assert recorded metrics
+		metricstest.AssertMetrics(t, test.out.expectedMetrics, mockMetrics)
+	}
+}
+
+// blockingBackend holds gets until their context is done and keeps the error it was done with
+type blockingBackend struct {
+	backends.MemoryBackend
+	ctxErr chan error
+}
+
+func (b *blockingBackend) Get(ctx context.Context, key string) (string, error) {
+	<-ctx.Done()
+	b.ctxErr <- ctx.Err()
+	return "", ctx.Err()
+}
+
+func TestGetBackendContext(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		inTimeout     time.Duration
+		inCancel      bool
+		expectedError error
+	}{
+		{
+			desc:          "Backend get canceled once the client goes away",
+			inTimeout:     time.Minute,
+			inCancel:      true,
+			expectedError: context.Canceled,
+		},
+		{
+			desc:          "Backend get given up after the configured timeout",
+			inTimeout:     time.Millisecond,
+			expectedError: context.DeadlineExceeded,
+		},
+	}
+
+	for _, test := range testCases {
+		backend := &blockingBackend{ctxErr: make(chan error, 1)}
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		}
+		router := httprouter.New()
+		router.GET("/cache", NewGetHandler(backend, m, 10, false, test.inTimeout))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, "GET", "/cache?uuid=fdd9405b-ef2b-46da-a55a-2f526d338e16", nil)
+		if test.inCancel {
+			cancel()
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+		cancel()
+
+		assert.Equal(t, test.expectedError, <-backend.ctxErr, test.desc)
+	}
+}
@@ -30,6 +30,7 @@ type PutHandler struct {
 type putHandlerConfig struct {
 	maxNumValues int
 	allowKeys    bool
+	timeout      time.Duration
 }
 
 type syncPools struct {
@@ -37,8 +38,9 @@ type syncPools struct {
 	putResponsePool sync.Pool
 }
 
-// NewPutHandler returns the handle function for the "/cache" endpoint when it receives a POST request
-func NewPutHandler(storage backends.Backend, metrics *metrics.Metrics, maxNumValues int, allowKeys bool) func(http.ResponseWriter, *http.Request, httprouter.Params) {
+// NewPutHandler returns the handle function for the "/cache" endpoint when it receives a POST request.
+// Backend puts are given up after timeout or as soon as the client goes away
+func NewPutHandler(storage backends.Backend, metrics *metrics.Metrics, maxNumValues int, allowKeys bool, timeout time.Duration) func(http.ResponseWriter, *http.Request, httprouter.Params) {
 	putHandler := &PutHandler{}
 
 	// Assign storage client to put endpoint
@@ -51,6 +53,7 @@ func NewPutHandler(storage backends.Backend, metrics *metrics.Metrics, maxNumVal
 	putHandler.cfg = putHandlerConfig{
 		maxNumValues: maxNumValues,
 		allowKeys:    allowKeys,
+		timeout:      timeout,
 	}
 
 	// Instantiate thread-safe memory pools
@@ -167,6 +170,15 @@ func classifyBackendError(err error, index int) error {
 		return utils.NewPBCError(utils.BAD_PAYLOAD_SIZE, fmt.Sprintf("POST /cache element %d exceeded max size: %v", index, err.Error()))
 	}
 
+	if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr {
+		switch pbcErr.Type {
+		case utils.PUT_DEADLINE_EXCEEDED, utils.BACKEND_UNAVAILABLE, utils.KEY_LENGTH:
+			// Backends that detect their own timeouts already report them as such, an open
+			// circuit breaker must keep its status code and so must keys a backend can't store
+			return err
+		}
+	}
+
 	switch err {
 	case context.DeadlineExceeded:
 		return utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED)
@@ -178,7 +190,7 @@ func classifyBackendError(err error, index int) error {
 func logBackendError(err error) {
 	logger.Error("POST /cache Error while writing to the backend: %+v", err)
 
-	if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr && pbcErr.StatusCode == utils.PUT_DEADLINE_EXCEEDED {
+	if pbcErr, isPBCErr := err.(utils.PBCError); isPBCErr && pbcErr.Type == utils.PUT_DEADLINE_EXCEEDED {
 		stats.LogCacheFailedPutStats(constant.TimedOut)
 		logger.Error("POST /cache timed out: %+v", err)
 	} else {
@@ -242,7 +254,7 @@ func (e *PutHandler) processPutRequest(r *http.Request) ([]byte, error) {
 	defer e.memory.putResponsePool.Put(putResponse)
 
 	// Send elements to storage service or database
-	if pcErr := e.putElements(putRequest, putResponse); pcErr != nil {
+	if pcErr := e.putElements(r.Context(), putRequest, putResponse); pcErr != nil {
 		return nil, pcErr
 	}
 
@@ -255,20 +267,46 @@ func (e *PutHandler) processPutRequest(r *http.Request) ([]byte, error) {
 	return bytes, nil
 }
 
-// putElements calls put(po *putObject, wg *sync.WaitGroup) in parallel and if any of those calls generates an error, logs the
-// first one in the order its corresponding putObject came inside the []PutRequest.Puts array
+// putElements parses and validates every element of the []PutRequest.Puts array and sends the valid ones to the
+// storage back-end. When more than one element needs to be stored, they are sent in a single batch so back-ends that
+// support it can store them all in one round trip; the rest fall back to one Put() call per element. If any element
+// generates an error, logs the first one in the order its corresponding putObject came inside the array and returns it
 //
-// TODO: For those storage clients that support storing multiple elements in a single call, build a batch and send them together
 // TODO: Allow Prebid Cache to provide error details in an "errors" field in the response
-func (e *PutHandler) putElements(put *putRequest, resps *PutResponse) error {
-	// Call Put() implementation of storage back-end in parrallel
-	var waitGroup sync.WaitGroup
-	waitGroup.Add(len(put.Puts))
-
+func (e *PutHandler) putElements(ctx context.Context, put *putRequest, resps *PutResponse) error {
+	items := make([]backends.BatchPutItem, 0, len(put.Puts))
+	indexes := make([]int, 0, len(put.Puts))
 	for i := 0; i < len(put.Puts); i++ {
-		go e.put(&put.Puts[i], &resps.Responses[i], i, &waitGroup)
+		if item, ok := e.preparePut(&put.Puts[i], &resps.Responses[i]); ok {
+			items = append(items, item)
+			indexes = append(indexes, i)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.cfg.timeout)
+	defer cancel()
+
+	var errs []error
+	switch len(items) {
+	case 0:
+	case 1:
+		errs = []error{e.backend.Put(ctx, items[0].Key, items[0].Value, items[0].TTLSeconds)}
+	default:
+		errs = backends.PutBatch(ctx, e.backend, items)
+	}
+
+	for j, err := range errs {
+		if err == nil {
+			continue
+		}
+		i := indexes[j]
+		if pbcErr, isPbcErr := err.(utils.PBCError); isPbcErr && pbcErr.Type == utils.RECORD_EXISTS {
+			// Record didn't get overwritten, return a response with an empty UUID string
+			resps.Responses[i].UUID = ""
+		} else {
+			resps.Responses[i].err = classifyBackendError(err, i)
+		}
 	}
-	waitGroup.Wait()
 
 	// Log the first element found and return it
 	for _, resp := range resps.Responses {
@@ -281,16 +319,14 @@ func (e *PutHandler) putElements(put *putRequest, resps *PutResponse) error {
 	return nil
 }
 
-// put parses the putObject, validates it and calls the back-end storage Put() function this Prebid Cache instance
-// is using. Returns a putResponseObject storing either the corresponding UUID's data was stored under, or an error
-// if any.
-func (e *PutHandler) put(po *putObject, resp *putResponseObject, index int, wg *sync.WaitGroup) {
-	defer wg.Done()
-
+// preparePut parses the putObject, validates it and assigns the UUID its data is going to be stored under. Returns
+// the item to send to the back-end storage and true, or false if there's nothing to store, in which case resp holds
+// the error found, if any.
+func (e *PutHandler) preparePut(po *putObject, resp *putResponseObject) (backends.BatchPutItem, bool) {
 	toCache, err := parsePutObject(*po)
 	if err != nil {
 		resp.err = err
-		return
+		return backends.BatchPutItem{}, false
 	}
 
 	// Only allow setting a provided key if configured (and ensure a key is provided).
@@ -304,28 +340,18 @@ func (e *PutHandler) put(po *putObject, resp *putResponseObject, index int, wg *
 		if resp.UUID, err = utils.GenerateRandomID(); err != nil {
 			resp.UUID = ""
 			resp.err = utils.NewPBCError(utils.PUT_INTERNAL_SERVER, "Error generating version 4 UUID")
-			return
+			return backends.BatchPutItem{}, false
 		}
 	}
 
 	// If we have a blank UUID, don't store anything.
 	// Eventually we may want to provide error details, but as of today this is the only non-fatal error
 	// Future error details could go into a second property of the Responses object, such as "errors"
-	if len(resp.UUID) > 0 {
-		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
-		defer cancel()
-
-		err = e.backend.Put(ctx, resp.UUID, toCache, po.TTLSeconds)
-		if err != nil {
-			if pbcErr, isPbcErr := err.(utils.PBCError); isPbcErr && pbcErr.Type == utils.RECORD_EXISTS {
-				// Record didn't get overwritten, return a response with an empty UUID string
-				resp.UUID = ""
-			} else {
-				resp.err = classifyBackendError(err, index)
-			}
-		}
+	if len(resp.UUID) == 0 {
+		return backends.BatchPutItem{}, false
 	}
-	return
+
+	return backends.BatchPutItem{Key: resp.UUID, Value: toCache, TTLSeconds: po.TTLSeconds}, true
 }
 
 type putRequest struct {
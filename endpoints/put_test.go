@@ -18,6 +18,7 @@ import (
 	"github.com/prebid/prebid-cache/backends"
 	"github.com/prebid/prebid-cache/backends/decorators"
 	backendDecorators "github.com/prebid/prebid-cache/backends/decorators"
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/metrics"
 	"github.com/prebid/prebid-cache/metrics/metricstest"
 	"github.com/prebid/prebid-cache/utils"
@@ -29,6 +30,8 @@ import (
 // TestStatusEndpointReadiness asserts the http://<prebid-cache-host>/status endpoint
 // is responds as expected.
 func TestStatusEndpointReadiness(t *testing.T) {
+	healthCheckConfig := config.HealthCheck{Enabled: true, IntervalSeconds: 60, TimeoutMs: 100}
+
 	type testCase struct {
 		description      string
 		handler          httprouter.Handle
@@ -39,22 +42,34 @@ func TestStatusEndpointReadiness(t *testing.T) {
 	testCases := []testCase{
 		{
 			description:      "Empty response",
-			handler:          NewStatusEndpoint(""),
+			handler:          NewStatusEndpoint("", nil),
 			expectedRespCode: http.StatusNoContent,
 			expectedRespBody: bytes.NewBuffer(nil),
 		},
 		{
 			description:      "string response",
-			handler:          NewStatusEndpoint("ready"),
+			handler:          NewStatusEndpoint("ready", nil),
 			expectedRespCode: http.StatusOK,
 			expectedRespBody: bytes.NewBuffer([]byte("ready")),
 		},
 		{
 			description:      "JSON string response",
-			handler:          NewStatusEndpoint(`{"status": "ok"}`),
+			handler:          NewStatusEndpoint(`{"status": "ok"}`, nil),
 			expectedRespCode: http.StatusOK,
 			expectedRespBody: bytes.NewBuffer([]byte(`{"status": "ok"}`)),
 		},
+		{
+			description:      "Healthy backend",
+			handler:          NewStatusEndpoint("ready", backends.NewHealthMonitor(backends.NewMemoryBackend(config.Memory{}), "memory", healthCheckConfig)),
+			expectedRespCode: http.StatusOK,
+			expectedRespBody: bytes.NewBuffer([]byte("ready")),
+		},
+		{
+			description:      "Unhealthy backend",
+			handler:          NewStatusEndpoint("ready", backends.NewHealthMonitor(backends.NewErrorResponseMemoryBackend(), "memory", healthCheckConfig)),
+			expectedRespCode: http.StatusServiceUnavailable,
+			expectedRespBody: bytes.NewBuffer([]byte(`{"status":"unavailable","backend":"memory","error":"Bakend error"}`)),
+		},
 	}
 
 	for _, tc := range testCases {
@@ -201,7 +216,7 @@ func TestSuccessfulPut(t *testing.T) {
 		for _, tc := range group.testCases {
 			// set test
 			router := httprouter.New()
-			backend := backends.NewMemoryBackend()
+			backend := backends.NewMemoryBackend(config.Memory{})
 			mockMetrics := metricstest.CreateMockMetrics()
 			m := &metrics.Metrics{
 				MetricEngines: []metrics.CacheMetrics{
@@ -209,8 +224,8 @@ func TestSuccessfulPut(t *testing.T) {
 				},
 			}
 
-			router.POST("/cache", NewPutHandler(backend, m, 10, true))
-			router.GET("/cache", NewGetHandler(backend, m, true))
+			router.POST("/cache", NewPutHandler(backend, m, 10, true, testBackendTimeout))
+			router.GET("/cache", NewGetHandler(backend, m, 10, true, testBackendTimeout))
 
 			// Feed the tests input put request to the endpoint's handle
 			putResponse := doPut(t, router, tc.inPutBody)
@@ -299,7 +314,7 @@ func TestMalformedOrInvalidValue(t *testing.T) {
 			},
 		}
 
-		router.POST("/cache", NewPutHandler(backend, m, 10, true))
+		router.POST("/cache", NewPutHandler(backend, m, 10, true, testBackendTimeout))
 
 		// Run test
 		putResponse := doPut(t, router, tc.inPutBody)
@@ -335,7 +350,7 @@ func TestNonSupportedType(t *testing.T) {
 			&mockMetrics,
 		},
 	}
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, true, testBackendTimeout))
 
 	putResponse := doPut(t, router, requestBody)
 
@@ -363,7 +378,7 @@ func TestPutNegativeTTL(t *testing.T) {
 
 	// Set up server to run our test
 	testRouter := httprouter.New()
-	testBackend := backends.NewMemoryBackend()
+	testBackend := backends.NewMemoryBackend(config.Memory{})
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
@@ -371,7 +386,7 @@ func TestPutNegativeTTL(t *testing.T) {
 		},
 	}
 
-	testRouter.POST("/cache", NewPutHandler(testBackend, m, 10, true))
+	testRouter.POST("/cache", NewPutHandler(testBackend, m, 10, true, testBackendTimeout))
 
 	recorder := httptest.NewRecorder()
 
@@ -473,7 +488,7 @@ func TestCustomKey(t *testing.T) {
 			}
 
 			router := httprouter.New()
-			putEndpointHandler := NewPutHandler(mockBackendWithValues, m, 10, tgroup.allowSettingKeys)
+			putEndpointHandler := NewPutHandler(mockBackendWithValues, m, 10, tgroup.allowSettingKeys, testBackendTimeout)
 			router.POST("/cache", putEndpointHandler)
 
 			recorder := httptest.NewRecorder()
@@ -510,7 +525,7 @@ func TestRequestReadError(t *testing.T) {
 			&mockMetrics,
 		},
 	}
-	putEndpointHandler := NewPutHandler(mockBackendWithValues, m, 10, false)
+	putEndpointHandler := NewPutHandler(mockBackendWithValues, m, 10, false, testBackendTimeout)
 
 	router := httprouter.New()
 	router.POST("/cache", putEndpointHandler)
@@ -555,7 +570,7 @@ func TestTooManyPutElements(t *testing.T) {
 			&mockMetrics,
 		},
 	}
-	router.POST("/cache", NewPutHandler(backend, m, len(putElements)-1, true))
+	router.POST("/cache", NewPutHandler(backend, m, len(putElements)-1, true, testBackendTimeout))
 
 	putResponse := doPut(t, router, reqBody)
 
@@ -602,7 +617,7 @@ func TestMultiPutRequest(t *testing.T) {
 
 	// Set up server and run
 	router := httprouter.New()
-	backend := backends.NewMemoryBackend()
+	backend := backends.NewMemoryBackend(config.Memory{})
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
@@ -610,8 +625,8 @@ func TestMultiPutRequest(t *testing.T) {
 		},
 	}
 
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
-	router.GET("/cache", NewGetHandler(backend, m, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, true, testBackendTimeout))
+	router.GET("/cache", NewGetHandler(backend, m, 10, true, testBackendTimeout))
 
 	rr := httptest.NewRecorder()
 
@@ -643,6 +658,61 @@ func TestMultiPutRequest(t *testing.T) {
 	metricstest.AssertMetrics(t, expectedMetrics, mockMetrics)
 }
 
+func TestMultiPutRequestIsBatched(t *testing.T) {
+	testCases := []struct {
+		desc                 string
+		reqBody              string
+		expectedPutCalls     int
+		expectedBatchCalls   int
+		expectedBatchedItems int
+	}{
+		{
+			desc:               "Single element put requests don't get batched",
+			reqBody:            `{"puts":[{"type":"json","value":true}]}`,
+			expectedPutCalls:   1,
+			expectedBatchCalls: 0,
+		},
+		{
+			desc:                 "Multi-element put requests get sent in a single batch",
+			reqBody:              `{"puts":[{"type":"json","value":true},{"type":"xml","value":"plain text"},{"type":"json","value":1}]}`,
+			expectedPutCalls:     0,
+			expectedBatchCalls:   1,
+			expectedBatchedItems: 3,
+		},
+		{
+			desc:                 "Invalid elements are left out of the batch",
+			reqBody:              `{"puts":[{"type":"json","value":true},{"type":"unknown","value":"plain text"},{"type":"json","value":1}]}`,
+			expectedPutCalls:     0,
+			expectedBatchCalls:   1,
+			expectedBatchedItems: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		// Set up server
+		backend := &batchCountingBackend{Backend: backends.NewMemoryBackend(config.Memory{})}
+		mockMetrics := metricstest.CreateMockMetrics()
+		m := &metrics.Metrics{
+			MetricEngines: []metrics.CacheMetrics{
+				&mockMetrics,
+			},
+		}
+		router := httprouter.New()
+		router.POST("/cache", NewPutHandler(backend, m, 10, false, testBackendTimeout))
+
+		request, err := http.NewRequest("POST", "/cache", strings.NewReader(tc.reqBody))
+		assert.NoError(t, err, tc.desc)
+
+		// Run test
+		router.ServeHTTP(httptest.NewRecorder(), request)
+
+		// Assertions
+		assert.Equal(t, tc.expectedPutCalls, backend.putCalls, tc.desc)
+		assert.Equal(t, tc.expectedBatchCalls, backend.batchCalls, tc.desc)
+		assert.Equal(t, tc.expectedBatchedItems, backend.batchedItems, tc.desc)
+	}
+}
+
 func TestBadPayloadSizePutError(t *testing.T) {
 	// Stored value size_limit
 	sizeLimit := 3
@@ -651,7 +721,7 @@ func TestBadPayloadSizePutError(t *testing.T) {
 	reqBody := "{\"puts\":[{\"type\":\"xml\",\"value\":\"text longer than size limit\"}]}"
 
 	// Declare a sizeCappedBackend client
-	backend := backendDecorators.EnforceSizeLimit(backends.NewMemoryBackend(), sizeLimit)
+	backend := backendDecorators.EnforceSizeLimit(backends.NewMemoryBackend(config.Memory{}), sizeLimit)
 
 	// Run client
 	router := httprouter.New()
@@ -661,7 +731,7 @@ func TestBadPayloadSizePutError(t *testing.T) {
 			&mockMetrics,
 		},
 	}
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, true, testBackendTimeout))
 
 	putResponse := doPut(t, router, reqBody)
 
@@ -698,7 +768,7 @@ func TestInternalPutClientError(t *testing.T) {
 	// Use mock client that will return an error
 	backendWithMetrics := decorators.LogMetrics(newErrorReturningBackend(), m)
 
-	router.POST("/cache", NewPutHandler(backendWithMetrics, m, 10, true))
+	router.POST("/cache", NewPutHandler(backendWithMetrics, m, 10, true, testBackendTimeout))
 
 	// Run test
 	putResponse := doPut(t, router, reqBody)
@@ -764,7 +834,7 @@ func TestEmptyPutRequests(t *testing.T) {
 
 	for i, tc := range testCases {
 		// Set up server
-		backend := backends.NewMemoryBackend()
+		backend := backends.NewMemoryBackend(config.Memory{})
 		mockMetrics := metricstest.CreateMockMetrics()
 		m := &metrics.Metrics{
 			MetricEngines: []metrics.CacheMetrics{
@@ -772,7 +842,7 @@ func TestEmptyPutRequests(t *testing.T) {
 			},
 		}
 		router := httprouter.New()
-		router.POST("/cache", NewPutHandler(backend, m, 10, true))
+		router.POST("/cache", NewPutHandler(backend, m, 10, true, testBackendTimeout))
 		rr := httptest.NewRecorder()
 
 		// Create request everytime
@@ -815,7 +885,7 @@ func TestPutClientDeadlineExceeded(t *testing.T) {
 			&mockMetrics,
 		},
 	}
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, true, testBackendTimeout))
 
 	putResponse := doPut(t, router, reqBody)
 
@@ -889,7 +959,7 @@ func TestParseRequest(t *testing.T) {
 					New: func() interface{} { return &putRequest{} },
 				},
 			},
-			cfg: putHandlerConfig{maxNumValues: 1},
+			cfg: putHandlerConfig{maxNumValues: 1, timeout: testBackendTimeout},
 		}
 		// run
 		put, err := putHandler.parseRequest(tc.getInputRequest())
@@ -1016,6 +1086,30 @@ func TestClassifyBackendError(t *testing.T) {
 				utils.HTTPDependencyTimeout,
 			},
 		},
+		{
+			"Timeout reported by the backend",
+			utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+			testOutput{
+				utils.NewPBCError(utils.PUT_DEADLINE_EXCEEDED),
+				utils.HTTPDependencyTimeout,
+			},
+		},
+		{
+			"Open circuit breaker",
+			utils.NewPBCError(utils.BACKEND_UNAVAILABLE),
+			testOutput{
+				utils.NewPBCError(utils.BACKEND_UNAVAILABLE),
+				http.StatusServiceUnavailable,
+			},
+		},
+		{
+			"Key longer than the backend allows",
+			utils.NewPBCError(utils.KEY_LENGTH, "Key length 300 exceeded SQL backend max key length 255"),
+			testOutput{
+				utils.NewPBCError(utils.KEY_LENGTH, "Key length 300 exceeded SQL backend max key length 255"),
+				http.StatusNotFound,
+			},
+		},
 		{
 			"Backend client error",
 			errors.New("Server memory error"),
@@ -1103,7 +1197,7 @@ func benchmarkPutHandler(b *testing.B, testCase string) {
 
 	//Set up server ready to run
 	router := httprouter.New()
-	backend := backends.NewMemoryBackend()
+	backend := backends.NewMemoryBackend(config.Memory{})
 	mockMetrics := metricstest.CreateMockMetrics()
 	m := &metrics.Metrics{
 		MetricEngines: []metrics.CacheMetrics{
@@ -1111,8 +1205,8 @@ func benchmarkPutHandler(b *testing.B, testCase string) {
 		},
 	}
 
-	router.POST("/cache", NewPutHandler(backend, m, 10, true))
-	router.GET("/cache", NewGetHandler(backend, m, true))
+	router.POST("/cache", NewPutHandler(backend, m, 10, true, testBackendTimeout))
+	router.GET("/cache", NewGetHandler(backend, m, 10, true, testBackendTimeout))
 
 	rr := httptest.NewRecorder()
 
@@ -1148,6 +1242,10 @@ func (b *errorReturningBackend) Put(ctx context.Context, key string, value strin
 	return fmt.Errorf("This is a mock backend that returns this error on Put() operation")
 }
 
+func (b *errorReturningBackend) Delete(ctx context.Context, key string) error {
+	return fmt.Errorf("This is a mock backend that returns this error on Delete() operation")
+}
+
 func newErrorReturningBackend() *errorReturningBackend {
 	return &errorReturningBackend{}
 }
@@ -1158,6 +1256,10 @@ func (b *deadlineExceedingBackend) Get(ctx context.Context, key string) (string,
 	return "", nil
 }
 
+func (b *deadlineExceedingBackend) Delete(ctx context.Context, key string) error {
+	return nil
+}
+
 func (b *deadlineExceedingBackend) Put(ctx context.Context, key string, value string, ttlSeconds int) error {
 	var err error
 
@@ -1196,3 +1298,28 @@ func (m *mockBackend) Put(ctx context.Context, key, value string, ttlSeconds int
 	args := m.Called(ctx, key, value, ttlSeconds)
 	return args.Error(0)
 }
+
+func (m *mockBackend) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+// batchCountingBackend implements backends.BatchPutter on top of the Backend it embeds and
+// counts how many times each kind of put gets called
+type batchCountingBackend struct {
+	backends.Backend
+	putCalls     int
+	batchCalls   int
+	batchedItems int
+}
+
+func (b *batchCountingBackend) Put(ctx context.Context, key, value string, ttlSeconds int) error {
+	b.putCalls++
+	return b.Backend.Put(ctx, key, value, ttlSeconds)
+}
+
+func (b *batchCountingBackend) PutBatch(ctx context.Context, items []backends.BatchPutItem) []error {
+	b.batchCalls++
+	b.batchedItems += len(items)
+	return backends.PutBatch(ctx, b.Backend, items)
+}
@@ -15,16 +15,16 @@ import (
 	"github.com/rs/cors"
 )
 
-func NewAdminHandler(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics) http.Handler {
+func NewAdminHandler(cfg config.Configuration, dataStore backends.Backend, health *backends.HealthMonitor, appMetrics *metrics.Metrics) http.Handler {
 	router := httprouter.New()
-	addReadRoutes(cfg, dataStore, appMetrics, router)
+	addReadRoutes(cfg, dataStore, health, appMetrics, router)
 	addWriteRoutes(cfg, dataStore, appMetrics, router)
 	return router
 }
 
-func NewPublicHandler(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics) http.Handler {
+func NewPublicHandler(cfg config.Configuration, dataStore backends.Backend, health *backends.HealthMonitor, appMetrics *metrics.Metrics) http.Handler {
 	router := httprouter.New()
-	addReadRoutes(cfg, dataStore, appMetrics, router)
+	addReadRoutes(cfg, dataStore, health, appMetrics, router)
 	if cfg.Routes.AllowPublicWrite {
 		addWriteRoutes(cfg, dataStore, appMetrics, router)
 	}
@@ -34,16 +34,17 @@ func NewPublicHandler(cfg config.Configuration, dataStore backends.Backend, appM
 	return handler
 }
 
-func addReadRoutes(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics, router *httprouter.Router) {
-	router.GET("/", endpoints.NewIndexHandler(cfg.IndexResponse))          // Default route handler
-	router.GET("/status", endpoints.NewStatusEndpoint(cfg.StatusResponse)) // Determines whether the server is ready for more traffic.
-	router.GET("/cache", endpoints.NewGetHandler(dataStore, appMetrics, cfg.RequestLimits.AllowSettingKeys))
+func addReadRoutes(cfg config.Configuration, dataStore backends.Backend, health *backends.HealthMonitor, appMetrics *metrics.Metrics, router *httprouter.Router) {
+	router.GET("/", endpoints.NewIndexHandler(cfg.IndexResponse))                  // Default route handler
+	router.GET("/status", endpoints.NewStatusEndpoint(cfg.StatusResponse, health)) // Determines whether the server is ready for more traffic.
+	router.GET("/cache", endpoints.NewGetHandler(dataStore, appMetrics, cfg.RequestLimits.MaxNumValues, cfg.RequestLimits.AllowSettingKeys, cfg.Timeouts.BackendReadTimeout()))
 	router.GET("/version", endpoints.NewVersionEndpoint(version.Ver, version.Rev))
 	router.GET("/healthcheck", endpoints.HealthCheck) // Determines whether the server is up and running.
 }
 
 func addWriteRoutes(cfg config.Configuration, dataStore backends.Backend, appMetrics *metrics.Metrics, router *httprouter.Router) {
-	router.POST("/cache", endpoints.NewPutHandler(dataStore, appMetrics, cfg.RequestLimits.MaxNumValues, cfg.RequestLimits.AllowSettingKeys))
+	router.POST("/cache", endpoints.NewPutHandler(dataStore, appMetrics, cfg.RequestLimits.MaxNumValues, cfg.RequestLimits.AllowSettingKeys, cfg.Timeouts.BackendWriteTimeout()))
+	router.DELETE("/cache", endpoints.NewDeleteHandler(dataStore, appMetrics, cfg.RequestLimits.AllowSettingKeys, cfg.Timeouts.BackendWriteTimeout()))
 }
 
 func handleCors(handler http.Handler) http.Handler {
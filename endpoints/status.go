@@ -1,22 +1,51 @@
 package endpoints
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/prebid/prebid-cache/backends"
 )
 
+// statusUnavailable is the body of the response sent while the backend is unhealthy
+type statusUnavailable struct {
+	Status  string `json:"status"`
+	Backend string `json:"backend"`
+	Error   string `json:"error"`
+}
+
 // NewStatusEndpoint returns a handler which writes the given response when the app is ready to serve requests.
-func NewStatusEndpoint(response string) httprouter.Handle {
-	// Today, the app always considers itself ready to serve requests.
-	if response == "" {
-		return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-			w.WriteHeader(http.StatusNoContent)
+// If health is not nil, the app is ready as long as the last backend health check succeeded. Otherwise, it
+// responds with a 503 and a JSON body describing the backend error.
+func NewStatusEndpoint(response string, health *backends.HealthMonitor) httprouter.Handle {
+	ready := func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+	if response != "" {
+		responseBytes := []byte(response)
+		ready = func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+			w.Write(responseBytes)
 		}
 	}
+	if health == nil {
+		return ready
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+		err := health.Err()
+		if err == nil {
+			ready(w, r, ps)
+			return
+		}
 
-	responseBytes := []byte(response)
-	return func(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
-		w.Write(responseBytes)
+		body, _ := json.Marshal(statusUnavailable{
+			Status:  "unavailable",
+			Backend: health.Name(),
+			Error:   err.Error(),
+		})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write(body)
 	}
 }
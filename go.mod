@@ -8,15 +8,19 @@ replace github.com/prebid/prebid-cache => ./
 
 require (
 	git.pubmatic.com/PubMatic/go-common v0.0.0-20231211162912-5d6b67bde771
+	github.com/DATA-DOG/go-sqlmock v1.5.2
 	github.com/aerospike/aerospike-client-go/v7 v7.8.0
+	github.com/alicebob/miniredis/v2 v2.31.1
 	github.com/didip/tollbooth/v6 v6.1.2
 	github.com/go-redis/redis/v8 v8.11.5
+	github.com/go-sql-driver/mysql v1.8.1
 	github.com/gocql/gocql v1.0.0
 	github.com/gofrs/uuid v4.2.0+incompatible
 	github.com/golang/glog v1.2.0
 	github.com/golang/snappy v0.0.4
 	github.com/google/gomemcache v0.0.0-20210709172713-c1c93e4523ee
 	github.com/julienschmidt/httprouter v1.3.0
+	github.com/lib/pq v1.10.9
 	github.com/prebid/prebid-cache v0.0.0-00010101000000-000000000000
 	github.com/prometheus/client_golang v1.12.2
 	github.com/prometheus/client_model v0.2.0
@@ -29,6 +33,8 @@ require (
 )
 
 require (
+	filippo.io/edwards25519 v1.1.0 // indirect
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
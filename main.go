@@ -1,8 +1,11 @@
 package main
 
 import (
+	"flag"
 	_ "net/http/pprof"
 
+	"git.pubmatic.com/PubMatic/go-common/logger"
+	"github.com/prebid/prebid-cache/backends"
 	backendConfig "github.com/prebid/prebid-cache/backends/config"
 	"github.com/prebid/prebid-cache/config"
 	"github.com/prebid/prebid-cache/endpoints/routing"
@@ -12,21 +15,69 @@ import (
 
 const configFileName = "config"
 
+// schemaCommand creates the Cassandra keyspace and table, or the SQL table, and exits instead of
+// serving requests
+const schemaCommand = "schema"
+
 func main() {
+	createSchema := flag.Bool("create-schema", false, "create the Cassandra keyspace and table, or the SQL table, if missing, before serving requests")
+	flag.Parse()
 
 	//log.SetOutput(os.Stdout)
 	cfg := config.NewConfig(configFileName)
 	//setLogLevel(cfg.Log.Level)
 	cfg.ValidateAndLog()
 
+	switch flag.Arg(0) {
+	case "":
+	case schemaCommand:
+		runCreateSchema(cfg)
+		return
+	default:
+		logger.Fatal("Unknown command %q. The only supported command is %q", flag.Arg(0), schemaCommand)
+	}
+
+	if *createSchema {
+		runCreateSchema(cfg)
+	}
+
 	appMetrics := metrics.CreateMetrics(cfg)
 	backend := backendConfig.NewBackend(cfg, appMetrics)
-	publicHandler := routing.NewPublicHandler(cfg, backend, appMetrics)
-	adminHandler := routing.NewAdminHandler(cfg, backend, appMetrics)
+	var health *backends.HealthMonitor
+	if cfg.HealthCheck.Enabled {
+		health = backends.NewHealthMonitor(backend, string(cfg.Backend.Type), cfg.HealthCheck)
+	}
+	publicHandler := routing.NewPublicHandler(cfg, backend, health, appMetrics)
+	adminHandler := routing.NewAdminHandler(cfg, backend, health, appMetrics)
 	go appMetrics.Export(cfg)
 	server.Listen(cfg, publicHandler, adminHandler, appMetrics)
 }
 
+// runCreateSchema creates the schema of the configured backends. Only Cassandra and SQL need one
+func runCreateSchema(cfg config.Configuration) {
+	created := false
+	for _, backend := range cfg.Backend.StorageBackends() {
+		switch backend.Type {
+		case config.BackendCassandra:
+			if err := backends.CreateCassandraSchema(backend.Cassandra); err != nil {
+				logger.Fatal("Error creating the Cassandra schema: %v", err)
+			}
+			logger.Info("Cassandra keyspace %s and table %s.cache are in place", backend.Cassandra.Keyspace, backend.Cassandra.Keyspace)
+			created = true
+		case config.BackendSQL:
+			if err := backends.CreateSQLSchema(backend.SQL); err != nil {
+				logger.Fatal("Error creating the SQL schema: %v", err)
+			}
+			logger.Info("SQL table %s is in place", backend.SQL.Table)
+			created = true
+		}
+	}
+
+	if !created {
+		logger.Fatal("Creating a schema requires a %s or %s backend, not %v", config.BackendCassandra, config.BackendSQL, cfg.Backend.StorageTypes())
+	}
+}
+
 /*func setLogLevel(logLevel config.LogLevel) {
 	level, err := log.ParseLevel(string(logLevel))
 	if err != nil {
@@ -68,6 +68,30 @@ func (m Metrics) RecordGetDuration(duration time.Duration) {
 	}
 }
 
+func (m Metrics) RecordDeleteError() {
+	for _, me := range m.MetricEngines {
+		me.RecordDeleteError()
+	}
+}
+
+func (m Metrics) RecordDeleteBadRequest() {
+	for _, me := range m.MetricEngines {
+		me.RecordDeleteBadRequest()
+	}
+}
+
+func (m Metrics) RecordDeleteTotal() {
+	for _, me := range m.MetricEngines {
+		me.RecordDeleteTotal()
+	}
+}
+
+func (m Metrics) RecordDeleteDuration(duration time.Duration) {
+	for _, me := range m.MetricEngines {
+		me.RecordDeleteDuration(duration)
+	}
+}
+
 func (m Metrics) RecordPutBackendXml() {
 	for _, me := range m.MetricEngines {
 		me.RecordPutBackendXml()
@@ -110,6 +134,18 @@ func (m Metrics) RecordPutBackendSize(sizeInBytes float64) {
 	}
 }
 
+func (m Metrics) RecordPutBackendBatchSize(size int) {
+	for _, me := range m.MetricEngines {
+		me.RecordPutBackendBatchSize(size)
+	}
+}
+
+func (m Metrics) RecordGetBackendBatchSize(size int) {
+	for _, me := range m.MetricEngines {
+		me.RecordGetBackendBatchSize(size)
+	}
+}
+
 func (m Metrics) RecordGetBackendDuration(duration time.Duration) {
 	for _, me := range m.MetricEngines {
 		me.RecordGetBackendDuration(duration)
@@ -128,6 +164,102 @@ func (m Metrics) RecordGetBackendError() {
 	}
 }
 
+func (m Metrics) RecordDeleteBackendDuration(duration time.Duration) {
+	for _, me := range m.MetricEngines {
+		me.RecordDeleteBackendDuration(duration)
+	}
+}
+
+func (m Metrics) RecordDeleteBackendTotal() {
+	for _, me := range m.MetricEngines {
+		me.RecordDeleteBackendTotal()
+	}
+}
+
+func (m Metrics) RecordDeleteBackendError() {
+	for _, me := range m.MetricEngines {
+		me.RecordDeleteBackendError()
+	}
+}
+
+func (m Metrics) RecordCassandraWriteDuration(mode config.CassandraWriteMode, duration time.Duration) {
+	for _, me := range m.MetricEngines {
+		me.RecordCassandraWriteDuration(mode, duration)
+	}
+}
+
+func (m Metrics) RecordMemcacheServerError(server string) {
+	for _, me := range m.MetricEngines {
+		me.RecordMemcacheServerError(server)
+	}
+}
+
+func (m Metrics) RecordTieredHit(tier config.CacheTier) {
+	for _, me := range m.MetricEngines {
+		me.RecordTieredHit(tier)
+	}
+}
+
+func (m Metrics) RecordTieredMiss() {
+	for _, me := range m.MetricEngines {
+		me.RecordTieredMiss()
+	}
+}
+
+func (m Metrics) RecordMigrationHit(side config.MigrationSideName) {
+	for _, me := range m.MetricEngines {
+		me.RecordMigrationHit(side)
+	}
+}
+
+func (m Metrics) RecordMigrationError(side config.MigrationSideName) {
+	for _, me := range m.MetricEngines {
+		me.RecordMigrationError(side)
+	}
+}
+
+func (m Metrics) RecordShardRequest(shard string) {
+	for _, me := range m.MetricEngines {
+		me.RecordShardRequest(shard)
+	}
+}
+
+func (m Metrics) RecordShardError(shard string) {
+	for _, me := range m.MetricEngines {
+		me.RecordShardError(shard)
+	}
+}
+
+func (m Metrics) RecordCircuitBreakerTransition(operation string, state config.CircuitState) {
+	for _, me := range m.MetricEngines {
+		me.RecordCircuitBreakerTransition(operation, state)
+	}
+}
+
+func (m Metrics) RecordBackendRetry(operation string) {
+	for _, me := range m.MetricEngines {
+		me.RecordBackendRetry(operation)
+	}
+}
+
+func (m Metrics) RecordHedgedGet() {
+	for _, me := range m.MetricEngines {
+		me.RecordHedgedGet()
+	}
+}
+
+func (m Metrics) RecordCoalescedGet() {
+	for _, me := range m.MetricEngines {
+		me.RecordCoalescedGet()
+	}
+}
+
+func (m Metrics) RecordNegativeCacheHit(source config.NegativeCacheSource) {
+	for _, me := range m.MetricEngines {
+		me.RecordNegativeCacheHit(source)
+	}
+}
+
 func (m Metrics) RecordKeyNotFoundError() {
 	for _, me := range m.MetricEngines {
 		me.RecordKeyNotFoundError()
@@ -195,6 +327,10 @@ type CacheMetrics interface {
 	RecordGetBadRequest()
 	RecordGetTotal()
 	RecordGetDuration(duration time.Duration)
+	RecordDeleteError()
+	RecordDeleteBadRequest()
+	RecordDeleteTotal()
+	RecordDeleteDuration(duration time.Duration)
 	RecordPutBackendXml()
 	RecordPutBackendJson()
 	RecordPutBackendInvalid()
@@ -202,9 +338,27 @@ type CacheMetrics interface {
 	RecordPutBackendTTLSeconds(duration time.Duration)
 	RecordPutBackendError()
 	RecordPutBackendSize(sizeInBytes float64)
+	RecordPutBackendBatchSize(size int)
 	RecordGetBackendTotal()
 	RecordGetBackendDuration(duration time.Duration)
 	RecordGetBackendError()
+	RecordGetBackendBatchSize(size int)
+	RecordDeleteBackendTotal()
+	RecordDeleteBackendDuration(duration time.Duration)
+	RecordDeleteBackendError()
+	RecordCassandraWriteDuration(mode config.CassandraWriteMode, duration time.Duration)
+	RecordMemcacheServerError(server string)
+	RecordTieredHit(tier config.CacheTier)
+	RecordTieredMiss()
+	RecordMigrationHit(side config.MigrationSideName)
+	RecordMigrationError(side config.MigrationSideName)
+	RecordShardRequest(shard string)
+	RecordShardError(shard string)
+	RecordCircuitBreakerTransition(operation string, state config.CircuitState)
+	RecordBackendRetry(operation string)
+	RecordHedgedGet()
+	RecordCoalescedGet()
+	RecordNegativeCacheHit(source config.NegativeCacheSource)
 	RecordKeyNotFoundError()
 	RecordMissingKeyError()
 	RecordConnectionOpen()
@@ -2,6 +2,7 @@ package metrics
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"git.pubmatic.com/PubMatic/go-common/logger"
@@ -14,6 +15,9 @@ const TenSeconds time.Duration = time.Second * 10
 
 const MetricsInfluxDB = "InfluxDB"
 
+// influxMetricNameReplacer keeps server addresses from adding levels to metric names
+var influxMetricNameReplacer = strings.NewReplacer(".", "_", ":", "_")
+
 type InfluxMetrics struct {
 	Registry    metrics.Registry
 	Puts        *InfluxMetricsEntry
@@ -23,6 +27,16 @@ type InfluxMetrics struct {
 	GetsErr     *InfluxMetricsGetErrors
 	Connections *InfluxConnectionMetrics
 	MetricsName string
+
+	Deletes        *InfluxMetricsEntry
+	DeletesBackend *InfluxMetricsEntry
+
+	CassandraWrites *InfluxCassandraWriteMetrics
+	Tiered          *InfluxTieredMetrics
+	Migration       *InfluxMigrationMetrics
+	Retries         *InfluxRetryMetrics
+	CoalescedGets   metrics.Meter
+	NegativeCache   *InfluxNegativeCacheMetrics
 }
 
 type InfluxMetricsEntry struct {
@@ -31,6 +45,7 @@ type InfluxMetricsEntry struct {
 	BadRequest metrics.Meter
 	Request    metrics.Meter
 	Update     metrics.Meter
+	BatchSize  metrics.Histogram
 }
 
 type InfluxMetricsEntryByFormat struct {
@@ -43,6 +58,7 @@ type InfluxMetricsEntryByFormat struct {
 	InvalidRequest metrics.Meter
 	RequestLength  metrics.Histogram
 	RequestTTL     metrics.Timer
+	BatchSize      metrics.Histogram
 }
 
 type InfluxConnectionMetrics struct {
@@ -51,6 +67,85 @@ type InfluxConnectionMetrics struct {
 	ConnectionAcceptErrors metrics.Meter
 }
 
+// InfluxCassandraWriteMetrics times Cassandra writes separately for each write mode so that
+// lightweight transactions and plain inserts can be compared
+type InfluxCassandraWriteMetrics struct {
+	LWTDuration   metrics.Timer
+	PlainDuration metrics.Timer
+}
+
+func NewInfluxCassandraWriteMetrics(name string, r metrics.Registry) *InfluxCassandraWriteMetrics {
+	return &InfluxCassandraWriteMetrics{
+		LWTDuration:   metrics.GetOrRegisterTimer(fmt.Sprintf("%s.lwt.request_duration", name), r),
+		PlainDuration: metrics.GetOrRegisterTimer(fmt.Sprintf("%s.plain.request_duration", name), r),
+	}
+}
+
+// InfluxTieredMetrics counts where the tiered backend found the values it was asked for
+type InfluxTieredMetrics struct {
+	L1Hits metrics.Meter
+	L2Hits metrics.Meter
+	Misses metrics.Meter
+}
+
+func NewInfluxTieredMetrics(name string, r metrics.Registry) *InfluxTieredMetrics {
+	return &InfluxTieredMetrics{
+		L1Hits: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.l1.hit_count", name), r),
+		L2Hits: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.l2.hit_count", name), r),
+		Misses: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.miss_count", name), r),
+	}
+}
+
+// InfluxMigrationMetrics counts the values each backend of a migration served and the errors
+// each of them returned
+type InfluxMigrationMetrics struct {
+	PrimaryHits     metrics.Meter
+	PrimaryErrors   metrics.Meter
+	SecondaryHits   metrics.Meter
+	SecondaryErrors metrics.Meter
+}
+
+func NewInfluxMigrationMetrics(name string, r metrics.Registry) *InfluxMigrationMetrics {
+	return &InfluxMigrationMetrics{
+		PrimaryHits:     metrics.GetOrRegisterMeter(fmt.Sprintf("%s.primary.hit_count", name), r),
+		PrimaryErrors:   metrics.GetOrRegisterMeter(fmt.Sprintf("%s.primary.error_count", name), r),
+		SecondaryHits:   metrics.GetOrRegisterMeter(fmt.Sprintf("%s.secondary.hit_count", name), r),
+		SecondaryErrors: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.secondary.error_count", name), r),
+	}
+}
+
+// InfluxRetryMetrics counts the backend requests retried after transient errors and the gets
+// sent again because the first one was taking longer than usual
+type InfluxRetryMetrics struct {
+	GetRetries    metrics.Meter
+	PutRetries    metrics.Meter
+	DeleteRetries metrics.Meter
+	HedgedGets    metrics.Meter
+}
+
+func NewInfluxRetryMetrics(name string, r metrics.Registry) *InfluxRetryMetrics {
+	return &InfluxRetryMetrics{
+		GetRetries:    metrics.GetOrRegisterMeter(fmt.Sprintf("%s.get_count", name), r),
+		PutRetries:    metrics.GetOrRegisterMeter(fmt.Sprintf("%s.put_count", name), r),
+		DeleteRetries: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.delete_count", name), r),
+		HedgedGets:    metrics.GetOrRegisterMeter(fmt.Sprintf("%s.hedged_get_count", name), r),
+	}
+}
+
+// InfluxNegativeCacheMetrics counts the gets answered as missing without reaching the backend
+// by what told the key was missing
+type InfluxNegativeCacheMetrics struct {
+	EntryHits       metrics.Meter
+	BloomFilterHits metrics.Meter
+}
+
+func NewInfluxNegativeCacheMetrics(name string, r metrics.Registry) *InfluxNegativeCacheMetrics {
+	return &InfluxNegativeCacheMetrics{
+		EntryHits:       metrics.GetOrRegisterMeter(fmt.Sprintf("%s.entry.hit_count", name), r),
+		BloomFilterHits: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.bloom_filter.hit_count", name), r),
+	}
+}
+
 type InfluxMetricsGetErrors struct {
 	KeyNotFoundErrors metrics.Meter
 	MissingKeyErrors  metrics.Meter
@@ -96,9 +191,18 @@ func NewInfluxMetricsEntryBackendPuts(name string, r metrics.Registry) *InfluxMe
 		InvalidRequest: metrics.GetOrRegisterMeter(fmt.Sprintf("%s.unknown_request_count", name), r),
 		RequestLength:  metrics.GetOrRegisterHistogram(name+".request_size_bytes", r, metrics.NewExpDecaySample(1028, 0.015)),
 		RequestTTL:     metrics.GetOrRegisterTimer(fmt.Sprintf("%s.request_ttl_seconds", name), r),
+		BatchSize:      metrics.GetOrRegisterHistogram(name+".batch_size", r, metrics.NewExpDecaySample(1028, 0.015)),
 	}
 }
 
+// NewInfluxMetricsEntryBackendGets initializes the same metrics NewInfluxMetricsEntryGet does
+// plus BatchSize, which accounts for the number of keys requested in a single batch
+func NewInfluxMetricsEntryBackendGets(name string, r metrics.Registry) *InfluxMetricsEntry {
+	entry := NewInfluxMetricsEntryGet(name, r)
+	entry.BatchSize = metrics.GetOrRegisterHistogram(name+".batch_size", r, metrics.NewExpDecaySample(1028, 0.015))
+	return entry
+}
+
 func NewInfluxConnectionMetrics(r metrics.Registry) *InfluxConnectionMetrics {
 	return &InfluxConnectionMetrics{
 		ActiveConnections:      metrics.GetOrRegisterCounter("connections.active_incoming", r),
@@ -115,10 +219,20 @@ func CreateInfluxMetrics() *InfluxMetrics {
 		Puts:        NewInfluxMetricsEntryEndpointPuts("puts.current_url", r),
 		Gets:        NewInfluxMetricsEntryGet("gets.current_url", r),
 		PutsBackend: NewInfluxMetricsEntryBackendPuts("puts.backend", r),
-		GetsBackend: NewInfluxMetricsEntryGet("gets.backend", r),
+		GetsBackend: NewInfluxMetricsEntryBackendGets("gets.backend", r),
 		GetsErr:     NewInfluxGetErrorMetrics("gets.backend_error", r),
 		Connections: NewInfluxConnectionMetrics(r),
 		MetricsName: MetricsInfluxDB,
+
+		Deletes:        NewInfluxMetricsEntryGet("deletes.current_url", r),
+		DeletesBackend: NewInfluxMetricsEntryGet("deletes.backend", r),
+
+		CassandraWrites: NewInfluxCassandraWriteMetrics("cassandra.writes", r),
+		Tiered:          NewInfluxTieredMetrics("tiered", r),
+		Migration:       NewInfluxMigrationMetrics("migration", r),
+		Retries:         NewInfluxRetryMetrics("retries", r),
+		CoalescedGets:   metrics.GetOrRegisterMeter("gets.backend.coalesced_count", r),
+		NegativeCache:   NewInfluxNegativeCacheMetrics("negative_cache", r),
 	}
 
 	metrics.RegisterDebugGCStats(m.Registry)
@@ -190,6 +304,22 @@ func (m *InfluxMetrics) RecordGetDuration(duration time.Duration) {
 	m.Gets.Duration.Update(duration)
 }
 
+func (m *InfluxMetrics) RecordDeleteError() {
+	m.Deletes.Errors.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordDeleteBadRequest() {
+	m.Deletes.BadRequest.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordDeleteTotal() {
+	m.Deletes.Request.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordDeleteDuration(duration time.Duration) {
+	m.Deletes.Duration.Update(duration)
+}
+
 func (m *InfluxMetrics) RecordPutBackendXml() {
 	m.PutsBackend.XmlRequest.Mark(1)
 }
@@ -222,6 +352,14 @@ func (m *InfluxMetrics) RecordPutBackendSize(sizeInBytes float64) {
 	m.PutsBackend.RequestLength.Update(int64(sizeInBytes))
 }
 
+func (m *InfluxMetrics) RecordPutBackendBatchSize(size int) {
+	m.PutsBackend.BatchSize.Update(int64(size))
+}
+
+func (m *InfluxMetrics) RecordGetBackendBatchSize(size int) {
+	m.GetsBackend.BatchSize.Update(int64(size))
+}
+
 func (m *InfluxMetrics) RecordGetBackendDuration(duration time.Duration) {
 	m.GetsBackend.Duration.Update(duration)
 }
@@ -230,6 +368,112 @@ func (m *InfluxMetrics) RecordGetBackendError() {
 	m.GetsBackend.Errors.Mark(1)
 }
 
+func (m *InfluxMetrics) RecordDeleteBackendTotal() {
+	m.DeletesBackend.Request.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordDeleteBackendDuration(duration time.Duration) {
+	m.DeletesBackend.Duration.Update(duration)
+}
+
+func (m *InfluxMetrics) RecordDeleteBackendError() {
+	m.DeletesBackend.Errors.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordCassandraWriteDuration(mode config.CassandraWriteMode, duration time.Duration) {
+	switch mode {
+	case config.CassandraWriteLWT:
+		m.CassandraWrites.LWTDuration.Update(duration)
+	case config.CassandraWritePlain:
+		m.CassandraWrites.PlainDuration.Update(duration)
+	}
+}
+
+// RecordMemcacheServerError counts errors by memcache node. Nodes come and go when auto
+// discovery is used, so their meters get registered as errors show up
+func (m *InfluxMetrics) RecordMemcacheServerError(server string) {
+	name := fmt.Sprintf("memcache.servers.%s.error_count", influxMetricNameReplacer.Replace(server))
+	metrics.GetOrRegisterMeter(name, m.Registry).Mark(1)
+}
+
+func (m *InfluxMetrics) RecordTieredHit(tier config.CacheTier) {
+	switch tier {
+	case config.CacheTierL1:
+		m.Tiered.L1Hits.Mark(1)
+	case config.CacheTierL2:
+		m.Tiered.L2Hits.Mark(1)
+	}
+}
+
+func (m *InfluxMetrics) RecordTieredMiss() {
+	m.Tiered.Misses.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordMigrationHit(side config.MigrationSideName) {
+	switch side {
+	case config.MigrationPrimary:
+		m.Migration.PrimaryHits.Mark(1)
+	case config.MigrationSecondary:
+		m.Migration.SecondaryHits.Mark(1)
+	}
+}
+
+func (m *InfluxMetrics) RecordMigrationError(side config.MigrationSideName) {
+	switch side {
+	case config.MigrationPrimary:
+		m.Migration.PrimaryErrors.Mark(1)
+	case config.MigrationSecondary:
+		m.Migration.SecondaryErrors.Mark(1)
+	}
+}
+
+// RecordShardRequest counts requests by shard of the sharded backend. Shard names come from
+// the configuration, so their meters get registered as requests show up
+func (m *InfluxMetrics) RecordShardRequest(shard string) {
+	name := fmt.Sprintf("sharded.shards.%s.request_count", influxMetricNameReplacer.Replace(shard))
+	metrics.GetOrRegisterMeter(name, m.Registry).Mark(1)
+}
+
+func (m *InfluxMetrics) RecordShardError(shard string) {
+	name := fmt.Sprintf("sharded.shards.%s.error_count", influxMetricNameReplacer.Replace(shard))
+	metrics.GetOrRegisterMeter(name, m.Registry).Mark(1)
+}
+
+// RecordCircuitBreakerTransition counts the times the circuit of a backend operation entered
+// state
+func (m *InfluxMetrics) RecordCircuitBreakerTransition(operation string, state config.CircuitState) {
+	name := fmt.Sprintf("circuit_breaker.%s.%s_count", operation, state)
+	metrics.GetOrRegisterMeter(name, m.Registry).Mark(1)
+}
+
+func (m *InfluxMetrics) RecordBackendRetry(operation string) {
+	switch operation {
+	case "get":
+		m.Retries.GetRetries.Mark(1)
+	case "put":
+		m.Retries.PutRetries.Mark(1)
+	case "delete":
+		m.Retries.DeleteRetries.Mark(1)
+	}
+}
+
+func (m *InfluxMetrics) RecordHedgedGet() {
+	m.Retries.HedgedGets.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordCoalescedGet() {
+	m.CoalescedGets.Mark(1)
+}
+
+func (m *InfluxMetrics) RecordNegativeCacheHit(source config.NegativeCacheSource) {
+	switch source {
+	case config.NegativeCacheEntry:
+		m.NegativeCache.EntryHits.Mark(1)
+	case config.NegativeCacheBloomFilter:
+		m.NegativeCache.BloomFilterHits.Mark(1)
+	}
+}
+
 func (m *InfluxMetrics) RecordKeyNotFoundError() {
 	m.GetsErr.KeyNotFoundErrors.Mark(1)
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prebid/prebid-cache/config"
 	"github.com/rcrowley/go-metrics"
 	"github.com/stretchr/testify/assert"
 )
@@ -37,12 +38,53 @@ func TestRegisteredInfluxMetrics(t *testing.T) {
 		{"puts.backend.unknown_request_count", "Meter"},
 		{"puts.backend.request_size_bytes", "Histogram"},
 		{"puts.backend.request_ttl_seconds", "Timer"},
+		{"puts.backend.batch_size", "Histogram"},
 
 		// Gets Backend:
 		{"gets.backend.request_duration", "Timer"},
 		{"gets.backend.error_count", "Meter"},
 		{"gets.backend.bad_request_count", "Meter"},
 		{"gets.backend.request_count", "Meter"},
+		{"gets.backend.batch_size", "Histogram"},
+
+		// Deletes:
+		{"deletes.current_url.request_duration", "Timer"},
+		{"deletes.current_url.error_count", "Meter"},
+		{"deletes.current_url.bad_request_count", "Meter"},
+		{"deletes.current_url.request_count", "Meter"},
+
+		// Deletes Backend:
+		{"deletes.backend.request_duration", "Timer"},
+		{"deletes.backend.error_count", "Meter"},
+		{"deletes.backend.request_count", "Meter"},
+
+		// Cassandra writes:
+		{"cassandra.writes.lwt.request_duration", "Timer"},
+		{"cassandra.writes.plain.request_duration", "Timer"},
+
+		// Tiered backend:
+		{"tiered.l1.hit_count", "Meter"},
+		{"tiered.l2.hit_count", "Meter"},
+		{"tiered.miss_count", "Meter"},
+
+		// Migration backend:
+		{"migration.primary.hit_count", "Meter"},
+		{"migration.primary.error_count", "Meter"},
+		{"migration.secondary.hit_count", "Meter"},
+		{"migration.secondary.error_count", "Meter"},
+
+		// Retries:
+		{"retries.get_count", "Meter"},
+		{"retries.put_count", "Meter"},
+		{"retries.delete_count", "Meter"},
+		{"retries.hedged_get_count", "Meter"},
+
+		// Coalesced gets:
+		{"gets.backend.coalesced_count", "Meter"},
+
+		// Negative cache:
+		{"negative_cache.entry.hit_count", "Meter"},
+		{"negative_cache.bloom_filter.hit_count", "Meter"},
 
 		// Gets Backend Errors:
 		{"gets.backend_error.key_not_found", "Meter"},
@@ -218,6 +260,116 @@ func TestAllRecorders(t *testing.T) {
 				},
 			},
 		},
+		{
+			"m.CassandraWrites",
+			[]testCase{
+				{
+					description:    "Five second RecordCassandraWriteDuration of a lightweight transaction",
+					runTest:        func(im *InfluxMetrics) { im.RecordCassandraWriteDuration(config.CassandraWriteLWT, fiveSeconds) },
+					metricToAssert: m.CassandraWrites.LWTDuration,
+				},
+				{
+					description:    "Five second RecordCassandraWriteDuration of a plain insert",
+					runTest:        func(im *InfluxMetrics) { im.RecordCassandraWriteDuration(config.CassandraWritePlain, fiveSeconds) },
+					metricToAssert: m.CassandraWrites.PlainDuration,
+				},
+			},
+		},
+		{
+			"m.Tiered",
+			[]testCase{
+				{
+					description:    "Record a value found in the local tier",
+					runTest:        func(im *InfluxMetrics) { im.RecordTieredHit(config.CacheTierL1) },
+					metricToAssert: m.Tiered.L1Hits,
+				},
+				{
+					description:    "Record a value found in the remote tier",
+					runTest:        func(im *InfluxMetrics) { im.RecordTieredHit(config.CacheTierL2) },
+					metricToAssert: m.Tiered.L2Hits,
+				},
+				{
+					description:    "Record a value found in neither tier",
+					runTest:        func(im *InfluxMetrics) { im.RecordTieredMiss() },
+					metricToAssert: m.Tiered.Misses,
+				},
+			},
+		},
+		{
+			"m.Migration",
+			[]testCase{
+				{
+					description:    "Record a value served by the primary backend",
+					runTest:        func(im *InfluxMetrics) { im.RecordMigrationHit(config.MigrationPrimary) },
+					metricToAssert: m.Migration.PrimaryHits,
+				},
+				{
+					description:    "Record an error returned by the primary backend",
+					runTest:        func(im *InfluxMetrics) { im.RecordMigrationError(config.MigrationPrimary) },
+					metricToAssert: m.Migration.PrimaryErrors,
+				},
+				{
+					description:    "Record a value served by the secondary backend",
+					runTest:        func(im *InfluxMetrics) { im.RecordMigrationHit(config.MigrationSecondary) },
+					metricToAssert: m.Migration.SecondaryHits,
+				},
+				{
+					description:    "Record an error returned by the secondary backend",
+					runTest:        func(im *InfluxMetrics) { im.RecordMigrationError(config.MigrationSecondary) },
+					metricToAssert: m.Migration.SecondaryErrors,
+				},
+			},
+		},
+		{
+			"m.Retries",
+			[]testCase{
+				{
+					description:    "Record a retried get",
+					runTest:        func(im *InfluxMetrics) { im.RecordBackendRetry("get") },
+					metricToAssert: m.Retries.GetRetries,
+				},
+				{
+					description:    "Record a retried put",
+					runTest:        func(im *InfluxMetrics) { im.RecordBackendRetry("put") },
+					metricToAssert: m.Retries.PutRetries,
+				},
+				{
+					description:    "Record a retried delete",
+					runTest:        func(im *InfluxMetrics) { im.RecordBackendRetry("delete") },
+					metricToAssert: m.Retries.DeleteRetries,
+				},
+				{
+					description:    "Record a hedged get",
+					runTest:        func(im *InfluxMetrics) { im.RecordHedgedGet() },
+					metricToAssert: m.Retries.HedgedGets,
+				},
+			},
+		},
+		{
+			"m.CoalescedGets",
+			[]testCase{
+				{
+					description:    "Record a get served by a get already in flight",
+					runTest:        func(im *InfluxMetrics) { im.RecordCoalescedGet() },
+					metricToAssert: m.CoalescedGets,
+				},
+			},
+		},
+		{
+			"m.NegativeCache",
+			[]testCase{
+				{
+					description:    "Record a get answered by a cached miss",
+					runTest:        func(im *InfluxMetrics) { im.RecordNegativeCacheHit(config.NegativeCacheEntry) },
+					metricToAssert: m.NegativeCache.EntryHits,
+				},
+				{
+					description:    "Record a get of a key missing from the bloom filter",
+					runTest:        func(im *InfluxMetrics) { im.RecordNegativeCacheHit(config.NegativeCacheBloomFilter) },
+					metricToAssert: m.NegativeCache.BloomFilterHits,
+				},
+			},
+		},
 		{
 			"m.Connections",
 			[]testCase{
@@ -263,3 +415,38 @@ func TestAllRecorders(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordMemcacheServerError(t *testing.T) {
+	m := CreateInfluxMetrics()
+
+	m.RecordMemcacheServerError("10.0.0.1:11211")
+	m.RecordMemcacheServerError("10.0.0.1:11211")
+	m.RecordMemcacheServerError("10.0.0.2:11211")
+
+	assert.Equal(t, int64(2), metrics.GetOrRegisterMeter("memcache.servers.10_0_0_1_11211.error_count", m.Registry).Count(), "First server errors")
+	assert.Equal(t, int64(1), metrics.GetOrRegisterMeter("memcache.servers.10_0_0_2_11211.error_count", m.Registry).Count(), "Second server errors")
+}
+
+func TestRecordShardMetrics(t *testing.T) {
+	m := CreateInfluxMetrics()
+
+	m.RecordShardRequest("redis-a")
+	m.RecordShardRequest("redis-a")
+	m.RecordShardRequest("cache.eu:1")
+	m.RecordShardError("cache.eu:1")
+
+	assert.Equal(t, int64(2), metrics.GetOrRegisterMeter("sharded.shards.redis-a.request_count", m.Registry).Count(), "First shard requests")
+	assert.Equal(t, int64(1), metrics.GetOrRegisterMeter("sharded.shards.cache_eu_1.request_count", m.Registry).Count(), "Second shard requests")
+	assert.Equal(t, int64(1), metrics.GetOrRegisterMeter("sharded.shards.cache_eu_1.error_count", m.Registry).Count(), "Second shard errors")
+}
+
+func TestRecordCircuitBreakerTransition(t *testing.T) {
+	m := CreateInfluxMetrics()
+
+	m.RecordCircuitBreakerTransition("put", config.CircuitOpen)
+	m.RecordCircuitBreakerTransition("put", config.CircuitHalfOpen)
+	m.RecordCircuitBreakerTransition("put", config.CircuitOpen)
+
+	assert.Equal(t, int64(2), metrics.GetOrRegisterMeter("circuit_breaker.put.open_count", m.Registry).Count(), "Put circuit opened")
+	assert.Equal(t, int64(1), metrics.GetOrRegisterMeter("circuit_breaker.put.half_open_count", m.Registry).Count(), "Put circuit half-opened")
+}
@@ -50,6 +50,27 @@ func CreateMockMetrics() MockMetrics {
 	mockMetrics.On("RecordCloseConnectionErrors")
 	mockMetrics.On("RecordConnectionClosed")
 	mockMetrics.On("RecordConnectionOpen")
+	mockMetrics.On("RecordDeleteBackendDuration", mock.Anything)
+	mockMetrics.On("RecordCassandraWriteDuration", mock.Anything, mock.Anything)
+	mockMetrics.On("RecordMemcacheServerError", mock.Anything)
+	mockMetrics.On("RecordTieredHit", mock.Anything)
+	mockMetrics.On("RecordTieredMiss")
+	mockMetrics.On("RecordMigrationHit", mock.Anything)
+	mockMetrics.On("RecordMigrationError", mock.Anything)
+	mockMetrics.On("RecordShardRequest", mock.Anything)
+	mockMetrics.On("RecordShardError", mock.Anything)
+	mockMetrics.On("RecordCircuitBreakerTransition", mock.Anything, mock.Anything)
+	mockMetrics.On("RecordBackendRetry", mock.Anything)
+	mockMetrics.On("RecordHedgedGet")
+	mockMetrics.On("RecordCoalescedGet")
+	mockMetrics.On("RecordNegativeCacheHit", mock.Anything)
+	mockMetrics.On("RecordDeleteBackendError")
+	mockMetrics.On("RecordDeleteBackendTotal")
+	mockMetrics.On("RecordDeleteBadRequest")
+	mockMetrics.On("RecordDeleteDuration", mock.Anything)
+	mockMetrics.On("RecordDeleteError")
+	mockMetrics.On("RecordDeleteTotal")
+	mockMetrics.On("RecordGetBackendBatchSize", mock.Anything)
 	mockMetrics.On("RecordGetBackendDuration", mock.Anything)
 	mockMetrics.On("RecordGetBackendError")
 	mockMetrics.On("RecordGetBackendTotal")
@@ -59,6 +80,7 @@ func CreateMockMetrics() MockMetrics {
 	mockMetrics.On("RecordGetTotal")
 	mockMetrics.On("RecordKeyNotFoundError")
 	mockMetrics.On("RecordMissingKeyError")
+	mockMetrics.On("RecordPutBackendBatchSize", mock.Anything)
 	mockMetrics.On("RecordPutBackendDuration", mock.Anything)
 	mockMetrics.On("RecordPutBackendError")
 	mockMetrics.On("RecordPutBackendInvalid")
@@ -122,6 +144,22 @@ func (m *MockMetrics) RecordGetDuration(duration time.Duration) {
 	m.Called()
 	return
 }
+func (m *MockMetrics) RecordDeleteError() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordDeleteBadRequest() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordDeleteTotal() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordDeleteDuration(duration time.Duration) {
+	m.Called()
+	return
+}
 func (m *MockMetrics) RecordPutBackendXml() {
 	m.Called()
 	return
@@ -146,6 +184,14 @@ func (m *MockMetrics) RecordPutBackendSize(sizeInBytes float64) {
 	m.Called()
 	return
 }
+func (m *MockMetrics) RecordPutBackendBatchSize(size int) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordGetBackendBatchSize(size int) {
+	m.Called()
+	return
+}
 func (m *MockMetrics) RecordPutBackendTTLSeconds(duration time.Duration) {
 	m.Called()
 	return
@@ -162,6 +208,70 @@ func (m *MockMetrics) RecordGetBackendError() {
 	m.Called()
 	return
 }
+func (m *MockMetrics) RecordDeleteBackendDuration(duration time.Duration) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordDeleteBackendTotal() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordDeleteBackendError() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordCassandraWriteDuration(mode config.CassandraWriteMode, duration time.Duration) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordMemcacheServerError(server string) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordTieredHit(tier config.CacheTier) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordTieredMiss() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordMigrationHit(side config.MigrationSideName) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordMigrationError(side config.MigrationSideName) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordShardRequest(shard string) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordShardError(shard string) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordCircuitBreakerTransition(operation string, state config.CircuitState) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordBackendRetry(operation string) {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordHedgedGet() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordCoalescedGet() {
+	m.Called()
+	return
+}
+func (m *MockMetrics) RecordNegativeCacheHit(source config.NegativeCacheSource) {
+	m.Called()
+	return
+}
 func (m *MockMetrics) RecordKeyNotFoundError() {
 	m.Called()
 	return
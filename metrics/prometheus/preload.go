@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"github.com/prebid/prebid-cache/config"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -10,7 +11,13 @@ func preloadLabelValues(m *PrometheusMetrics) {
 	preloadLabelValuesForCounter(m.PutsBackend.PutBackendRequests, map[string][]string{FormatKey: {XmlVal, JsonVal, InvFormatVal, ErrorVal}})
 	preloadLabelValuesForCounter(m.GetsBackend.RequestStatus, map[string][]string{StatusKey: {ErrorVal, BadRequestVal, TotalsVal}})
 	preloadLabelValuesForCounter(m.GetsBackend.ErrorsByType, map[string][]string{TypeKey: {KeyNotFoundVal, MissingKeyVal}})
+	preloadLabelValuesForCounter(m.Deletes.RequestStatus, map[string][]string{StatusKey: {ErrorVal, BadRequestVal, TotalsVal}})
+	preloadLabelValuesForCounter(m.DeletesBackend.RequestStatus, map[string][]string{StatusKey: {ErrorVal, TotalsVal}})
 	preloadLabelValuesForCounter(m.Connections.ConnectionsErrors, map[string][]string{ConnErrorKey: {CloseVal, AcceptVal}})
+	preloadLabelValuesForHistogram(m.CassandraWrites.Duration, map[string][]string{ModeKey: {string(config.CassandraWriteLWT), string(config.CassandraWritePlain)}})
+	preloadLabelValuesForCounter(m.Tiered.Hits, map[string][]string{TierKey: {string(config.CacheTierL1), string(config.CacheTierL2)}})
+	preloadLabelValuesForCounter(m.Migration.Hits, map[string][]string{SideKey: {string(config.MigrationPrimary), string(config.MigrationSecondary)}})
+	preloadLabelValuesForCounter(m.Migration.Errors, map[string][]string{SideKey: {string(config.MigrationPrimary), string(config.MigrationSecondary)}})
 }
 
 func preloadLabelValuesForCounter(counter *prometheus.CounterVec, labelsWithValues map[string][]string) {
@@ -19,6 +26,12 @@ func preloadLabelValuesForCounter(counter *prometheus.CounterVec, labelsWithValu
 	})
 }
 
+func preloadLabelValuesForHistogram(histogram *prometheus.HistogramVec, labelsWithValues map[string][]string) {
+	registerLabelPermutations(labelsWithValues, func(labels prometheus.Labels) {
+		histogram.With(labels)
+	})
+}
+
 func registerLabelPermutations(labelsWithValues map[string][]string, register func(prometheus.Labels)) {
 	if len(labelsWithValues) == 0 {
 		return
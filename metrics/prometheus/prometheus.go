@@ -14,6 +14,14 @@ const (
 	FormatKey    string = "format"
 	ConnErrorKey string = "connection_error"
 	TypeKey      string = "type"
+	ModeKey      string = "mode"
+	ServerKey    string = "server"
+	TierKey      string = "tier"
+	SideKey      string = "side"
+	ShardKey     string = "shard"
+	OperationKey string = "operation"
+	StateKey     string = "state"
+	SourceKey    string = "source"
 
 	// Label values
 	TotalsVal      string = "total"
@@ -37,9 +45,28 @@ const (
 	PutBackDurMet  string = "puts_backend_duration"
 	PutBackSizeMet string = "puts_backend_request_size_bytes"
 	PutTTLSeconds  string = "puts_backend_request_ttl"
+	PutBatchSize   string = "puts_backend_batch_size"
 	GetBackendMet  string = "gets_backend"
 	GetBackendErr  string = "gets_backend_error"
 	GetBackDurMet  string = "gets_backend_duration"
+	GetBatchSize   string = "gets_backend_batch_size"
+	DelRequestMet  string = "deletes_request"
+	DelReqDurMet   string = "deletes_request_duration"
+	DelBackendMet  string = "deletes_backend"
+	DelBackDurMet  string = "deletes_backend_duration"
+	CassWriteDur   string = "cassandra_write_duration"
+	MemcacheSrvErr string = "memcache_server_errors"
+	TieredHitsMet  string = "tiered_hits"
+	TieredMissMet  string = "tiered_misses"
+	MigrationHits  string = "migration_hits"
+	MigrationErrs  string = "migration_errors"
+	ShardRequests  string = "shard_requests"
+	ShardErrors    string = "shard_errors"
+	CircuitTrans   string = "circuit_breaker_transitions"
+	BackendRetries string = "backend_retries"
+	HedgedGets     string = "gets_backend_hedged"
+	CoalescedGets  string = "gets_backend_coalesced"
+	NegativeHits   string = "negative_cache_hits"
 	ConnOpenedMet  string = "connection_opened"
 	ConnClosedMet  string = "connection_closed"
 
@@ -54,12 +81,26 @@ type PrometheusMetrics struct {
 	GetsBackend *PrometheusRequestStatusMetric
 	Connections *PrometheusConnectionMetrics
 	MetricsName string
+
+	Deletes        *PrometheusRequestStatusMetric
+	DeletesBackend *PrometheusRequestStatusMetric
+
+	CassandraWrites *PrometheusCassandraWriteMetrics
+	MemcacheServers *PrometheusMemcacheServerMetrics
+	Tiered          *PrometheusTieredMetrics
+	Migration       *PrometheusMigrationMetrics
+	Shards          *PrometheusShardMetrics
+	CircuitBreaker  *PrometheusCircuitBreakerMetrics
+	Retries         *PrometheusRetryMetrics
+	CoalescedGets   prometheus.Counter
+	NegativeCache   *PrometheusNegativeCacheMetrics
 }
 
 type PrometheusRequestStatusMetric struct {
 	Duration      prometheus.Histogram
 	RequestStatus *prometheus.CounterVec
 	ErrorsByType  *prometheus.CounterVec
+	BatchSize     prometheus.Histogram
 }
 
 type PrometheusRequestStatusMetricByFormat struct {
@@ -67,6 +108,43 @@ type PrometheusRequestStatusMetricByFormat struct {
 	PutBackendRequests *prometheus.CounterVec
 	RequestLength      prometheus.Histogram
 	RequestTTLDuration prometheus.Histogram
+	BatchSize          prometheus.Histogram
+}
+
+type PrometheusCassandraWriteMetrics struct {
+	Duration *prometheus.HistogramVec
+}
+
+type PrometheusMemcacheServerMetrics struct {
+	Errors *prometheus.CounterVec
+}
+
+type PrometheusTieredMetrics struct {
+	Hits   *prometheus.CounterVec
+	Misses prometheus.Counter
+}
+
+type PrometheusMigrationMetrics struct {
+	Hits   *prometheus.CounterVec
+	Errors *prometheus.CounterVec
+}
+
+type PrometheusShardMetrics struct {
+	Requests *prometheus.CounterVec
+	Errors   *prometheus.CounterVec
+}
+
+type PrometheusCircuitBreakerMetrics struct {
+	Transitions *prometheus.CounterVec
+}
+
+type PrometheusRetryMetrics struct {
+	Retries    *prometheus.CounterVec
+	HedgedGets prometheus.Counter
+}
+
+type PrometheusNegativeCacheMetrics struct {
+	Hits *prometheus.CounterVec
 }
 
 type PrometheusConnectionMetrics struct {
@@ -80,6 +158,7 @@ func CreatePrometheusMetrics(cfg config.PrometheusMetrics) *PrometheusMetrics {
 	// TTL seconds buckets for 1 second, half a minute as well as one, ten, fifteen, thirty minutes and 1, 2, and 3 and 10 hours
 	ttlBuckets := []float64{0.001, 1, 30, 60, 600, 900, 1800, 3600, 7200, 10800, 36000}
 	requestSizeBuckets := []float64{0, 4096, 8192, 16384, 32768, 65536, 131072, 262144, 524288, 1048576}
+	batchSizeBuckets := []float64{1, 2, 5, 10, 20, 50, 100}
 	registry := prometheus.NewRegistry()
 	promMetrics := &PrometheusMetrics{
 		Registry: registry,
@@ -128,6 +207,11 @@ func CreatePrometheusMetrics(cfg config.PrometheusMetrics) *PrometheusMetrics {
 				"Time-to-live duration in seconds specified in put request body's ttl_seconds field",
 				ttlBuckets,
 			),
+			BatchSize: newHistogram(cfg, registry,
+				PutBatchSize,
+				"Number of elements sent to the backend in a single batch put request.",
+				batchSizeBuckets,
+			),
 		},
 		GetsBackend: &PrometheusRequestStatusMetric{
 			Duration: newHistogram(cfg, registry,
@@ -145,6 +229,11 @@ func CreatePrometheusMetrics(cfg config.PrometheusMetrics) *PrometheusMetrics {
 				"Account for the most frequent type of get errors in the backend",
 				[]string{TypeKey},
 			),
+			BatchSize: newHistogram(cfg, registry,
+				GetBatchSize,
+				"Number of keys requested from the backend in a single batch get request.",
+				batchSizeBuckets,
+			),
 		},
 		Connections: &PrometheusConnectionMetrics{
 			ConnectionsClosed: newSingleCounter(cfg, registry, ConnClosedMet, "Count the number of closed connections"),
@@ -156,6 +245,110 @@ func CreatePrometheusMetrics(cfg config.PrometheusMetrics) *PrometheusMetrics {
 			),
 		},
 		MetricsName: MetricsPrometheus,
+
+		Deletes: &PrometheusRequestStatusMetric{
+			Duration: newHistogram(cfg, registry,
+				DelReqDurMet,
+				"Duration in seconds Prebid Cache takes to process delete requests.",
+				timeBuckets,
+			),
+			RequestStatus: newCounterVecWithLabels(cfg, registry,
+				DelRequestMet,
+				"Count of total delete requests to Prebid Cache labeled by status.",
+				[]string{StatusKey},
+			),
+		},
+		DeletesBackend: &PrometheusRequestStatusMetric{
+			Duration: newHistogram(cfg, registry,
+				DelBackDurMet,
+				"Duration in seconds Prebid Cache takes to process backend delete requests.",
+				timeBuckets,
+			),
+			RequestStatus: newCounterVecWithLabels(cfg, registry,
+				DelBackendMet,
+				"Count of total backend delete requests to Prebid Cache labeled by status.",
+				[]string{StatusKey},
+			),
+		},
+		CassandraWrites: &PrometheusCassandraWriteMetrics{
+			Duration: newHistogramVecWithLabels(cfg, registry,
+				CassWriteDur,
+				"Duration in seconds the Cassandra backend takes to write a value labeled by write mode.",
+				timeBuckets,
+				[]string{ModeKey},
+			),
+		},
+		MemcacheServers: &PrometheusMemcacheServerMetrics{
+			Errors: newCounterVecWithLabels(cfg, registry,
+				MemcacheSrvErr,
+				"Count of errors the Memcache backend got labeled by memcache server.",
+				[]string{ServerKey},
+			),
+		},
+		Tiered: &PrometheusTieredMetrics{
+			Hits: newCounterVecWithLabels(cfg, registry,
+				TieredHitsMet,
+				"Count of values the tiered backend found labeled by tier.",
+				[]string{TierKey},
+			),
+			Misses: newSingleCounter(cfg, registry,
+				TieredMissMet,
+				"Count of values the tiered backend found in neither tier.",
+			),
+		},
+		Migration: &PrometheusMigrationMetrics{
+			Hits: newCounterVecWithLabels(cfg, registry,
+				MigrationHits,
+				"Count of values the migration backend served labeled by the side that had them.",
+				[]string{SideKey},
+			),
+			Errors: newCounterVecWithLabels(cfg, registry,
+				MigrationErrs,
+				"Count of errors the migration backend got labeled by the side that returned them.",
+				[]string{SideKey},
+			),
+		},
+		Shards: &PrometheusShardMetrics{
+			Requests: newCounterVecWithLabels(cfg, registry,
+				ShardRequests,
+				"Count of keys the sharded backend routed labeled by shard.",
+				[]string{ShardKey},
+			),
+			Errors: newCounterVecWithLabels(cfg, registry,
+				ShardErrors,
+				"Count of errors the sharded backend got labeled by shard.",
+				[]string{ShardKey},
+			),
+		},
+		CircuitBreaker: &PrometheusCircuitBreakerMetrics{
+			Transitions: newCounterVecWithLabels(cfg, registry,
+				CircuitTrans,
+				"Count of circuit breaker state changes labeled by backend operation and the state entered.",
+				[]string{OperationKey, StateKey},
+			),
+		},
+		Retries: &PrometheusRetryMetrics{
+			Retries: newCounterVecWithLabels(cfg, registry,
+				BackendRetries,
+				"Count of backend requests retried after a transient error labeled by backend operation.",
+				[]string{OperationKey},
+			),
+			HedgedGets: newSingleCounter(cfg, registry,
+				HedgedGets,
+				"Count of backend gets sent again because the first one was taking longer than usual.",
+			),
+		},
+		CoalescedGets: newSingleCounter(cfg, registry,
+			CoalescedGets,
+			"Count of gets that shared the backend get another request for the same key had in flight.",
+		),
+		NegativeCache: &PrometheusNegativeCacheMetrics{
+			Hits: newCounterVecWithLabels(cfg, registry,
+				NegativeHits,
+				"Count of gets answered as missing without reaching the backend labeled by what told the key was missing.",
+				[]string{SourceKey},
+			),
+		},
 	}
 
 	// Should be the equivalent of the following influx collectors
@@ -214,6 +407,19 @@ func newHistogram(cfg config.PrometheusMetrics, registry *prometheus.Registry, n
 	return histogram
 }
 
+func newHistogramVecWithLabels(cfg config.PrometheusMetrics, registry *prometheus.Registry, name, help string, buckets []float64, labels []string) *prometheus.HistogramVec {
+	opts := prometheus.HistogramOpts{
+		Namespace: cfg.Namespace,
+		Subsystem: cfg.Subsystem,
+		Name:      name,
+		Help:      help,
+		Buckets:   buckets,
+	}
+	histogramVec := prometheus.NewHistogramVec(opts, labels)
+	registry.MustRegister(histogramVec)
+	return histogramVec
+}
+
 func (m PrometheusMetrics) Export(cfg config.Metrics) {
 }
 
@@ -261,6 +467,22 @@ func (m *PrometheusMetrics) RecordGetDuration(duration time.Duration) {
 	m.Gets.Duration.Observe(duration.Seconds())
 }
 
+func (m *PrometheusMetrics) RecordDeleteError() {
+	m.Deletes.RequestStatus.With(prometheus.Labels{StatusKey: ErrorVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordDeleteBadRequest() {
+	m.Deletes.RequestStatus.With(prometheus.Labels{StatusKey: BadRequestVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordDeleteTotal() {
+	m.Deletes.RequestStatus.With(prometheus.Labels{StatusKey: TotalsVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordDeleteDuration(duration time.Duration) {
+	m.Deletes.Duration.Observe(duration.Seconds())
+}
+
 func (m *PrometheusMetrics) RecordPutBackendXml() {
 	m.PutsBackend.PutBackendRequests.With(prometheus.Labels{FormatKey: XmlVal}).Inc()
 }
@@ -289,6 +511,14 @@ func (m *PrometheusMetrics) RecordPutBackendSize(sizeInBytes float64) {
 	m.PutsBackend.RequestLength.Observe(sizeInBytes)
 }
 
+func (m *PrometheusMetrics) RecordPutBackendBatchSize(size int) {
+	m.PutsBackend.BatchSize.Observe(float64(size))
+}
+
+func (m *PrometheusMetrics) RecordGetBackendBatchSize(size int) {
+	m.GetsBackend.BatchSize.Observe(float64(size))
+}
+
 func (m *PrometheusMetrics) RecordGetBackendTotal() {
 	m.GetsBackend.RequestStatus.With(prometheus.Labels{StatusKey: TotalsVal}).Inc()
 }
@@ -305,6 +535,70 @@ func (m *PrometheusMetrics) RecordGetBackendBadRequest() {
 	m.GetsBackend.RequestStatus.With(prometheus.Labels{StatusKey: BadRequestVal}).Inc()
 }
 
+func (m *PrometheusMetrics) RecordDeleteBackendTotal() {
+	m.DeletesBackend.RequestStatus.With(prometheus.Labels{StatusKey: TotalsVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordDeleteBackendDuration(duration time.Duration) {
+	m.DeletesBackend.Duration.Observe(duration.Seconds())
+}
+
+func (m *PrometheusMetrics) RecordDeleteBackendError() {
+	m.DeletesBackend.RequestStatus.With(prometheus.Labels{StatusKey: ErrorVal}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordCassandraWriteDuration(mode config.CassandraWriteMode, duration time.Duration) {
+	m.CassandraWrites.Duration.With(prometheus.Labels{ModeKey: string(mode)}).Observe(duration.Seconds())
+}
+
+func (m *PrometheusMetrics) RecordMemcacheServerError(server string) {
+	m.MemcacheServers.Errors.With(prometheus.Labels{ServerKey: server}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordTieredHit(tier config.CacheTier) {
+	m.Tiered.Hits.With(prometheus.Labels{TierKey: string(tier)}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordTieredMiss() {
+	m.Tiered.Misses.Inc()
+}
+
+func (m *PrometheusMetrics) RecordMigrationHit(side config.MigrationSideName) {
+	m.Migration.Hits.With(prometheus.Labels{SideKey: string(side)}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordMigrationError(side config.MigrationSideName) {
+	m.Migration.Errors.With(prometheus.Labels{SideKey: string(side)}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordShardRequest(shard string) {
+	m.Shards.Requests.With(prometheus.Labels{ShardKey: shard}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordShardError(shard string) {
+	m.Shards.Errors.With(prometheus.Labels{ShardKey: shard}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordCircuitBreakerTransition(operation string, state config.CircuitState) {
+	m.CircuitBreaker.Transitions.With(prometheus.Labels{OperationKey: operation, StateKey: string(state)}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordBackendRetry(operation string) {
+	m.Retries.Retries.With(prometheus.Labels{OperationKey: operation}).Inc()
+}
+
+func (m *PrometheusMetrics) RecordHedgedGet() {
+	m.Retries.HedgedGets.Inc()
+}
+
+func (m *PrometheusMetrics) RecordCoalescedGet() {
+	m.CoalescedGets.Inc()
+}
+
+func (m *PrometheusMetrics) RecordNegativeCacheHit(source config.NegativeCacheSource) {
+	m.NegativeCache.Hits.With(prometheus.Labels{SourceKey: string(source)}).Inc()
+}
+
 func (m *PrometheusMetrics) RecordKeyNotFoundError() {
 	m.GetsBackend.ErrorsByType.With(prometheus.Labels{TypeKey: KeyNotFoundVal}).Inc()
 }
@@ -171,6 +171,56 @@ func TestPrometheusRequestStatusMetric(t *testing.T) {
 				expRequestTotals: 1, expRequestErrors: 1, expBadRequests: 1,
 			},
 		},
+		m.Deletes: {
+			{
+				description: "Log delete request duration",
+				testCase: func(pm *PrometheusMetrics) {
+					pm.RecordDeleteDuration(TenSeconds)
+				},
+				expDuration:      10,
+				expRequestTotals: 0, expRequestErrors: 0, expBadRequests: 0,
+			},
+			{
+				description:      "Count delete request total",
+				testCase:         func(pm *PrometheusMetrics) { pm.RecordDeleteTotal() },
+				expDuration:      10,
+				expRequestTotals: 1, expRequestErrors: 0, expBadRequests: 0,
+			},
+			{
+				description:      "Count delete request error",
+				testCase:         func(pm *PrometheusMetrics) { pm.RecordDeleteError() },
+				expDuration:      10,
+				expRequestTotals: 1, expRequestErrors: 1, expBadRequests: 0,
+			},
+			{
+				description:      "Count delete request bad request",
+				testCase:         func(pm *PrometheusMetrics) { pm.RecordDeleteBadRequest() },
+				expDuration:      10,
+				expRequestTotals: 1, expRequestErrors: 1, expBadRequests: 1,
+			},
+		},
+		m.DeletesBackend: {
+			{
+				description: "Log delete backend request duration",
+				testCase: func(pm *PrometheusMetrics) {
+					pm.RecordDeleteBackendDuration(TenSeconds)
+				},
+				expDuration:      10,
+				expRequestTotals: 0, expRequestErrors: 0, expBadRequests: 0,
+			},
+			{
+				description:      "Count delete backend request total",
+				testCase:         func(pm *PrometheusMetrics) { pm.RecordDeleteBackendTotal() },
+				expDuration:      10,
+				expRequestTotals: 1, expRequestErrors: 0, expBadRequests: 0,
+			},
+			{
+				description:      "Count delete backend request error",
+				testCase:         func(pm *PrometheusMetrics) { pm.RecordDeleteBackendError() },
+				expDuration:      10,
+				expRequestTotals: 1, expRequestErrors: 1, expBadRequests: 0,
+			},
+		},
 	}
 
 	for prometheusMetric, testCaseArray := range testGroups {
@@ -320,6 +370,117 @@ func TestPutBackendMetrics(t *testing.T) {
 	}
 }
 
+func TestCassandraWriteMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordCassandraWriteDuration(config.CassandraWriteLWT, TenSeconds)
+	m.RecordCassandraWriteDuration(config.CassandraWriteLWT, TenSeconds)
+	m.RecordCassandraWriteDuration(config.CassandraWritePlain, TenSeconds)
+
+	assertHistogram(t, "Lightweight transaction writes", m.CassandraWrites.Duration.With(prometheus.Labels{ModeKey: "lwt"}).(prometheus.Histogram), 2, 20)
+	assertHistogram(t, "Plain insert writes", m.CassandraWrites.Duration.With(prometheus.Labels{ModeKey: "plain"}).(prometheus.Histogram), 1, 10)
+}
+
+func TestMemcacheServerMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordMemcacheServerError("10.0.0.1:11211")
+	m.RecordMemcacheServerError("10.0.0.1:11211")
+	m.RecordMemcacheServerError("10.0.0.2:11211")
+
+	assertCounterVecValue(t, "First server errors", m.MemcacheServers.Errors, 2, prometheus.Labels{ServerKey: "10.0.0.1:11211"})
+	assertCounterVecValue(t, "Second server errors", m.MemcacheServers.Errors, 1, prometheus.Labels{ServerKey: "10.0.0.2:11211"})
+}
+
+func TestTieredMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordTieredHit(config.CacheTierL1)
+	m.RecordTieredHit(config.CacheTierL1)
+	m.RecordTieredHit(config.CacheTierL2)
+	m.RecordTieredMiss()
+
+	assertCounterVecValue(t, "L1 hits", m.Tiered.Hits, 2, prometheus.Labels{TierKey: "l1"})
+	assertCounterVecValue(t, "L2 hits", m.Tiered.Hits, 1, prometheus.Labels{TierKey: "l2"})
+	assertCounterValue(t, "Misses", m.Tiered.Misses, 1)
+}
+
+func TestMigrationMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordMigrationHit(config.MigrationPrimary)
+	m.RecordMigrationHit(config.MigrationPrimary)
+	m.RecordMigrationHit(config.MigrationSecondary)
+	m.RecordMigrationError(config.MigrationSecondary)
+
+	assertCounterVecValue(t, "Primary hits", m.Migration.Hits, 2, prometheus.Labels{SideKey: "primary"})
+	assertCounterVecValue(t, "Secondary hits", m.Migration.Hits, 1, prometheus.Labels{SideKey: "secondary"})
+	assertCounterVecValue(t, "Primary errors", m.Migration.Errors, 0, prometheus.Labels{SideKey: "primary"})
+	assertCounterVecValue(t, "Secondary errors", m.Migration.Errors, 1, prometheus.Labels{SideKey: "secondary"})
+}
+
+func TestShardMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordShardRequest("redis-a")
+	m.RecordShardRequest("redis-a")
+	m.RecordShardRequest("memcache-b")
+	m.RecordShardError("memcache-b")
+
+	assertCounterVecValue(t, "First shard requests", m.Shards.Requests, 2, prometheus.Labels{ShardKey: "redis-a"})
+	assertCounterVecValue(t, "Second shard requests", m.Shards.Requests, 1, prometheus.Labels{ShardKey: "memcache-b"})
+	assertCounterVecValue(t, "First shard errors", m.Shards.Errors, 0, prometheus.Labels{ShardKey: "redis-a"})
+	assertCounterVecValue(t, "Second shard errors", m.Shards.Errors, 1, prometheus.Labels{ShardKey: "memcache-b"})
+}
+
+func TestCircuitBreakerMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordCircuitBreakerTransition("put", config.CircuitOpen)
+	m.RecordCircuitBreakerTransition("put", config.CircuitHalfOpen)
+	m.RecordCircuitBreakerTransition("put", config.CircuitOpen)
+	m.RecordCircuitBreakerTransition("get", config.CircuitOpen)
+
+	assertCounterVecValue(t, "Put circuit opened", m.CircuitBreaker.Transitions, 2, prometheus.Labels{OperationKey: "put", StateKey: "open"})
+	assertCounterVecValue(t, "Put circuit half-opened", m.CircuitBreaker.Transitions, 1, prometheus.Labels{OperationKey: "put", StateKey: "half_open"})
+	assertCounterVecValue(t, "Put circuit closed", m.CircuitBreaker.Transitions, 0, prometheus.Labels{OperationKey: "put", StateKey: "closed"})
+	assertCounterVecValue(t, "Get circuit opened", m.CircuitBreaker.Transitions, 1, prometheus.Labels{OperationKey: "get", StateKey: "open"})
+}
+
+func TestRetryMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordBackendRetry("get")
+	m.RecordBackendRetry("get")
+	m.RecordBackendRetry("put")
+	m.RecordHedgedGet()
+
+	assertCounterVecValue(t, "Get retries", m.Retries.Retries, 2, prometheus.Labels{OperationKey: "get"})
+	assertCounterVecValue(t, "Put retries", m.Retries.Retries, 1, prometheus.Labels{OperationKey: "put"})
+	assertCounterVecValue(t, "Delete retries", m.Retries.Retries, 0, prometheus.Labels{OperationKey: "delete"})
+	assertCounterValue(t, "Hedged gets", m.Retries.HedgedGets, 1)
+}
+
+func TestCoalescedGetMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordCoalescedGet()
+	m.RecordCoalescedGet()
+
+	assertCounterValue(t, "Coalesced gets", m.CoalescedGets, 2)
+}
+
+func TestNegativeCacheMetrics(t *testing.T) {
+	m := createPrometheusMetricsForTesting()
+
+	m.RecordNegativeCacheHit(config.NegativeCacheEntry)
+	m.RecordNegativeCacheHit(config.NegativeCacheEntry)
+	m.RecordNegativeCacheHit(config.NegativeCacheBloomFilter)
+
+	assertCounterVecValue(t, "Cached misses", m.NegativeCache.Hits, 2, prometheus.Labels{SourceKey: "entry"})
+	assertCounterVecValue(t, "Keys missing from the bloom filter", m.NegativeCache.Hits, 1, prometheus.Labels{SourceKey: "bloom_filter"})
+}
+
 func TestConnectionMetrics(t *testing.T) {
 	testCases := []struct {
 		description                    string
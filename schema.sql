@@ -1,7 +1,8 @@
-CREATE KEYSPACE prebid WITH replication = {'class': 'SimpleStrategy', 'replication_factor': '1'};
+-- Equivalent to running "prebid-cache schema" with the default config.backend.cassandra.schema settings
+CREATE KEYSPACE IF NOT EXISTS prebid WITH replication = {'class': 'SimpleStrategy', 'replication_factor': '1'};
 
-CREATE TABLE prebid.cache (
+CREATE TABLE IF NOT EXISTS prebid.cache (
     key text,
     value text,
     PRIMARY KEY (key)
-);
+) WITH default_time_to_live = 0 AND compaction = {'class': 'SizeTieredCompactionStrategy'};
@@ -32,10 +32,11 @@ func Listen(cfg config.Configuration, publicHandler http.Handler, adminHandler h
 	// because a shared channel would only alert one consumer (whichever one happens to read it first).
 	//
 	// After a server has finished shutting down, it should send a signal in through the "done" channel.
+	shutdownTimeout := time.Duration(cfg.Timeouts.ShutdownSeconds) * time.Second
 	mainServer := newMainServer(cfg, publicHandler)
 	adminServer := newAdminServer(cfg, adminHandler)
-	go shutdownAfterSignals(mainServer, stopMain, done)
-	go shutdownAfterSignals(adminServer, stopAdmin, done)
+	go shutdownAfterSignals(mainServer, shutdownTimeout, stopMain, done)
+	go shutdownAfterSignals(adminServer, shutdownTimeout, stopAdmin, done)
 
 	// Attach the servers to the sockets
 	mainListener, err := newListener(mainServer.Addr, metrics)
@@ -58,7 +59,7 @@ func Listen(cfg config.Configuration, publicHandler http.Handler, adminHandler h
 		promRegistry := metrics.GetEngineRegistry(localprometheus.MetricsPrometheus).(*prometheus.Registry)
 
 		prometheusServer := newPrometheusServer(&cfg, promRegistry)
-		go shutdownAfterSignals(prometheusServer, stopPrometheus, done)
+		go shutdownAfterSignals(prometheusServer, shutdownTimeout, stopPrometheus, done)
 		prometheusListener, err := newListener(prometheusServer.Addr, nil)
 		if err != nil {
 			logger.Error("Error listening for TCP connections on %s: %v for prometheus server", adminServer.Addr, err)
@@ -84,8 +85,8 @@ func newMainServer(cfg config.Configuration, handler http.Handler) *http.Server
 	return &http.Server{
 		Addr:         ":" + strconv.Itoa(cfg.Port),
 		Handler:      handler,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		ReadTimeout:  time.Duration(cfg.Timeouts.ServerReadSeconds) * time.Second,
+		WriteTimeout: time.Duration(cfg.Timeouts.ServerWriteSeconds) * time.Second,
 	}
 }
 
@@ -127,10 +128,12 @@ func wait(inbound <-chan os.Signal, done <-chan struct{}, outbound ...chan<- os.
 	}
 }
 
-func shutdownAfterSignals(server *http.Server, stopper <-chan os.Signal, done chan<- struct{}) {
+// shutdownAfterSignals gives the requests server has in flight up to timeout to complete once a
+// signal comes through stopper
+func shutdownAfterSignals(server *http.Server, timeout time.Duration, stopper <-chan os.Signal, done chan<- struct{}) {
 	sig := <-stopper
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
 	var s struct{}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/prebid/prebid-cache/config"
 )
@@ -23,11 +24,18 @@ func TestNewMainServer(t *testing.T) {
 	cfg := config.Configuration{
 		Port:      8000,
 		AdminPort: 6060,
+		Timeouts: config.Timeouts{
+			ServerReadSeconds:  5,
+			ServerWriteSeconds: 20,
+		},
 	}
 	server := newMainServer(cfg, http.HandlerFunc(handler))
 	if server.Addr != ":8000" {
 		t.Errorf("Admin server address should be %s. Got %s", ":8000", server.Addr)
 	}
+	if server.ReadTimeout != 5*time.Second || server.WriteTimeout != 20*time.Second {
+		t.Errorf("Main server timeouts should be %v and %v. Got %v and %v", 5*time.Second, 20*time.Second, server.ReadTimeout, server.WriteTimeout)
+	}
 }
 
 func TestServerShutdown(t *testing.T) {
@@ -36,7 +44,7 @@ func TestServerShutdown(t *testing.T) {
 
 	stopper := make(chan os.Signal)
 	done := make(chan struct{})
-	go shutdownAfterSignals(server, stopper, done)
+	go shutdownAfterSignals(server, 10*time.Second, stopper, done)
 	go server.Serve(ln)
 
 	stopper <- os.Interrupt
@@ -8,10 +8,49 @@ const (
 
 // The following numeric constants serve as configuration defaults
 const (
-	CASSANDRA_DEFAULT_TTL_SECONDS    = 2400
-	REDIS_DEFAULT_EXPIRATION_MINUTES = 60
-	RATE_LIMITER_NUM_REQUESTS        = 100
-	REQUEST_MAX_SIZE_BYTES           = 10 * 1024
-	REQUEST_MAX_NUM_VALUES           = 10
-	REQUEST_MAX_TTL_SECONDS          = 3600
+	CASSANDRA_DEFAULT_TTL_SECONDS            = 2400
+	CASSANDRA_DEFAULT_TIMEOUT_MS             = 600
+	REDIS_DEFAULT_EXPIRATION_MINUTES         = 60
+	MEMCACHE_DEFAULT_TIMEOUT_MS              = 100
+	MEMCACHE_DEFAULT_MAX_IDLE_CONNS          = 2
+	MEMORY_SWEEP_INTERVAL_SECONDS            = 60
+	MEMORY_DEFAULT_SHARDS                    = 32
+	FILE_DEFAULT_SEGMENT_SIZE_BYTES          = 64 * 1024 * 1024
+	FILE_DEFAULT_COMPACTION_INTERVAL_SECONDS = 300
+	SQL_DEFAULT_MAX_OPEN_CONNS               = 10
+	SQL_DEFAULT_MAX_IDLE_CONNS               = 2
+	SQL_DEFAULT_SWEEP_INTERVAL_SECONDS       = 60
+	TIERED_L1_DEFAULT_MAX_ITEMS              = 10000
+	TIERED_L1_DEFAULT_TTL_SECONDS            = 60
+	SHARDED_DEFAULT_VIRTUAL_NODES            = 160
+	RATE_LIMITER_NUM_REQUESTS                = 100
+	CIRCUIT_BREAKER_FAILURE_RATE_PERCENT     = 50
+	CIRCUIT_BREAKER_MIN_REQUESTS             = 20
+	CIRCUIT_BREAKER_WINDOW_SECONDS           = 10
+	CIRCUIT_BREAKER_OPEN_SECONDS             = 5
+	CIRCUIT_BREAKER_HALF_OPEN_REQUESTS       = 5
+	RETRY_DEFAULT_MAX_READ_RETRIES           = 2
+	RETRY_DEFAULT_MIN_BACKOFF_MS             = 10
+	RETRY_DEFAULT_MAX_BACKOFF_MS             = 100
+	RETRY_DEFAULT_BUDGET_PERCENT             = 20
+	NEGATIVE_CACHE_DEFAULT_TTL_SECONDS       = 5
+	NEGATIVE_CACHE_DEFAULT_MAX_ITEMS         = 100000
+	BLOOM_FILTER_DEFAULT_EXPECTED_KEYS       = 1000000
+	TIMEOUT_DEFAULT_BACKEND_MS               = 500
+	TIMEOUT_DEFAULT_SERVER_SECONDS           = 15
+	TIMEOUT_DEFAULT_SHUTDOWN_SECONDS         = 10
+	HEALTH_CHECK_DEFAULT_INTERVAL_SECONDS    = 5
+	HEALTH_CHECK_DEFAULT_TIMEOUT_MS          = 500
+	REQUEST_MAX_SIZE_BYTES                   = 10 * 1024
+	REQUEST_MAX_NUM_VALUES                   = 10
+	REQUEST_MAX_TTL_SECONDS                  = 3600
 )
+
+// Aerospike set and bin Prebid Cache stores its values under unless configured otherwise
+const (
+	AEROSPIKE_DEFAULT_SET_NAME = "uuid"
+	AEROSPIKE_DEFAULT_BIN_NAME = "value"
+)
+
+// Table the SQL backend stores its values in unless configured otherwise
+const SQL_DEFAULT_TABLE = "prebid_cache"
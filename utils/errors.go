@@ -21,6 +21,9 @@ const (
 	PUT_INTERNAL_SERVER              // PUT http.StatusInternalServerError 500
 	MARSHAL_RESPONSE                 // PUT http.StatusInternalServerError 500
 	PUT_DEADLINE_EXCEEDED            // PUT HttpDependencyTimeout 597
+	GET_MAX_NUM_VALUES               // GET http.StatusBadRequest 400
+	GET_DEADLINE_EXCEEDED            // GET HttpDependencyTimeout 597
+	BACKEND_UNAVAILABLE              // GET, PUT and DELETE http.StatusServiceUnavailable 503
 )
 
 // HTTPDependencyTimeout is the status code for errors due to a downstream dependency timeout.
@@ -43,6 +46,9 @@ var errToStatusCodes map[int]int = map[int]int{
 	KEY_NOT_FOUND:             http.StatusNotFound,
 	KEY_LENGTH:                http.StatusNotFound,
 	PUT_DEADLINE_EXCEEDED:     HTTPDependencyTimeout,
+	GET_MAX_NUM_VALUES:        http.StatusBadRequest,
+	GET_DEADLINE_EXCEEDED:     HTTPDependencyTimeout,
+	BACKEND_UNAVAILABLE:       http.StatusServiceUnavailable,
 }
 
 // Map Prebid Cache's error codes to their corresponding constant error message if they have one.
@@ -57,6 +63,8 @@ var errToMsgs map[int]string = map[int]string{
 	KEY_NOT_FOUND:            "Key not found",
 	KEY_LENGTH:               "invalid uuid length",
 	PUT_DEADLINE_EXCEEDED:    "timeout writing value to the backend.",
+	GET_DEADLINE_EXCEEDED:    "timeout reading value from the backend.",
+	BACKEND_UNAVAILABLE:      "backend unavailable: too many recent errors, circuit breaker is open.",
 }
 
 // PBCError implements the error interface